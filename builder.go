@@ -0,0 +1,167 @@
+package hocon
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Builder constructs a Config programmatically, e.g. to define the defaults in code:
+//
+//	config, err := NewBuilder().Set("server.host", "localhost").Set("server.port", 8080).Build()
+//
+// the intermediate objects of the dotted paths are created as needed and the first error,
+// like assigning a value under a scalar value, is reported by the Build method
+type Builder struct {
+	root Object
+	err  error
+}
+
+// NewBuilder function creates a Builder with an empty root object
+func NewBuilder() *Builder {
+	return &Builder{root: Object{}}
+}
+
+// Set method converts the given Go value to the corresponding Value and sets it at the given path,
+// supported values are Values, strings, booleans, integers, floats, time.Duration, nil, slices and string keyed maps.
+// The slices, the arrays and the objects are copied, so modifying them after they are set does not change the config.
+// Setting a path under a scalar value, replacing an object with a scalar value or an unsigned integer greater than
+// the max int (an error wrapping ErrOutOfRange) is reported as an error
+func (b *Builder) Set(path string, value interface{}) *Builder {
+	if b.err != nil {
+		return b
+	}
+
+	converted, err := toValue(value)
+	if err != nil {
+		b.err = fmt.Errorf("could not set the value at path: %s, %w", path, err)
+		return b
+	}
+
+	b.err = b.set(path, converted)
+
+	return b
+}
+
+// SetList method sets the given slice as an array at the given path, returns an error from the Build method
+// if the given value is not a slice
+func (b *Builder) SetList(path string, values interface{}) *Builder {
+	if b.err != nil {
+		return b
+	}
+
+	if kind := reflect.ValueOf(values).Kind(); kind != reflect.Slice && kind != reflect.Array {
+		b.err = fmt.Errorf("could not set the value at path: %s, %T is not a list", path, values)
+		return b
+	}
+
+	return b.Set(path, values)
+}
+
+// Build method returns the built Config, or the first error that occurred while setting the values
+func (b *Builder) Build() (*Config, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	return b.root.copy().ToConfig(), nil
+}
+
+func (b *Builder) set(path string, value Value) error {
+	keys := strings.Split(path, dotToken)
+	current := b.root
+
+	for i, key := range keys[:len(keys)-1] {
+		existing, ok := current[key]
+		if !ok {
+			object := Object{}
+			current[key] = object
+			current = object
+
+			continue
+		}
+
+		object, ok := existing.(Object)
+		if !ok {
+			return fmt.Errorf("could not set the value at path: %s, value at path: %s is not an object", path, strings.Join(keys[:i+1], dotToken))
+		}
+
+		current = object
+	}
+
+	lastKey := keys[len(keys)-1]
+	if existing, ok := current[lastKey]; ok && existing.Type() == ObjectType && value.Type() != ObjectType {
+		return fmt.Errorf("could not set the value at path: %s, it is already an object", path)
+	}
+
+	current[lastKey] = value
+
+	return nil
+}
+
+func toValue(value interface{}) (Value, error) {
+	switch v := value.(type) {
+	case nil:
+		return null, nil
+	case Value:
+		return copyValue(v), nil // the given arrays and objects can be modified by the caller after they are added
+	case string:
+		return String(v), nil
+	case bool:
+		return Boolean(v), nil
+	case time.Duration:
+		return Duration(v), nil
+	case float32:
+		return Float32(v), nil
+	case float64:
+		return Float64(v), nil
+	}
+
+	reflectValue := reflect.ValueOf(value)
+
+	switch reflectValue.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return Int(reflectValue.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if reflectValue.Uint() > math.MaxInt {
+			return nil, fmt.Errorf("%w, %d is greater than the max int: %d", ErrOutOfRange, reflectValue.Uint(), math.MaxInt)
+		}
+
+		return Int(reflectValue.Uint()), nil
+	case reflect.Slice, reflect.Array:
+		array := Array{}
+
+		for i := 0; i < reflectValue.Len(); i++ {
+			element, err := toValue(reflectValue.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+
+			array = append(array, element)
+		}
+
+		return array, nil
+	case reflect.Map:
+		if reflectValue.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("unsupported map key type: %s", reflectValue.Type().Key())
+		}
+
+		object := Object{}
+		iter := reflectValue.MapRange()
+
+		for iter.Next() {
+			element, err := toValue(iter.Value().Interface())
+			if err != nil {
+				return nil, err
+			}
+
+			object[iter.Key().String()] = element
+		}
+
+		return object, nil
+	}
+
+	return nil, fmt.Errorf("unsupported type: %T", value)
+}
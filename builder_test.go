@@ -0,0 +1,93 @@
+package hocon
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestBuilder(t *testing.T) {
+	t.Run("build the config creating the intermediate objects of the dotted paths", func(t *testing.T) {
+		got, err := NewBuilder().
+			Set("server.host", "localhost").
+			Set("server.port", 8080).
+			Set("server.timeout", 5*time.Second).
+			Set("debug", true).
+			Set("ratio", 0.5).
+			Set("missing", nil).
+			SetList("tags", []string{"a", "b"}).
+			Build()
+		assertNoError(t, err)
+
+		expected := Object{
+			"server":  Object{"host": String("localhost"), "port": Int(8080), "timeout": Duration(5 * time.Second)},
+			"debug":   Boolean(true),
+			"ratio":   Float64(0.5),
+			"missing": null,
+			"tags":    Array{String("a"), String("b")},
+		}
+		assertDeepEqual(t, got.root, expected)
+	})
+
+	t.Run("convert the maps to objects", func(t *testing.T) {
+		got, err := NewBuilder().Set("a", map[string]int{"b": 1}).Build()
+		assertNoError(t, err)
+		assertDeepEqual(t, got.root, Object{"a": Object{"b": Int(1)}})
+	})
+
+	t.Run("overwrite the existing scalar value", func(t *testing.T) {
+		got, err := NewBuilder().Set("a", 1).Set("a", "b").Build()
+		assertNoError(t, err)
+		assertDeepEqual(t, got.root, Object{"a": String("b")})
+	})
+
+	t.Run("return an error if a value is set under a scalar value", func(t *testing.T) {
+		got, err := NewBuilder().Set("a", 1).Set("a.b", 2).Build()
+		assertError(t, err, errors.New("could not set the value at path: a.b, value at path: a is not an object"))
+		assertNil(t, got)
+	})
+
+	t.Run("return an error if an object is replaced with a scalar value", func(t *testing.T) {
+		got, err := NewBuilder().Set("a.b", 1).Set("a", 2).Build()
+		assertError(t, err, errors.New("could not set the value at path: a, it is already an object"))
+		assertNil(t, got)
+	})
+
+	t.Run("return an error if the value type is not supported", func(t *testing.T) {
+		got, err := NewBuilder().Set("a", struct{}{}).Build()
+		assertError(t, err, errors.New("could not set the value at path: a, unsupported type: struct {}"))
+		assertNil(t, got)
+	})
+
+	t.Run("return an error wrapping ErrOutOfRange if an unsigned integer is greater than the max int", func(t *testing.T) {
+		got, err := NewBuilder().Set("a", uint64(math.MaxUint64)).Build()
+		assertError(t, err, fmt.Errorf("could not set the value at path: a, %w, 18446744073709551615 is greater than the max int: %d",
+			ErrOutOfRange, math.MaxInt))
+		assertEquals(t, errors.Is(err, ErrOutOfRange), true)
+		assertNil(t, got)
+
+		config, err := NewBuilder().Set("a", uint64(math.MaxInt)).Build()
+		assertNoError(t, err)
+		assertEquals(t, config.GetInt("a"), math.MaxInt)
+	})
+
+	t.Run("copy the arrays and the objects when they are set", func(t *testing.T) {
+		array := Array{Int(1), Object{"b": Int(2)}}
+		builder := NewBuilder().Set("a", array).SetList("c", []Value{Int(3)})
+
+		array[0] = Int(10)
+		array[1].(Object)["b"] = Int(20)
+
+		got, err := builder.Build()
+		assertNoError(t, err)
+		assertDeepEqual(t, got.root, Object{"a": Array{Int(1), Object{"b": Int(2)}}, "c": Array{Int(3)}})
+	})
+
+	t.Run("return an error if the value of SetList is not a list", func(t *testing.T) {
+		got, err := NewBuilder().SetList("a", "b").Build()
+		assertError(t, err, errors.New("could not set the value at path: a, string is not a list"))
+		assertNil(t, got)
+	})
+}
@@ -1,10 +1,23 @@
 package hocon
 
 import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"math"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode"
 )
 
 // Type of an hocon Value
@@ -26,14 +39,214 @@ const (
 // Config stores the root of the configuration tree
 // and provides an API to retrieve configuration values with the path expressions
 type Config struct {
-	root Value
+	root     Value
+	report   *ResolutionReport
+	quoted   map[string]bool   // paths of the string values that are written as quoted strings
+	raw      map[string]string // source text of the values as written, by path
+	warnings []string          // problems found while parsing that did not fail it
+	lazy     *lazyResolution   // set if the substitutions are resolved on access
+	header   []string          // comment lines before the first key or element of the parsed document
+	includes []IncludeToken    // includes recorded instead of being loaded with the WithDryRunIncludes option
+	source   *sourceSpans      // parsed source with the offsets of the values, kept only with the WithRawText option
+	booleans booleanSpellings  // boolean spellings of the WithBooleanSpellings option that the string values are read with
 }
 
-// String method returns the string representation of the Config object
-func (c *Config) String() string { return c.root.String() }
+// lazyResolution resolves the substitutions of a Config on access, the lock guards the tree which is modified
+// as the resolved values are cached in it, so the values are looked up under the lock as well. A resolved subtree
+// is not modified any more and the returned values can be read without the lock
+type lazyResolution struct {
+	lock     sync.Mutex
+	resolver *resolver
+	done     bool // whether the whole tree is resolved
+}
+
+// lookup resolves the substitutions on the given path and finds the value at it
+func (l *lazyResolution) lookup(c *Config, path string) Value {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.done {
+		return find(c.root, path)
+	}
+
+	if array, ok := c.root.(Array); ok {
+		var found bool
+		if path, found = l.unshiftedPath(array, path); !found {
+			return nil
+		}
+	}
+
+	if err := l.resolver.resolvePath(path); err != nil {
+		panic(err)
+	}
+
+	return find(c.root, path)
+}
+
+// unshiftedPath resolves the elements of the array root in order up to the one at the index of the given path
+// and returns the path with the index of the element in the array as written, the unresolved optional substitutions
+// are omitted from the array root once it is resolved, so the indexes of the elements after them shift
+func (l *lazyResolution) unshiftedPath(array Array, path string) (string, bool) {
+	keys := splitPath(path)
+
+	index, ok := arrayIndex(keys[0], len(array))
+	if !ok {
+		return path, true
+	}
+
+	for i := range array {
+		if err := l.resolver.resolvePath(strconv.Itoa(i)); err != nil {
+			panic(err)
+		}
+
+		if array[i] == nil {
+			continue
+		}
+
+		if index == 0 {
+			keys[0] = strconv.Itoa(i)
+			return joinPath(keys), true
+		}
+
+		index--
+	}
+
+	return "", false
+}
+
+func (l *lazyResolution) root(c *Config) Value {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.mustResolve(c)
+
+	return c.root
+}
+
+func (l *lazyResolution) mustResolve(c *Config) {
+	if err := l.resolveRemaining(c); err != nil {
+		panic(err)
+	}
+}
+
+// resolveRemaining resolves the substitutions that are not resolved yet, the lock must be held
+func (l *lazyResolution) resolveRemaining(c *Config) error {
+	if l.done {
+		return nil
+	}
+
+	if err := l.resolver.resolve(); err != nil {
+		return err
+	}
+
+	if array, ok := c.root.(Array); ok {
+		c.root = withoutAbsentElements(array)
+	}
+
+	l.done = true
+
+	return nil
+}
+
+// ResolutionReport describes how the substitutions of a parsed configuration were resolved,
+// each list contains the substitution paths (one entry per occurrence) in sorted order
+type ResolutionReport struct {
+	Resolved           []string // substitutions resolved to a value in the configuration
+	FromEnv            []string // substitutions that fell back to an environment variable
+	FromResolver       []string // substitutions resolved with the resolver of the WithSubstitutionResolver option
+	UnresolvedOptional []string // optional substitutions that could not be resolved
+	// SubstitutionSources are the top-level keys whose values are substituted into the other top-level keys (one entry
+	// per key), e.g. the variables defined only to feed the substitutions, which are dead config if nothing else reads them
+	SubstitutionSources []string
+}
+
+func (r *ResolutionReport) isEmpty() bool {
+	return len(r.Resolved) == 0 && len(r.FromEnv) == 0 && len(r.FromResolver) == 0 && len(r.UnresolvedOptional) == 0 &&
+		len(r.SubstitutionSources) == 0
+}
+
+func (r *ResolutionReport) sorted() *ResolutionReport {
+	sort.Strings(r.Resolved)
+	sort.Strings(r.FromEnv)
+	sort.Strings(r.FromResolver)
+	sort.Strings(r.UnresolvedOptional)
+	sort.Strings(r.SubstitutionSources)
+
+	return r
+}
+
+// Warnings method returns the problems found while parsing that did not fail it, e.g. the errors reported by the scanner,
+// returns nil if there are no warnings or the configuration is not created by parsing
+func (c *Config) Warnings() []string {
+	return c.warnings
+}
+
+// Includes method returns the include directives of a configuration parsed with the WithDryRunIncludes option in the order
+// they are written, with the environment variables of their paths expanded. Returns nil for the other configurations
+func (c *Config) Includes() []IncludeToken {
+	return c.includes
+}
+
+// ResolutionReport method returns how the substitutions were resolved while parsing the configuration,
+// returns an empty report if the configuration is not created by parsing or does not contain substitutions
+func (c *Config) ResolutionReport() ResolutionReport {
+	if c.report == nil {
+		return ResolutionReport{}
+	}
+
+	if c.lazy != nil { // the report grows as the substitutions are resolved, the returned lists are copies
+		c.lazy.lock.Lock()
+		defer c.lazy.lock.Unlock()
+
+		report := c.report.sorted()
+
+		return ResolutionReport{
+			Resolved:            append([]string(nil), report.Resolved...),
+			FromEnv:             append([]string(nil), report.FromEnv...),
+			FromResolver:        append([]string(nil), report.FromResolver...),
+			UnresolvedOptional:  append([]string(nil), report.UnresolvedOptional...),
+			SubstitutionSources: append([]string(nil), report.SubstitutionSources...),
+		}
+	}
+
+	return *c.report
+}
+
+// Header method returns the comment lines (with their # or // markers) that appear before the first key or element
+// of the parsed document, e.g. a "generated by" banner, returns nil if there is no header
+func (c *Config) Header() []string {
+	return c.header
+}
+
+// String method returns the string representation of the Config object, preceded by the header comment lines if any
+func (c *Config) String() string {
+	var builder strings.Builder
+
+	_, _ = c.WriteTo(&builder)
+
+	return builder.String()
+}
+
+// Resolve method resolves all the remaining substitutions of a configuration parsed with the WithLazyResolution option
+// and returns the first error, e.g. to validate the configuration at the startup instead of panicking on access later.
+// It is a no-op returning nil for the configurations that are resolved while parsing
+func (c *Config) Resolve() error {
+	if c.lazy == nil {
+		return nil
+	}
+
+	c.lazy.lock.Lock()
+	defer c.lazy.lock.Unlock()
+
+	return c.lazy.resolveRemaining(c)
+}
 
 // GetRoot method returns the root value of the configuration
 func (c *Config) GetRoot() Value {
+	if c.lazy != nil {
+		return c.lazy.root(c)
+	}
+
 	return c.root
 }
 
@@ -54,9 +267,132 @@ func (c *Config) GetConfig(path string) *Config {
 		return nil
 	}
 
-	return value.ToConfig()
+	config := value.ToConfig()
+	config.booleans = c.booleans
+
+	return config
+}
+
+// MustGetConfig method finds the value at the given path and returns it as a Config
+// panics if the value is not found or if it is not an object
+func (c *Config) MustGetConfig(path string) *Config {
+	value := c.Get(path)
+	if value == nil {
+		panic("could not find the required config at path: " + path)
+	}
+
+	object, ok := value.(Object)
+	if !ok {
+		panic("value: " + value.String() + " at path: " + path + " is not an object!")
+	}
+
+	return object.ToConfig()
+}
+
+// GetConfigStrict method finds the object at the given path and returns it as a Config like the GetConfig method,
+// and returns an error wrapping ErrUnknownKeys that lists all the keys of the object outside the allowed ones,
+// e.g. to catch the typos like "tiemout" in a small, well-defined section. Returns an error wrapping ErrValueNotFound
+// if the value is not found and an error if it is not an object
+func (c *Config) GetConfigStrict(path string, allowedKeys []string) (*Config, error) {
+	value := c.Get(path)
+	if value == nil {
+		return nil, valueNotFoundError(path)
+	}
+
+	object, ok := value.(Object)
+	if !ok {
+		return nil, fmt.Errorf("value: %s at path: %s is not an object", value, path)
+	}
+
+	allowed := make(map[string]bool, len(allowedKeys))
+	for _, key := range allowedKeys {
+		allowed[key] = true
+	}
+
+	var unknown []string
+
+	for _, entry := range object.Entries() {
+		if !allowed[entry.Key] {
+			unknown = append(unknown, entry.Key)
+		}
+	}
+
+	if len(unknown) > 0 {
+		return nil, unknownKeysError(path, unknown, allowedKeys)
+	}
+
+	return object.ToConfig(), nil
+}
+
+// ForEach method iterates the object at the given path in sorted key order and calls the given function
+// with each key and a Config rooted at the child object, the children that are not objects are skipped.
+// Does nothing if the value is not found or if it is not an object
+func (c *Config) ForEach(path string, fn func(key string, sub *Config)) {
+	object, ok := c.Get(path).(Object)
+	if !ok {
+		return
+	}
+
+	for _, key := range object.sortedKeys() {
+		if child, ok := object[key].(Object); ok {
+			fn(key, child.ToConfig())
+		}
+	}
+}
+
+// Prefixed method returns a new Config with the whole tree nested under the given (possibly dotted) prefix,
+// e.g. after config.Prefixed("plugins.auth") the enabled key of the config is read as plugins.auth.enabled.
+// It is the inverse of the GetConfig method and it does not modify the config
+func (c *Config) Prefixed(prefix string) *Config {
+	value := c.GetRoot()
+	if object, ok := value.(Object); ok {
+		value = object.copy()
+	}
+
+	if prefix == "" {
+		return &Config{root: value, booleans: c.booleans}
+	}
+
+	keys := splitPath(prefix)
+	for i := len(keys) - 1; i >= 0; i-- {
+		value = Object{keys[i]: value}
+	}
+
+	return &Config{root: value, booleans: c.booleans}
+}
+
+// SelectProfile method returns the profile object at the path base.name (e.g. profiles.prod) as a new root config,
+// merged over the shared profile at base.default if there is one. Returns an error listing the available profile
+// names if the profile is not found
+func (c *Config) SelectProfile(base string, name string) (*Config, error) {
+	profiles, ok := c.Get(base).(Object)
+	if !ok {
+		return nil, fmt.Errorf("could not find the profiles at path: %s", base)
+	}
+
+	profile, ok := profiles[name].(Object)
+	if !ok {
+		var available []string
+
+		for _, key := range profiles.sortedKeys() {
+			if _, isObject := profiles[key].(Object); isObject && key != defaultProfile {
+				available = append(available, key)
+			}
+		}
+
+		return nil, fmt.Errorf("could not find the profile: %q at path: %s, available profiles: %q", name, base, available)
+	}
+
+	selected := profile.copy().ToConfig()
+	if shared, ok := profiles[defaultProfile].(Object); ok && name != defaultProfile {
+		selected = selected.WithFallback(shared.ToConfig())
+	}
+
+	return selected, nil
 }
 
+const defaultProfile = "default"
+
 // GetStringMap method finds the value at the given path and returns it as a map[string]Value
 // returns nil if the value is not found
 func (c *Config) GetStringMap(path string) map[string]Value {
@@ -81,6 +417,34 @@ func (c *Config) GetStringMapString(path string) map[string]string {
 	return m
 }
 
+// GetIntMap method finds the object at the given path and returns its values as a map[string]int, e.g. for the weights
+// of the routes. The values are converted like the GetInt method does, the objects, arrays and nulls are skipped.
+// Returns nil if the value is not found, panics if a value cannot be converted to int
+func (c *Config) GetIntMap(path string) map[string]int {
+	value := c.Get(path)
+	if value == nil {
+		return nil
+	}
+
+	object := value.(Object)
+
+	var m = make(map[string]int, len(object))
+	for k, v := range object {
+		if valueType := v.Type(); valueType == ObjectType || valueType == ArrayType || valueType == NullType {
+			continue
+		}
+
+		intValue, ok := AsInt(v)
+		if !ok {
+			panic("cannot parse value: " + v.String() + " to int!")
+		}
+
+		m[k] = intValue
+	}
+
+	return m
+}
+
 // GetArray method finds the value at the given path and returns it as an Array, returns nil if the value is not found
 func (c *Config) GetArray(path string) Array {
 	value := c.Get(path)
@@ -91,6 +455,20 @@ func (c *Config) GetArray(path string) Array {
 	return value.(Array)
 }
 
+// GetValueList method finds the array at the given path and returns a copy of its elements without casting them to any type,
+// e.g. for the arrays mixing scalars, objects and arrays whose elements can be checked with their Type method or the
+// As* functions. Returns nil if the value is not found, panics if it is not an array
+func (c *Config) GetValueList(path string) []Value {
+	value := c.Get(path)
+	if value == nil {
+		return nil
+	}
+
+	array := value.(Array)
+
+	return append(make([]Value, 0, len(array)), array...)
+}
+
 // GetIntSlice method finds the value at the given path and returns it as []int, returns nil if the value is not found
 func (c *Config) GetIntSlice(path string) []int {
 	value := c.Get(path)
@@ -98,178 +476,1366 @@ func (c *Config) GetIntSlice(path string) []int {
 		return nil
 	}
 
-	arr := value.(Array)
-	slice := make([]int, 0, len(arr))
+	arr := value.(Array)
+	slice := make([]int, 0, len(arr))
+
+	for _, v := range arr {
+		slice = append(slice, int(v.(Int)))
+	}
+
+	return slice
+}
+
+// GetStringSlice method finds the value at the given path and returns it as []string
+// returns nil if the value is not found
+func (c *Config) GetStringSlice(path string) []string {
+	value := c.Get(path)
+	if value == nil {
+		return nil
+	}
+
+	arr := value.(Array)
+	slice := make([]string, 0, len(arr))
+
+	for _, v := range arr {
+		slice = append(slice, v.String())
+	}
+
+	return slice
+}
+
+// GetArrayWithDefault method finds the value at the given path and returns it as an Array like the GetArray method,
+// returns the given default value if the value is not found. An explicitly empty array is returned as an empty Array
+func (c *Config) GetArrayWithDefault(path string, def Array) Array {
+	if c.Get(path) == nil {
+		return def
+	}
+
+	if array := c.GetArray(path); array != nil {
+		return array
+	}
+
+	return Array{}
+}
+
+// GetValueListWithDefault method finds the array at the given path and returns its elements like the GetValueList method,
+// returns the given default value if the value is not found
+func (c *Config) GetValueListWithDefault(path string, def []Value) []Value {
+	if c.Get(path) == nil {
+		return def
+	}
+
+	return c.GetValueList(path)
+}
+
+// GetIntSliceWithDefault method finds the value at the given path and returns it as []int like the GetIntSlice method,
+// returns the given default value if the value is not found
+func (c *Config) GetIntSliceWithDefault(path string, def []int) []int {
+	if c.Get(path) == nil {
+		return def
+	}
+
+	return c.GetIntSlice(path)
+}
+
+// GetStringSliceWithDefault method finds the value at the given path and returns it as []string like the GetStringSlice
+// method, returns the given default value if the value is not found
+func (c *Config) GetStringSliceWithDefault(path string, def []string) []string {
+	if c.Get(path) == nil {
+		return def
+	}
+
+	return c.GetStringSlice(path)
+}
+
+// GetStringSet method finds the array at the given path and returns its elements as a set of strings, e.g. for the tags
+// or the allowed origins that are checked for membership. The duplicates are merged, use the GetStringSetE method to
+// report them. A single value is returned as a one-element set, returns an empty set if the value is not found.
+// Panics if the value or an element is an object, an array or null
+func (c *Config) GetStringSet(path string) map[string]struct{} {
+	elements := c.stringSetElements(path)
+	set := make(map[string]struct{}, len(elements))
+
+	for _, element := range elements {
+		if !isStringScalar(element) {
+			panic("cannot parse value: " + element.String() + " to string!")
+		}
+
+		set[unquotedString(element)] = struct{}{}
+	}
+
+	return set
+}
+
+// GetStringSetE method finds the array at the given path and returns its elements as a set of strings like the GetStringSet
+// method, returns an error naming the index of the first element that duplicates an earlier one or that is an object,
+// an array or null
+func (c *Config) GetStringSetE(path string) (map[string]struct{}, error) {
+	value := c.Get(path)
+	if value != nil && !isStringScalar(value) && value.Type() != ArrayType {
+		return nil, fmt.Errorf("could not parse the value: %s at path: %s to string", value, path)
+	}
+
+	elements := c.stringSetElements(path)
+	set := make(map[string]struct{}, len(elements))
+
+	for i, element := range elements {
+		if !isStringScalar(element) {
+			return nil, fmt.Errorf("could not parse the value: %s at index: %d of the array at path: %s to string", element, i, path)
+		}
+
+		s := unquotedString(element)
+		if _, ok := set[s]; ok {
+			return nil, fmt.Errorf("duplicate value: %q at index: %d of the array at path: %s", s, i, path)
+		}
+
+		set[s] = struct{}{}
+	}
+
+	return set, nil
+}
+
+// stringSetElements returns the elements of the array at the given path, a single value (including an object or null)
+// as the only element and no elements if the value is not found
+func (c *Config) stringSetElements(path string) []Value {
+	switch value := c.Get(path).(type) {
+	case nil:
+		return nil
+	case Array:
+		return value
+	default:
+		return []Value{value}
+	}
+}
+
+// isStringScalar reports whether the value can be read as a string element of a set, i.e. it is not an object,
+// an array or null
+func isStringScalar(value Value) bool {
+	switch value.Type() {
+	case ObjectType, ArrayType, NullType:
+		return false
+	default:
+		return true
+	}
+}
+
+// GetString method finds the value at the given path and returns it as a String
+// returns empty string if the value is not found
+func (c *Config) GetString(path string) string {
+	value := c.Get(path)
+	if value == nil {
+		return ""
+	}
+
+	return value.String()
+}
+
+// GetStringExpanded method finds the value at the given path and returns it as a String with the shell-style
+// ${VAR} and $VAR variables expanded with the environment variables, undefined variables expand to empty string.
+// Unlike the substitutions that are resolved while parsing, the expansion is done on the literal string when it is read
+func (c *Config) GetStringExpanded(path string) string {
+	return os.ExpandEnv(c.GetString(path))
+}
+
+// GetInt method finds the value at the given path and returns it as an Int, returns zero if the value is not found
+func (c *Config) GetInt(path string) int {
+	value := c.Get(path)
+	if value == nil {
+		return 0
+	}
+
+	switch val := value.(type) {
+	case Int:
+		return int(val)
+	case String:
+		intValue, err := strconv.Atoi(string(val))
+		if err != nil {
+			panic(err)
+		}
+
+		return intValue
+	default:
+		panic("cannot parse value: " + val.String() + " to int!")
+	}
+}
+
+// GetFloat32 method finds the value at the given path and returns it as a Float32
+// returns float32(0.0) if the value is not found
+func (c *Config) GetFloat32(path string) float32 {
+	value := c.Get(path)
+	if value == nil {
+		return float32(0.0)
+	}
+
+	switch val := value.(type) {
+	case Float32:
+		return float32(val)
+	case Float64:
+		if math.Abs(float64(val)) > math.MaxFloat32 {
+			panic("cannot parse value: " + val.String() + " to float32, it is out of range!")
+		}
+
+		return float32(val)
+	case String:
+		floatValue, err := strconv.ParseFloat(string(val), 32)
+		if err != nil {
+			panic(err)
+		}
+
+		return float32(floatValue)
+	default:
+		panic("cannot parse value: " + val.String() + " to float32!")
+	}
+}
+
+// GetFloat64 method finds the value at the given path and returns it as a Float64
+// returns 0.0 if the value is not found
+func (c *Config) GetFloat64(path string) float64 {
+	value := c.Get(path)
+	if value == nil {
+		return 0.0
+	}
+
+	switch val := value.(type) {
+	case Float64:
+		return float64(val)
+	case Float32:
+		return float64(val)
+	case String:
+		floatValue, err := strconv.ParseFloat(string(val), 64)
+		if err != nil {
+			panic(err)
+		}
+
+		return floatValue
+	default:
+		panic("cannot parse value: " + val.String() + "to float64!")
+	}
+}
+
+// GetBoolean method finds the value at the given path and returns it as a Boolean
+// returns false if the value is not found
+func (c *Config) GetBoolean(path string) bool {
+	value := c.Get(path)
+	if value == nil {
+		return false
+	}
+
+	switch val := value.(type) {
+	case Boolean:
+		return bool(val)
+	case String:
+		boolValue, ok := c.booleans.lookup(string(val))
+		if !ok {
+			panic("cannot parse value: " + val + " to boolean!")
+		}
+
+		return boolValue
+	default:
+		panic("cannot parse value: " + val.String() + " to boolean!")
+	}
+}
+
+// GetBooleanList method finds the array at the given path and returns its elements as []bool like the GetBooleanListE
+// method, returns nil if the value is not found and panics if an element is not a boolean
+func (c *Config) GetBooleanList(path string) []bool {
+	booleans, err := c.GetBooleanListE(path)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	return booleans
+}
+
+// GetBooleanListE method finds the array at the given path and returns its elements as []bool, accepting all the spellings
+// of the GetBoolean method for each element. A single boolean is returned as a one-element slice, returns nil
+// if the value is not found and an error naming the index of the first element that is not a boolean
+func (c *Config) GetBooleanListE(path string) ([]bool, error) {
+	value := c.Get(path)
+	if value == nil {
+		return nil, nil
+	}
+
+	array, ok := value.(Array)
+	if !ok {
+		boolean, ok := c.booleans.asBoolean(value)
+		if !ok {
+			return nil, fmt.Errorf("could not parse the value: %s at path: %s to boolean", value, path)
+		}
+
+		return []bool{boolean}, nil
+	}
+
+	booleans := make([]bool, 0, len(array))
+
+	for i, element := range array {
+		boolean, ok := c.booleans.asBoolean(element)
+		if !ok {
+			return nil, fmt.Errorf("could not parse the value: %s at index: %d of the array at path: %s to boolean", element, i, path)
+		}
+
+		booleans = append(booleans, boolean)
+	}
+
+	return booleans, nil
+}
+
+// GetDuration method finds the value at the given path and returns it as a time.Duration
+// returns 0 if the value is not found
+func (c *Config) GetDuration(path string) time.Duration {
+	value := c.Get(path)
+	if value == nil {
+		return 0
+	}
+
+	if str, ok := value.(String); ok {
+		duration, err := parseDuration(string(str))
+		if err != nil {
+			panic(err)
+		}
+
+		return duration
+	}
+
+	return time.Duration(value.(Duration))
+}
+
+// GetDurationWithDefault method finds the value at the given path and returns it as a time.Duration like the
+// GetDuration method, returns the given default value if the value is not found
+func (c *Config) GetDurationWithDefault(path string, def time.Duration) time.Duration {
+	if c.Get(path) == nil {
+		return def
+	}
+
+	return c.GetDuration(path)
+}
+
+// GetIntInRange method finds the value at the given path and returns it as an int like the GetInt method, returns an error
+// wrapping ErrOutOfRange if the value is not in the inclusive range [min, max] and ErrValueNotFound if it is not found
+func (c *Config) GetIntInRange(path string, min, max int) (int, error) {
+	if c.Get(path) == nil {
+		return 0, valueNotFoundError(path)
+	}
+
+	return checkRange(path, c.GetInt(path), min, max)
+}
+
+// GetFloat64InRange method finds the value at the given path and returns it as a float64 like the GetFloat64 method,
+// returns an error wrapping ErrOutOfRange if the value is not in the inclusive range [min, max]
+// and ErrValueNotFound if it is not found
+func (c *Config) GetFloat64InRange(path string, min, max float64) (float64, error) {
+	if c.Get(path) == nil {
+		return 0, valueNotFoundError(path)
+	}
+
+	return checkRange(path, c.GetFloat64(path), min, max)
+}
+
+// GetDurationInRange method finds the value at the given path and returns it as a time.Duration like the GetDuration method,
+// returns an error wrapping ErrOutOfRange if the value is not in the inclusive range [min, max]
+// and ErrValueNotFound if it is not found
+func (c *Config) GetDurationInRange(path string, min, max time.Duration) (time.Duration, error) {
+	if c.Get(path) == nil {
+		return 0, valueNotFoundError(path)
+	}
+
+	return checkRange(path, c.GetDuration(path), min, max)
+}
+
+// checkRange returns the value if it is in the given range, NaN and the infinities are always out of the range
+func checkRange[T int | float64 | time.Duration](path string, value, min, max T) (T, error) {
+	if number := float64(value); math.IsNaN(number) || math.IsInf(number, 0) || value < min || value > max {
+		return 0, outOfRangeError(path, value, min, max)
+	}
+
+	return value, nil
+}
+
+// GetByteSize method finds the value at the given path and returns it as a number of bytes, the value can be
+// an integer or a string with a size unit (e.g. 512MB, "512 megabytes", 1.5GiB), returns 0 if the value is not found.
+// The lowercase m is not a size unit as 10m is parsed as a duration of ten minutes, the M or Mi units are the mebibytes
+func (c *Config) GetByteSize(path string) int64 {
+	value := c.Get(path)
+	if value == nil {
+		return 0
+	}
+
+	switch val := value.(type) {
+	case Int:
+		return int64(val)
+	case String:
+		number, unit, err := splitNumberAndUnit(string(val))
+		if err != nil {
+			panic(err)
+		}
+
+		multiplier, ok := byteSizeUnits[unit]
+		if !ok {
+			panic("unknown size unit: " + unit + " in value: " + val.String())
+		}
+
+		return int64(math.Round(number * float64(multiplier)))
+	case Duration:
+		panic("cannot parse the duration: " + val.String() + " to byte size, use the M or Mi unit for the mebibytes!")
+	default:
+		panic("cannot parse value: " + val.String() + " to byte size!")
+	}
+}
+
+// GetByteSizeWithDefault method finds the value at the given path and returns it as a number of bytes like the
+// GetByteSize method, returns the given default value if the value is not found
+func (c *Config) GetByteSizeWithDefault(path string, def int64) int64 {
+	if c.Get(path) == nil {
+		return def
+	}
+
+	return c.GetByteSize(path)
+}
+
+var durationUnits = map[string]time.Duration{
+	"ns": time.Nanosecond, "nano": time.Nanosecond, "nanos": time.Nanosecond, "nanosecond": time.Nanosecond, "nanoseconds": time.Nanosecond,
+	"us": time.Microsecond, "micro": time.Microsecond, "micros": time.Microsecond, "microsecond": time.Microsecond, "microseconds": time.Microsecond,
+	"ms": time.Millisecond, "milli": time.Millisecond, "millis": time.Millisecond, "millisecond": time.Millisecond, "milliseconds": time.Millisecond,
+	"s": time.Second, "second": time.Second, "seconds": time.Second,
+	"m": time.Minute, "minute": time.Minute, "minutes": time.Minute,
+	"h": time.Hour, "hour": time.Hour, "hours": time.Hour,
+	"d": time.Hour * 24, "day": time.Hour * 24, "days": time.Hour * 24,
+}
+
+var byteSizeUnits = map[string]int64{
+	"B": 1, "b": 1, "byte": 1, "bytes": 1,
+	"kB": 1e3, "kilobyte": 1e3, "kilobytes": 1e3,
+	"K": 1 << 10, "k": 1 << 10, "Ki": 1 << 10, "KiB": 1 << 10, "kibibyte": 1 << 10, "kibibytes": 1 << 10,
+	"MB": 1e6, "megabyte": 1e6, "megabytes": 1e6,
+	"M": 1 << 20, "Mi": 1 << 20, "MiB": 1 << 20, "mebibyte": 1 << 20, "mebibytes": 1 << 20,
+	"GB": 1e9, "gigabyte": 1e9, "gigabytes": 1e9,
+	"G": 1 << 30, "g": 1 << 30, "Gi": 1 << 30, "GiB": 1 << 30, "gibibyte": 1 << 30, "gibibytes": 1 << 30,
+	"TB": 1e12, "terabyte": 1e12, "terabytes": 1e12,
+	"T": 1 << 40, "t": 1 << 40, "Ti": 1 << 40, "TiB": 1 << 40, "tebibyte": 1 << 40, "tebibytes": 1 << 40,
+}
+
+// parseDuration parses the duration written as a number and a unit with or without whitespaces between them
+func parseDuration(value string) (time.Duration, error) {
+	number, unit, err := splitNumberAndUnit(value)
+	if err != nil {
+		return 0, err
+	}
+
+	multiplier, ok := durationUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("unknown duration unit: %q in value: %q", unit, value)
+	}
+
+	return scaleDuration(number, multiplier), nil
+}
+
+// scaleDuration multiplies the unit with the possibly fractional magnitude rounding to the nearest nanosecond,
+// so that the sub-unit precision is kept, e.g. 1.5s is 1500ms and 0.5h is 30m
+func scaleDuration(magnitude float64, unit time.Duration) time.Duration {
+	return time.Duration(math.Round(magnitude * float64(unit)))
+}
+
+// splitNumberAndUnit splits the value into its leading number and the trailing unit, ignoring the whitespaces
+func splitNumberAndUnit(value string) (float64, string, error) {
+	value = strings.TrimSpace(value)
+	unitStart := strings.IndexFunc(value, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsSpace(r) })
+
+	if unitStart == -1 {
+		unitStart = len(value)
+	}
+
+	number, err := strconv.ParseFloat(value[:unitStart], 64)
+	if err != nil {
+		return 0, "", fmt.Errorf("cannot parse the number of the value: %q, %w", value, err)
+	}
+
+	return number, strings.TrimSpace(value[unitStart:]), nil
+}
+
+// BytesEncoding is the encoding of the string values read with the GetBytes method
+type BytesEncoding int
+
+// BytesEncoding constants
+const (
+	Base64Encoding BytesEncoding = iota
+	HexEncoding
+)
+
+// GetBytes method finds the string value at the given path and decodes it with the given encoding (base64 by default)
+// returns nil if the value is not found, returns an error if the value is not a string or cannot be decoded
+func (c *Config) GetBytes(path string, encoding ...BytesEncoding) ([]byte, error) {
+	value := c.Get(path)
+	if value == nil {
+		return nil, nil
+	}
+
+	str, ok := value.(String)
+	if !ok {
+		return nil, fmt.Errorf("could not decode the value: %s at path: %s, the value is not a string", value, path)
+	}
+
+	var bytes []byte
+
+	var err error
+
+	if len(encoding) > 0 && encoding[0] == HexEncoding {
+		bytes, err = hex.DecodeString(string(str))
+	} else {
+		bytes, err = base64.StdEncoding.DecodeString(string(str))
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("could not decode the value at path: %s, %w", path, err)
+	}
+
+	return bytes, nil
+}
+
+// GetEnum function finds the string value at the given path and returns it as T if it is one of the valid values,
+// returns the default value if the value is not found, returns an error listing the valid values otherwise
+// (including the values that are not strings)
+func GetEnum[T ~string](c *Config, path string, valid []T, def T) (T, error) {
+	value := c.Get(path)
+	if value == nil {
+		return def, nil
+	}
+
+	switch str := value.(type) {
+	case String:
+		for _, v := range valid {
+			if v == T(str) {
+				return v, nil
+			}
+		}
+
+		return def, fmt.Errorf("invalid value: %q at path: %s, allowed values: %q", string(str), path, valid)
+	default:
+		return def, fmt.Errorf("invalid value: %s at path: %s, the value is not a string, allowed values: %q", value, path, valid)
+	}
+}
+
+// GetIntByName method finds the object of the name to integer mappings at the given base path and returns the integer
+// of the given name, e.g. GetIntByName("priorities", os.Getenv("PRIORITY")) for priorities { low = 1, high = 9 }.
+// The name is a single key, it is not split by the periods. Returns an error wrapping ErrValueNotFound if the base
+// object is not found, an error listing the available names if the name is not found and an error if it is not an integer
+func (c *Config) GetIntByName(basePath string, name string) (int, error) {
+	value := c.Get(basePath)
+	if value == nil {
+		return 0, valueNotFoundError(basePath)
+	}
+
+	object, ok := value.(Object)
+	if !ok {
+		return 0, fmt.Errorf("value: %s at path: %s is not an object", value, basePath)
+	}
+
+	found, ok := object[name]
+	if !ok {
+		return 0, fmt.Errorf("could not find the name: %q at path: %s, available names: %q", name, basePath, object.sortedKeys())
+	}
+
+	integer, ok := AsInt(found)
+	if !ok {
+		return 0, fmt.Errorf("value: %s of the name: %q at path: %s is not an integer", found, name, basePath)
+	}
+
+	return integer, nil
+}
+
+// logLevels maps the lower-cased level names and their common aliases to the slog levels
+var logLevels = map[string]slog.Level{
+	"debug": slog.LevelDebug, "info": slog.LevelInfo, "warn": slog.LevelWarn, "warning": slog.LevelWarn, "error": slog.LevelError,
+}
+
+// GetLogLevel method finds the string value at the given path and returns it as a slog.Level, the debug, info, warn
+// (or warning) and error names are matched case-insensitively. Returns an error wrapping ErrValueNotFound if the value
+// is not found and an error listing the valid names if the value is not one of them
+func (c *Config) GetLogLevel(path string) (slog.Level, error) {
+	if c.Get(path) == nil {
+		return slog.LevelInfo, valueNotFoundError(path)
+	}
+
+	name := c.GetString(path)
+
+	level, ok := logLevels[strings.ToLower(name)]
+	if !ok {
+		names := make([]string, 0, len(logLevels))
+		for levelName := range logLevels {
+			names = append(names, levelName)
+		}
+
+		sort.Strings(names)
+
+		return slog.LevelInfo, fmt.Errorf("invalid log level: %q at path: %s, allowed values: %q", name, path, names)
+	}
+
+	return level, nil
+}
+
+// GetLogLevelWithDefault method finds the string value at the given path and returns it as a slog.Level like
+// the GetLogLevel method, returns the given default level if the value is not found
+func (c *Config) GetLogLevelWithDefault(path string, def slog.Level) (slog.Level, error) {
+	if c.Get(path) == nil {
+		return def, nil
+	}
+
+	return c.GetLogLevel(path)
+}
+
+// GetTime method finds the string value at the given path and parses it with the given layout (time.RFC3339 if empty),
+// returns the zero time and an error wrapping ErrValueNotFound if the value is not found and an error if it is not a string
+func (c *Config) GetTime(path string, layout string) (time.Time, error) {
+	value := c.Get(path)
+	if value == nil {
+		return time.Time{}, valueNotFoundError(path)
+	}
+
+	return parseTime(path, value, layout)
+}
+
+// GetTimeList method finds the array at the given path and parses each of its string elements with the given layout
+// (time.RFC3339 if empty), returns an error wrapping ErrValueNotFound if the value is not found
+func (c *Config) GetTimeList(path string, layout string) ([]time.Time, error) {
+	value := c.Get(path)
+	if value == nil {
+		return nil, valueNotFoundError(path)
+	}
+
+	array, ok := value.(Array)
+	if !ok {
+		return nil, fmt.Errorf("value at path: %s is not an array", path)
+	}
+
+	times := make([]time.Time, 0, len(array))
+
+	for i, element := range array {
+		t, err := parseTime(fmt.Sprintf("%s.%d", path, i), element, layout)
+		if err != nil {
+			return nil, err
+		}
+
+		times = append(times, t)
+	}
+
+	return times, nil
+}
+
+// GetTimeListWithDefault method finds the array at the given path and parses its elements like the GetTimeList method,
+// returns the given default value if the value is not found
+func (c *Config) GetTimeListWithDefault(path string, layout string, def []time.Time) ([]time.Time, error) {
+	if c.Get(path) == nil {
+		return def, nil
+	}
+
+	return c.GetTimeList(path, layout)
+}
+
+// parseTime parses the string value with the given layout (time.RFC3339 if empty), the other values are not times
+func parseTime(path string, value Value, layout string) (time.Time, error) {
+	str, ok := value.(String)
+	if !ok {
+		return time.Time{}, fmt.Errorf("could not parse the time: %s at path: %s, the value is not a string", value, path)
+	}
+
+	if layout == "" {
+		layout = time.RFC3339
+	}
+
+	t, err := time.Parse(layout, string(str))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("could not parse the time at path: %s, %w", path, err)
+	}
+
+	return t, nil
+}
+
+// GetRegexp method finds the string value at the given path and compiles it as a regular expression,
+// returns an error wrapping ErrValueNotFound if the value is not found and an error naming the path if the pattern is invalid
+func (c *Config) GetRegexp(path string) (*regexp.Regexp, error) {
+	value := c.Get(path)
+	if value == nil {
+		return nil, valueNotFoundError(path)
+	}
+
+	return compileRegexp(path, value)
+}
+
+// GetRegexpList method finds the array at the given path and compiles each of its string elements as a regular expression,
+// returns an error wrapping ErrValueNotFound if the value is not found
+func (c *Config) GetRegexpList(path string) ([]*regexp.Regexp, error) {
+	value := c.Get(path)
+	if value == nil {
+		return nil, valueNotFoundError(path)
+	}
+
+	array, ok := value.(Array)
+	if !ok {
+		return nil, fmt.Errorf("value at path: %s is not an array", path)
+	}
+
+	regexps := make([]*regexp.Regexp, 0, len(array))
+
+	for i, element := range array {
+		re, err := compileRegexp(fmt.Sprintf("%s.%d", path, i), element)
+		if err != nil {
+			return nil, err
+		}
+
+		regexps = append(regexps, re)
+	}
+
+	return regexps, nil
+}
+
+func compileRegexp(path string, value Value) (*regexp.Regexp, error) {
+	re, err := regexp.Compile(unquotedString(value))
+	if err != nil {
+		return nil, fmt.Errorf("could not compile the regexp at path: %s, %w", path, err)
+	}
+
+	return re, nil
+}
+
+// URLCheck selects how strictly the URLs are validated by the GetURL and GetURLList methods
+type URLCheck int
+
+// URLCheck constants
+const (
+	AnyURL      URLCheck = iota // any URL accepted by url.Parse, including the relative references
+	AbsoluteURL                 // an absolute URL with a scheme, e.g. https://example.com
+)
+
+// GetURL method finds the string value at the given path and parses it as a URL, the relative references are accepted
+// unless the AbsoluteURL check is given. Returns an error wrapping ErrValueNotFound if the value is not found
+// and an error naming the path and the value if it is not a valid URL
+func (c *Config) GetURL(path string, check ...URLCheck) (*url.URL, error) {
+	value := c.Get(path)
+	if value == nil {
+		return nil, valueNotFoundError(path)
+	}
+
+	return parseURL(path, value, check)
+}
+
+// GetURLList method finds the array at the given path and parses each of its string elements as a URL like the GetURL method,
+// returns an error wrapping ErrValueNotFound if the value is not found
+func (c *Config) GetURLList(path string, check ...URLCheck) ([]*url.URL, error) {
+	value := c.Get(path)
+	if value == nil {
+		return nil, valueNotFoundError(path)
+	}
+
+	array, ok := value.(Array)
+	if !ok {
+		return nil, fmt.Errorf("value at path: %s is not an array", path)
+	}
+
+	urls := make([]*url.URL, 0, len(array))
+
+	for i, element := range array {
+		u, err := parseURL(fmt.Sprintf("%s.%d", path, i), element, check)
+		if err != nil {
+			return nil, err
+		}
+
+		urls = append(urls, u)
+	}
+
+	return urls, nil
+}
+
+func parseURL(path string, value Value, check []URLCheck) (*url.URL, error) {
+	str := unquotedString(value)
+
+	u, err := url.Parse(str)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse the URL: %q at path: %s, %w", str, path, err)
+	}
+
+	if len(check) > 0 && check[0] == AbsoluteURL && !u.IsAbs() {
+		return nil, fmt.Errorf("could not parse the URL: %q at path: %s, the URL is not absolute", str, path)
+	}
+
+	return u, nil
+}
+
+// PercentMode selects how the GetPercent method reads the numbers written without the percent sign
+type PercentMode int
+
+// PercentMode constants
+const (
+	BareFraction        PercentMode = iota // the bare numbers are fractions, e.g. 0.8
+	BarePercentage                         // the bare numbers are percentages, e.g. 80 is 0.8
+	PercentSignRequired                    // the bare numbers are rejected, only the values like "80%" are accepted
+)
+
+// GetPercent method finds the value at the given path and returns it as a fraction in the range [0, 1], the values
+// with the percent sign are divided by 100 (e.g. "80%" is 0.8) and the bare numbers are read as selected by the mode,
+// as fractions by default. Returns an error wrapping ErrValueNotFound if the value is not found, an error wrapping
+// ErrOutOfRange if the fraction is not in the range, and an error naming the path if the value is not a percentage
+func (c *Config) GetPercent(path string, mode ...PercentMode) (float64, error) {
+	value := c.Get(path)
+	if value == nil {
+		return 0, valueNotFoundError(path)
+	}
+
+	text := strings.TrimSpace(unquotedString(value))
+	if number, ok := AsFloat64(value); ok {
+		text = strconv.FormatFloat(number, 'f', -1, 64)
+	}
+
+	number, isPercentage := strings.CutSuffix(text, "%")
+
+	fraction, err := strconv.ParseFloat(strings.TrimSpace(number), 64)
+	if err != nil {
+		return 0, fmt.Errorf("could not parse the percentage: %q at path: %s", text, path)
+	}
+
+	switch {
+	case isPercentage:
+		fraction /= 100
+	case len(mode) > 0 && mode[0] == BarePercentage:
+		fraction /= 100
+	case len(mode) > 0 && mode[0] == PercentSignRequired:
+		return 0, fmt.Errorf("could not parse the percentage: %q at path: %s, the percent sign is required", text, path)
+	}
+
+	if math.IsNaN(fraction) || fraction < 0 || fraction > 1 { // the infinities are out of the range as well
+		return 0, outOfRangeError(path, fraction, 0, 1)
+	}
+
+	return fraction, nil
+}
+
+// unquotedString returns the string as it is for the String values, unlike the String method
+// that quotes the strings containing ':', and the string representation of the other values
+func unquotedString(value Value) string {
+	if s, ok := value.(String); ok {
+		return string(s)
+	}
+
+	return value.String()
+}
+
+// IsQuoted method reports whether the value at the given path is a string that was written as a quoted
+// (or multi-line) string in the parsed configuration, returns false for unquoted and concatenated strings.
+// The quoted strings are kept by the WithFallback method and the MergeConfigs function for the values they take
+// from the merged configs
+func (c *Config) IsQuoted(path string) bool {
+	if _, ok := c.Get(path).(String); !ok {
+		return false
+	}
+
+	return c.quoted[path]
+}
+
+// RawText method returns the source text of the value at the given path as it was written, before the substitutions
+// are resolved and the strings are unquoted. Reports false if the value is not found or the configuration
+// is not parsed with the WithRawText option
+func (c *Config) RawText(path string) (string, bool) {
+	text, ok := c.raw[path]
+	return text, ok
+}
+
+// HasPath method reports whether there is a value at the given path, a null value counts as present
+func (c *Config) HasPath(path string) bool {
+	return c.Get(path) != nil
+}
+
+// Get method finds the value at the given path and returns it without casting to any type, numeric path keys
+// index into arrays (e.g. "clusters.0.nodes.2.address"), returns nil if the value is not found
+func (c *Config) Get(path string) Value {
+	if c.lazy != nil {
+		return c.lazy.lookup(c, path)
+	}
+
+	return find(c.root, path)
+}
+
+// GetPointer method finds the value at the given RFC 6901 JSON Pointer, e.g. "/servers/0/host", as an alternative to
+// the path expressions for the keys that contain periods. The tokens are separated with slashes, "~1" and "~0" in them
+// stand for '/' and '~', the array indices are the non-negative numbers without leading zeros. The empty pointer
+// refers to the root, reports false if the value is not found or the pointer is malformed
+func (c *Config) GetPointer(pointer string) (Value, bool) {
+	if pointer == "" {
+		return c.GetRoot(), true
+	}
+
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, false
+	}
+
+	unescape := strings.NewReplacer("~1", "/", "~0", "~")
+	value := c.GetRoot()
+
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = unescape.Replace(token)
+
+		switch v := value.(type) {
+		case Object:
+			found, ok := v[token]
+			if !ok {
+				return nil, false
+			}
+
+			value = found
+		case Array:
+			index, ok := arrayIndex(token, len(v))
+			if !ok || !isPointerIndex(token) {
+				return nil, false
+			}
+
+			value = v[index]
+		default:
+			return nil, false
+		}
+	}
+
+	return value, true
+}
+
+// isPointerIndex reports whether the JSON Pointer token is an array index, unlike the path keys
+// the indices cannot be negative or have leading zeros
+func isPointerIndex(token string) bool {
+	if token == "" || token != "0" && token[0] == '0' {
+		return false
+	}
+
+	for _, r := range token {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ResolvePath method finds the value at the given path and returns it with the canonical form of the path,
+// in which the keys are separated with periods, the keys that need quoting (e.g. the ones containing a period)
+// are quoted and the array indexes are normalized, e.g. "a"."b.c".01 becomes a."b.c".1. Reports false if the value is not found
+func (c *Config) ResolvePath(path string) (string, Value, bool) {
+	canonicalKeys := make([]string, 0)
+
+	value := findPath(c.GetRoot(), path, func(key string) { canonicalKeys = append(canonicalKeys, quoteKeyIfNeeded(key)) })
+	if value == nil {
+		return "", nil, false
+	}
+
+	return strings.Join(canonicalKeys, dotToken), value, true
+}
+
+// quoteKeyIfNeeded quotes the key if it cannot be written as an unquoted key in a path expression
+func quoteKeyIfNeeded(key string) string {
+	needsQuoting := key == ""
+
+	for _, r := range key {
+		if r == '.' || unicode.IsSpace(r) || forbiddenCharacters[string(r)] {
+			needsQuoting = true
+			break
+		}
+	}
+
+	if !needsQuoting {
+		return key
+	}
+
+	quoted, _ := json.Marshal(key)
+
+	return string(quoted)
+}
+
+// ValuesOfType method returns the values of the given type in the subtree at the given path (including the value at the path),
+// in the order of their paths with the object keys sorted and the array elements in order, e.g. ValuesOfType("secrets", StringType)
+// returns all the string leaves under secrets. The empty path refers to the root, returns nil if the value is not found
+func (c *Config) ValuesOfType(path string, valueType Type) []Value {
+	var values []Value
+
+	c.walkSubtree(path, func(_ string, value Value) {
+		if value.Type() == valueType {
+			values = append(values, value)
+		}
+	})
+
+	return values
+}
+
+// PathsOfType method returns the paths of the values that the ValuesOfType method returns for the same arguments
+func (c *Config) PathsOfType(path string, valueType Type) []string {
+	var paths []string
+
+	c.walkSubtree(path, func(valuePath string, value Value) {
+		if value.Type() == valueType {
+			paths = append(paths, valuePath)
+		}
+	})
+
+	return paths
+}
+
+// MatchPaths method returns the paths of the leaf values (the values that are not objects, including the arrays)
+// matching the given glob pattern in sorted order. The segments of the pattern are matched against the keys with
+// path.Match, e.g. * matches any single key and db* the keys starting with db, and the ** segment matches
+// any number of keys, e.g. servers.*.port or **.enabled
+func (c *Config) MatchPaths(pattern string) []string {
+	var paths []string
+
+	var collect func(keys []string, value Value)
+	collect = func(keys []string, value Value) {
+		object, ok := value.(Object)
+		if !ok {
+			if len(keys) > 0 && matchPathPattern(splitPath(pattern), keys) {
+				paths = append(paths, joinPath(keys))
+			}
+
+			return
+		}
+
+		for _, key := range object.sortedKeys() {
+			collect(append(keys[:len(keys):len(keys)], key), object[key])
+		}
+	}
+
+	collect(nil, c.GetRoot())
+
+	return paths
+}
+
+func matchPathPattern(pattern []string, keys []string) bool {
+	if len(pattern) == 0 {
+		return len(keys) == 0
+	}
+
+	if pattern[0] == "**" {
+		for i := 0; i <= len(keys); i++ {
+			if matchPathPattern(pattern[1:], keys[i:]) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if len(keys) == 0 {
+		return false
+	}
+
+	if matched, err := path.Match(pattern[0], keys[0]); (err != nil || !matched) && pattern[0] != keys[0] {
+		return false
+	}
+
+	return matchPathPattern(pattern[1:], keys[1:])
+}
+
+func (c *Config) walkSubtree(path string, fn func(path string, value Value)) {
+	value := c.GetRoot()
+	if path != "" {
+		value = c.Get(path)
+	}
+
+	if value != nil {
+		walk(path, value, fn)
+	}
+}
+
+// walk calls the given function for the value and all of its descendants with their paths, depth-first
+// with the object keys sorted
+func walk(path string, value Value, fn func(path string, value Value)) {
+	fn(path, value)
+
+	childPath := func(key string) string {
+		if path == "" {
+			return key
+		}
+
+		return path + dotToken + key
+	}
+
+	switch v := value.(type) {
+	case Object:
+		for _, key := range v.sortedKeys() {
+			walk(childPath(quoteKeyIfNeeded(key)), v[key], fn)
+		}
+	case Array:
+		for i, element := range v {
+			walk(childPath(strconv.Itoa(i)), element, fn)
+		}
+	}
+}
+
+// NullMode controls how the null values of the current config are merged while applying a fallback config
+type NullMode int
+
+// NullMode constants
+const (
+	NullWins         NullMode = iota // an explicit null overrides the fallback value
+	NullFallsThrough                 // a null is treated as absent, so the fallback value is used
+)
+
+func (c *Config) copy() *Config {
+	if object, ok := c.GetRoot().(Object); ok {
+		config := object.copy().ToConfig()
+		config.booleans = c.booleans
+
+		return config
+	}
+
+	return &Config{root: c.root, booleans: c.booleans}
+}
+
+// WithValue method returns a copy of the Config with the given value set at the given path, the missing objects
+// of the path are created and the non-object values on the path are replaced with objects, like the HOCON fields
+// with the same path do. The current config is not modified
+func (c *Config) WithValue(path string, value Value) *Config {
+	root, ok := c.GetRoot().(Object)
+	if ok {
+		root = root.copy()
+	} else {
+		root = Object{}
+	}
+
+	keys := splitPath(path)
+	current := root
+
+	for _, key := range keys[:len(keys)-1] {
+		object, ok := current[key].(Object)
+		if !ok {
+			object = Object{}
+			current[key] = object
+		}
+
+		current = object
+	}
+
+	current[keys[len(keys)-1]] = value
+
+	return root.ToConfig()
+}
+
+// Unset method returns a copy of the Config without the value at the given path, the objects of the path that become
+// empty are removed as well if pruneEmpty is true. Unsetting a non-existing path returns an equivalent copy,
+// the current config is not modified
+func (c *Config) Unset(path string, pruneEmpty ...bool) *Config {
+	result := c.copy()
+
+	root, ok := result.root.(Object)
+	if !ok {
+		return result
+	}
+
+	keys := splitPath(path)
+	objects := []Object{root}
+
+	for _, key := range keys[:len(keys)-1] {
+		object, ok := objects[len(objects)-1][key].(Object)
+		if !ok {
+			return result
+		}
+
+		objects = append(objects, object)
+	}
+
+	delete(objects[len(objects)-1], keys[len(keys)-1])
+
+	if len(pruneEmpty) > 0 && pruneEmpty[0] {
+		for i := len(objects) - 1; i > 0 && len(objects[i]) == 0; i-- {
+			delete(objects[i-1], keys[i-1])
+		}
+	}
+
+	return result
+}
+
+// WithFallback method returns a new *Config (or the current config, if the given fallback doesn't get used)
+// 1. merges the values of the current and fallback *Configs, if the root of both of them are of type Object
+// for the same keys current values overrides the fallback values, nulls are handled with the given NullMode (NullWins by default)
+// 2. if any of the *Configs has non-object root then returns the current *Config ignoring the fallback parameter
+func (c *Config) WithFallback(fallback *Config, nullMode ...NullMode) *Config {
+	if current, ok := c.GetRoot().(Object); ok {
+		if fallbackObject, ok := fallback.GetRoot().(Object); ok {
+			resultConfig := fallbackObject.copy()
+			merger{nullFallsThrough: len(nullMode) > 0 && nullMode[0] == NullFallsThrough}.merge(resultConfig, current)
 
-	for _, v := range arr {
-		slice = append(slice, int(v.(Int)))
+			config := resultConfig.ToConfig()
+			config.quoted = c.mergeQuoted(fallback.quoted)
+
+			return config
+		}
 	}
 
-	return slice
+	return c
 }
 
-// GetStringSlice method finds the value at the given path and returns it as []string
-// returns nil if the value is not found
-func (c *Config) GetStringSlice(path string) []string {
-	value := c.Get(path)
-	if value == nil {
-		return nil
+// MergeMap method returns a new *Config with the given Go map merged into the current config recursively,
+// the values of the map override the current values. The map values are converted like the Builder.Set method does,
+// returns an error if a value is not supported, if the root is not an object or if an object would be replaced
+// with a non-null non-object value (or the other way around)
+func (c *Config) MergeMap(m map[string]interface{}) (*Config, error) {
+	root, ok := c.GetRoot().(Object)
+	if !ok {
+		return nil, errors.New("could not merge the map, the root of the config is not an object")
 	}
 
-	arr := value.(Array)
-	slice := make([]string, 0, len(arr))
+	value, err := toValue(m)
+	if err != nil {
+		return nil, fmt.Errorf("could not merge the map, %w", err)
+	}
 
-	for _, v := range arr {
-		slice = append(slice, v.String())
+	if err := checkMergeConflicts(root, value.(Object)); err != nil {
+		return nil, err
 	}
 
-	return slice
+	merged := root.copy()
+	merger{}.merge(merged, value.(Object))
+
+	return merged.ToConfig(), nil
 }
 
-// GetString method finds the value at the given path and returns it as a String
-// returns empty string if the value is not found
-func (c *Config) GetString(path string) string {
-	value := c.Get(path)
-	if value == nil {
-		return ""
+// checkMergeConflicts returns an error for the first path (in sorted order) where an object and a non-null
+// non-object value would be merged with each other
+func checkMergeConflicts(existing Object, new Object) error {
+	if conflict := findMergeConflict(nil, existing, new); conflict != nil {
+		return fmt.Errorf("could not merge the value at path: %s, cannot merge %s with %s",
+			joinPath(conflict.path), typeName(conflict.existing), typeName(conflict.new))
 	}
 
-	return value.String()
+	return nil
 }
 
-// GetInt method finds the value at the given path and returns it as an Int, returns zero if the value is not found
-func (c *Config) GetInt(path string) int {
-	value := c.Get(path)
-	if value == nil {
-		return 0
+// mergeConflict is an object and a non-object value at the same path that would be merged with each other
+type mergeConflict struct {
+	path     []string
+	existing Value
+	new      Value
+}
+
+// findMergeConflict returns the first conflict (in sorted order of the keys) between the existing and the new value
+// at the given path, or nil if they can be merged. The null values and the values whose type is only known after
+// the resolution (substitutions and concatenations) never conflict
+func findMergeConflict(path []string, existing Value, new Value) *mergeConflict {
+	if !hasKnownType(existing) || !hasKnownType(new) {
+		return nil
 	}
 
-	switch val := value.(type) {
-	case Int:
-		return int(val)
-	case String:
-		intValue, err := strconv.Atoi(string(val))
-		if err != nil {
-			panic(err)
+	existingObject, existingIsObject := existing.(Object)
+	newObject, newIsObject := new.(Object)
+
+	switch {
+	case existingIsObject && newIsObject:
+		for _, key := range newObject.sortedKeys() {
+			existingValue, ok := existingObject[key]
+			if !ok {
+				continue
+			}
+
+			keyPath := append(path[:len(path):len(path)], key)
+			if conflict := findMergeConflict(keyPath, existingValue, newObject[key]); conflict != nil {
+				return conflict
+			}
 		}
-
-		return intValue
-	default:
-		panic("cannot parse value: " + val.String() + " to int!")
+	case existingIsObject || newIsObject:
+		return &mergeConflict{path: path, existing: existing, new: new}
 	}
+
+	return nil
 }
 
-// GetFloat32 method finds the value at the given path and returns it as a Float32
-// returns float32(0.0) if the value is not found
-func (c *Config) GetFloat32(path string) float32 {
-	value := c.Get(path)
+func hasKnownType(value Value) bool {
 	if value == nil {
-		return float32(0.0)
+		return false
 	}
 
-	switch val := value.(type) {
-	case Float32:
-		return float32(val)
-	case Float64:
-		return float32(val)
-	case String:
-		floatValue, err := strconv.ParseFloat(string(val), 32)
-		if err != nil {
-			panic(err)
-		}
-
-		return float32(floatValue)
+	switch value.Type() {
+	case NullType, SubstitutionType, ConcatenationType, valueWithAlternativeType:
+		return false
 	default:
-		panic("cannot parse value: " + val.String() + " to float32!")
+		return true
 	}
 }
 
-// GetFloat64 method finds the value at the given path and returns it as a Float64
-// returns 0.0 if the value is not found
-func (c *Config) GetFloat64(path string) float64 {
-	value := c.Get(path)
-	if value == nil {
-		return 0.0
+// joinPath joins the keys into a path, quoting the keys where needed
+func joinPath(keys []string) string {
+	quoted := make([]string, len(keys))
+	for i, key := range keys {
+		quoted[i] = quoteKeyIfNeeded(key)
 	}
 
-	switch val := value.(type) {
-	case Float64:
-		return float64(val)
-	case Float32:
-		return float64(val)
-	case String:
-		floatValue, err := strconv.ParseFloat(string(val), 64)
-		if err != nil {
-			panic(err)
-		}
+	return strings.Join(quoted, dotToken)
+}
 
-		return floatValue
-	default:
-		panic("cannot parse value: " + val.String() + "to float64!")
+func typeName(value Value) string {
+	if value.Type() == ObjectType {
+		return "an object"
 	}
+
+	return "a non-object value"
 }
 
-// GetBoolean method finds the value at the given path and returns it as a Boolean
-// returns false if the value is not found
-func (c *Config) GetBoolean(path string) bool {
-	value := c.Get(path)
-	if value == nil {
-		return false
-	}
+// MergeConfigs function merges the given configs in order, the values of the later configs override the values
+// of the earlier ones and the objects are merged recursively. The configs with a non-object root are ignored.
+// The nulls are handled with the given NullMode like the WithFallback method does (NullWins by default)
+func MergeConfigs(configs []*Config, nullMode ...NullMode) *Config {
+	merged, _ := MergeConfigsVerbose(configs, nullMode...)
+	return merged
+}
 
-	switch val := value.(type) {
-	case Boolean:
-		return bool(val)
-	case String:
-		switch val {
-		case "true", "yes", "on":
-			return true
-		case "false", "no", "off":
-			return false
-		default:
-			panic("cannot parse value: " + val + " to boolean!")
-		}
-	default:
-		panic("cannot parse value: " + val.String() + " to boolean!")
-	}
+// Override describes a value of a merged config that is replaced with a different value by a later config
+type Override struct {
+	Path string
+	Old  Value
+	New  Value
 }
 
-// GetDuration method finds the value at the given path and returns it as a time.Duration
-// returns 0 if the value is not found
-func (c *Config) GetDuration(path string) time.Duration {
-	value := c.Get(path)
-	if value == nil {
-		return 0
+// MergeConfigsVerbose function merges the given configs like MergeConfigs and additionally returns the overridden values
+// in the order of the configs that override them and sorted by path for each config,
+// e.g. to audit which defaults are overridden by an environment specific config
+func MergeConfigsVerbose(configs []*Config, nullMode ...NullMode) (*Config, []Override) {
+	var overrides []Override
+
+	m := merger{nullFallsThrough: len(nullMode) > 0 && nullMode[0] == NullFallsThrough, onOverride: func(path string, existing, new Value) {
+		overrides = append(overrides, Override{Path: path, Old: existing, New: new})
+	}}
+
+	merged := Object{}
+
+	var quoted map[string]bool
+
+	for _, config := range configs {
+		if object, ok := config.GetRoot().(Object); ok {
+			start := len(overrides)
+			m.merge(merged, object.copy())
+			quoted = config.mergeQuoted(quoted)
+
+			configOverrides := overrides[start:]
+			sort.Slice(configOverrides, func(i, j int) bool { return configOverrides[i].Path < configOverrides[j].Path })
+		}
 	}
 
-	return time.Duration(value.(Duration))
+	config := merged.ToConfig()
+	config.quoted = quoted
+
+	return config, overrides
 }
 
-// Get method finds the value at the given path and returns it without casting to any type
-// returns nil if the value is not found
-func (c *Config) Get(path string) Value {
-	if c.root.Type() != ObjectType {
+// mergeQuoted returns the paths of the quoted strings of the config merged over the given quoted paths of the fallback,
+// the quoted paths of the fallback are kept where the config does not set a value (or sets it to null). The other
+// metadata of the parsed configs (e.g. the raw text and the warnings) is not carried to the merged configs
+func (c *Config) mergeQuoted(fallback map[string]bool) map[string]bool {
+	if len(c.quoted) == 0 && len(fallback) == 0 {
 		return nil
 	}
 
-	return c.root.(Object).find(path)
-}
-
-// WithFallback method returns a new *Config (or the current config, if the given fallback doesn't get used)
-// 1. merges the values of the current and fallback *Configs, if the root of both of them are of type Object
-// for the same keys current values overrides the fallback values
-// 2. if any of the *Configs has non-object root then returns the current *Config ignoring the fallback parameter
-func (c *Config) WithFallback(fallback *Config) *Config {
-	if current, ok := c.root.(Object); ok {
-		if fallbackObject, ok := fallback.root.(Object); ok {
-			resultConfig := fallbackObject.copy()
-			mergeObjects(resultConfig, current)
+	quoted := make(map[string]bool, len(c.quoted)+len(fallback))
+	for path := range c.quoted {
+		quoted[path] = true
+	}
 
-			return resultConfig.ToConfig()
+	for path := range fallback {
+		if value := c.Get(path); value == nil || value.Type() == NullType {
+			quoted[path] = true
 		}
 	}
 
-	return c
+	return quoted
 }
 
 // Value interface represents a value in the configuration tree, all the value types implements this interface
@@ -279,6 +1845,76 @@ type Value interface {
 	isConcatenable() bool
 }
 
+// AsObject function returns the given value as an Object, ok is false if it is not an object
+func AsObject(value Value) (Object, bool) {
+	object, ok := value.(Object)
+	return object, ok
+}
+
+// AsArray function returns the given value as an Array, ok is false if it is not an array
+func AsArray(value Value) (Array, bool) {
+	array, ok := value.(Array)
+	return array, ok
+}
+
+// AsString function returns the given string value as a Go string, ok is false if it is not a string
+func AsString(value Value) (string, bool) {
+	str, ok := value.(String)
+	return string(str), ok
+}
+
+// AsInt function returns the given value as an int, the integers and the strings of integers (e.g. "10") are converted,
+// ok is false for the other values
+func AsInt(value Value) (int, bool) {
+	switch v := value.(type) {
+	case Int:
+		return int(v), true
+	case String:
+		i, err := strconv.Atoi(string(v))
+		return i, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// AsFloat64 function returns the given value as a float64, the numbers and the strings of numbers (e.g. "0.5") are converted,
+// ok is false for the other values
+func AsFloat64(value Value) (float64, bool) {
+	switch v := value.(type) {
+	case Float64:
+		return float64(v), true
+	case Float32:
+		return float64(v), true
+	case Int:
+		return float64(v), true
+	case String:
+		f, err := strconv.ParseFloat(string(v), 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// AsBoolean function returns the given value as a bool, the booleans and the boolean spellings (e.g. "yes") are converted,
+// ok is false for the other values
+func AsBoolean(value Value) (bool, bool) {
+	return booleanSpellings(nil).asBoolean(value)
+}
+
+// AsDuration function returns the given value as a time.Duration, the durations and the strings with a duration unit
+// (e.g. "10 seconds") are converted, ok is false for the other values
+func AsDuration(value Value) (time.Duration, bool) {
+	switch v := value.(type) {
+	case Duration:
+		return time.Duration(v), true
+	case String:
+		duration, err := parseDuration(string(v))
+		return duration, err == nil
+	default:
+		return 0, false
+	}
+}
+
 // String represents a string value
 type String string
 
@@ -316,70 +1952,153 @@ type Object map[string]Value
 func (o Object) Type() Type           { return ObjectType }
 func (o Object) isConcatenable() bool { return false }
 
-// String method returns the string representation of the Object
-func (o Object) String() string {
-	var builder strings.Builder
-
-	itemsSize := len(o)
-	i := 1
-
-	builder.WriteString(objectStartToken)
+// String method returns the string representation of the Object, keys are written in sorted order
+func (o Object) String() string { return renderToString(o) }
 
-	for key, value := range o {
-		builder.WriteString(key)
-		builder.WriteString(colonToken)
-		builder.WriteString(value.String())
+// Entry is a key and its value in an Object
+type Entry struct {
+	Key   string
+	Value Value
+}
 
-		if i < itemsSize {
-			builder.WriteString(", ")
-		}
-		i++
+// Entries method returns the keys and the values of the Object in sorted order of the keys, the returned slice
+// is created on each call so changing it does not affect the Object
+func (o Object) Entries() []Entry {
+	entries := make([]Entry, 0, len(o))
+	for _, key := range o.sortedKeys() {
+		entries = append(entries, Entry{Key: key, Value: o[key]})
 	}
 
-	builder.WriteString(objectEndToken)
-
-	return builder.String()
+	return entries
 }
 
 // ToConfig method converts object to *Config
 func (o Object) ToConfig() *Config {
-	return &Config{o}
+	return &Config{root: o}
 }
 
 func (o Object) find(path string) Value {
-	keys := strings.Split(path, dotToken)
-	size := len(keys)
-	lastKey := keys[size-1]
-	keysWithoutLast := keys[:size-1]
-	object := o
-
-	for _, key := range keysWithoutLast {
-		value, ok := object[key]
-		if !ok {
+	return find(o, path)
+}
+
+// find descends the given value with the keys of the path, numeric keys are used as indices of the arrays
+// returns nil if any of the keys does not exist or the path descends into a non-container value
+func find(value Value, path string) Value {
+	return findPath(value, path, nil)
+}
+
+// findPath follows the keys of the path from the given value and returns the value at the end of it, or nil if it is
+// not found. The visit function (if not nil) is called with the key of each step, the array indexes are normalized
+// (e.g. -1 and 01 become the index from the start)
+func findPath(value Value, path string, visit func(key string)) Value {
+	for _, key := range splitPath(path) {
+		switch v := value.(type) {
+		case Object:
+			found, ok := v[key]
+			if !ok {
+				return nil
+			}
+
+			value = found
+		case Array:
+			index, ok := arrayIndex(key, len(v))
+			if !ok {
+				return nil
+			}
+
+			if visit != nil {
+				key = strconv.Itoa(index)
+			}
+
+			value = v[index]
+		default:
 			return nil
 		}
 
-		object = value.(Object)
+		if visit != nil {
+			visit(key)
+		}
+	}
+
+	return value
+}
+
+// arrayIndex converts the path key to an index of the array with the given length, the negative indices count from
+// the end of the array (e.g. -1 is the last element). Reports false if the key is not a number or it is out of range
+func arrayIndex(key string, length int) (int, bool) {
+	index, err := strconv.Atoi(key)
+	if err != nil {
+		return 0, false
+	}
+
+	if index < 0 {
+		index += length
+	}
+
+	return index, index >= 0 && index < length
+}
+
+// splitPath splits the path expression into its keys by the periods outside the quoted segments,
+// the quoted segments are unquoted so that "a.b".c refers to the key c of the key a.b
+func splitPath(path string) []string {
+	if !strings.Contains(path, `"`) {
+		return strings.Split(path, dotToken)
+	}
+
+	var keys []string
+
+	start, quoted, escaped := 0, false, false
+
+	for i, r := range path {
+		switch {
+		case escaped:
+			escaped = false
+		case quoted && r == '\\':
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+		case !quoted && r == '.':
+			keys = append(keys, unquote(path[start:i]))
+			start = i + 1
+		}
 	}
 
-	return object[lastKey]
+	return append(keys, unquote(path[start:]))
+}
+
+// normalizePath converts the path expression to the periods separated keys without the quotes
+func normalizePath(path string) string {
+	return strings.Join(splitPath(path), dotToken)
 }
 
+// copy returns a deep copy of the object, the nested objects and arrays are copied as well so the copy can be
+// modified (e.g. merged into) without changing the original
 func (o Object) copy() Object {
 	result := Object{}
 
 	for k, v := range o {
-		subObject, ok := v.(Object)
-		if ok {
-			result[k] = subObject.copy()
-		} else {
-			result[k] = v
-		}
+		result[k] = copyValue(v)
 	}
 
 	return result
 }
 
+func copyValue(value Value) Value {
+	switch v := value.(type) {
+	case Object:
+		return v.copy()
+	case Array:
+		array := make(Array, len(v))
+		for i, element := range v {
+			array[i] = copyValue(element)
+		}
+
+		return array
+	default:
+		return value
+	}
+}
+
 // Array represents an array node in the configuration tree
 type Array []Value
 
@@ -388,25 +2107,7 @@ func (a Array) Type() Type           { return ArrayType }
 func (a Array) isConcatenable() bool { return false }
 
 // String method returns the string representation of the Array
-func (a Array) String() string {
-	if len(a) == 0 {
-		return "[]"
-	}
-
-	var builder strings.Builder
-
-	builder.WriteString(arrayStartToken)
-	builder.WriteString(a[0].String())
-
-	for _, value := range a[1:] {
-		builder.WriteString(commaToken)
-		builder.WriteString(value.String())
-	}
-
-	builder.WriteString(arrayEndToken)
-
-	return builder.String()
-}
+func (a Array) String() string { return renderToString(a) }
 
 // Int represents an Integer value
 type Int int
@@ -436,14 +2137,111 @@ func (f Float64) isConcatenable() bool { return false }
 type Boolean bool
 
 func newBooleanFromString(value string) Boolean {
-	switch value {
-	case "true", "yes", "on":
-		return true
-	case "false", "no", "off":
-		return false
-	default:
+	boolValue, ok := lookupBoolean(value)
+	if !ok {
 		panic(fmt.Sprintf("cannot parse value: %s to Boolean!", value))
 	}
+
+	return Boolean(boolValue)
+}
+
+// registeredBooleans stores the lower-cased custom boolean spellings registered with the RegisterBooleanSpellings
+var registeredBooleans = struct {
+	sync.RWMutex
+	spellings map[string]bool
+}{spellings: map[string]bool{}}
+
+// RegisterBooleanSpellings function registers additional spellings for the true and false values (e.g. enabled/disabled)
+// in addition to the default true/yes/on and false/no/off, registered spellings are matched case-insensitively.
+// Returns an error if a spelling is empty or would map to both true and false.
+// The registered spellings are not consulted by the parses with the WithBooleanSpellings option and the configs they create.
+//
+// Deprecated: the spellings are registered for the whole process and affect every other parse, use the WithBooleanSpellings
+// option to set them per parse instead
+func RegisterBooleanSpellings(trueSpellings, falseSpellings []string) error {
+	registeredBooleans.Lock()
+	defer registeredBooleans.Unlock()
+
+	return addBooleanSpellings(registeredBooleans.spellings, trueSpellings, falseSpellings)
+}
+
+// addBooleanSpellings adds the lower-cased spellings to the given ones if none of them is empty or conflicts
+// with the default or the given spellings, otherwise the given spellings are left unchanged
+func addBooleanSpellings(spellings map[string]bool, trueSpellings, falseSpellings []string) error {
+	newSpellings := make(map[string]bool, len(trueSpellings)+len(falseSpellings))
+
+	for _, group := range []struct {
+		value     bool
+		spellings []string
+	}{{true, trueSpellings}, {false, falseSpellings}} {
+		value := group.value
+
+		for _, spelling := range group.spellings {
+			lowered := strings.ToLower(spelling)
+			if lowered == "" {
+				return errors.New("boolean spelling cannot be empty")
+			}
+
+			existing, ok := defaultBooleans[lowered]
+			if !ok {
+				existing, ok = spellings[lowered]
+			}
+
+			if !ok {
+				existing, ok = newSpellings[lowered]
+			}
+
+			if ok && existing != value {
+				return fmt.Errorf("boolean spelling: %q cannot be registered as %t, it is already %t", spelling, value, existing)
+			}
+
+			newSpellings[lowered] = value
+		}
+	}
+
+	for spelling, value := range newSpellings {
+		spellings[spelling] = value
+	}
+
+	return nil
+}
+
+var defaultBooleans = map[string]bool{"true": true, "yes": true, "on": true, "false": false, "no": false, "off": false}
+
+// booleanSpellings is a set of the lower-cased custom boolean spellings of a parse, set with the WithBooleanSpellings option.
+// A nil set stands for the spellings registered with the deprecated RegisterBooleanSpellings function
+type booleanSpellings map[string]bool
+
+func (s booleanSpellings) lookup(value string) (bool, bool) {
+	if boolValue, ok := defaultBooleans[value]; ok {
+		return boolValue, true
+	}
+
+	if s == nil {
+		registeredBooleans.RLock()
+		defer registeredBooleans.RUnlock()
+
+		s = registeredBooleans.spellings
+	}
+
+	boolValue, ok := s[strings.ToLower(value)]
+
+	return boolValue, ok
+}
+
+func (s booleanSpellings) asBoolean(value Value) (bool, bool) {
+	switch v := value.(type) {
+	case Boolean:
+		return bool(v), true
+	case String:
+		return s.lookup(string(v))
+	default:
+		return false, false
+	}
+}
+
+func lookupBoolean(value string) (bool, bool) {
+	return booleanSpellings(nil).lookup(value)
 }
 
 // Type Boolean
@@ -455,6 +2253,7 @@ func (b Boolean) isConcatenable() bool { return true }
 type Substitution struct {
 	path     string
 	optional bool
+	parts    []Value // literal Strings and nested Substitutions of a path written with nested substitutions, e.g. a.${b}
 }
 
 // Type Substitution
@@ -501,19 +2300,34 @@ func (c concatenation) Type() Type           { return ConcatenationType }
 func (c concatenation) isConcatenable() bool { return true }
 func (c concatenation) containsObject() bool {
 	for _, value := range c {
-		if value.Type() == ObjectType {
+		if value != nil && value.Type() == ObjectType {
 			return true
 		}
 	}
 
 	return false
 }
-func (c concatenation) String() string {
-	var builder strings.Builder
 
+// containsArray reports whether the concatenation is an array concatenation created by the += operator,
+// i.e. it ends with an array
+func (c concatenation) containsArray() bool {
+	if len(c) == 0 {
+		return false
+	}
+
+	_, ok := c[len(c)-1].(Array)
+
+	return ok
+}
+
+func (c concatenation) containsSubstitution() bool {
 	for _, value := range c {
-		builder.WriteString(value.String())
+		if value != nil && value.Type() == SubstitutionType {
+			return true
+		}
 	}
 
-	return builder.String()
+	return false
 }
+
+func (c concatenation) String() string { return renderToString(c) }
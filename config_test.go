@@ -1,14 +1,18 @@
 package hocon
 
 import (
+	"errors"
 	"fmt"
+	"log/slog"
+	"math"
+	"sync"
 	"testing"
 	"time"
 )
 
 func TestGetRoot(t *testing.T) {
 	root := Object{"a": Object{"b": String("c")}, "d": Array{}}
-	config := &Config{root}
+	config := &Config{root: root}
 
 	t.Run("get root value", func(t *testing.T) {
 		got := config.GetRoot()
@@ -17,7 +21,7 @@ func TestGetRoot(t *testing.T) {
 }
 
 func TestGetObject(t *testing.T) {
-	config := &Config{Object{"a": Object{"b": String("c")}, "d": Array{}}}
+	config := &Config{root: Object{"a": Object{"b": String("c")}, "d": Array{}}}
 
 	t.Run("get object", func(t *testing.T) {
 		got := config.GetObject("a")
@@ -37,8 +41,8 @@ func TestGetObject(t *testing.T) {
 }
 
 func TestGetConfig(t *testing.T) {
-	nestedConfig := &Config{Object{"b": String("c"), "d": Array{}}}
-	config := &Config{Object{"a": nestedConfig.root}}
+	nestedConfig := &Config{root: Object{"b": String("c"), "d": Array{}}}
+	config := &Config{root: Object{"a": nestedConfig.root}}
 
 	t.Run("get nested config", func(t *testing.T) {
 		got := config.GetConfig("a")
@@ -53,15 +57,106 @@ func TestGetConfig(t *testing.T) {
 	})
 }
 
+func TestMustGetConfig(t *testing.T) {
+	nestedConfig := &Config{root: Object{"b": String("c")}}
+	config := &Config{root: Object{"a": nestedConfig.root, "d": Int(1)}}
+
+	t.Run("get nested config", func(t *testing.T) {
+		got := config.MustGetConfig("a")
+		assertDeepEqual(t, got, nestedConfig)
+	})
+
+	t.Run("panic with the path if the config does not exist", func(t *testing.T) {
+		assertPanic(t, func() { config.MustGetConfig("b") }, "could not find the required config at path: b")
+	})
+
+	t.Run("panic if the value at the path is not an object", func(t *testing.T) {
+		assertPanic(t, func() { config.MustGetConfig("d") }, "value: 1 at path: d is not an object!")
+	})
+}
+
+func TestGetConfigStrict(t *testing.T) {
+	config := &Config{root: Object{"server": Object{"host": String("a"), "tiemout": Int(1), "prot": Int(2)}, "d": Int(1)}}
+
+	t.Run("return the config if all the keys are allowed", func(t *testing.T) {
+		got, err := config.GetConfigStrict("server", []string{"host", "prot", "tiemout", "port"})
+		assertNoError(t, err)
+		assertDeepEqual(t, got, config.GetConfig("server"))
+	})
+
+	t.Run("return an error listing all the unknown keys", func(t *testing.T) {
+		got, err := config.GetConfigStrict("server", []string{"host", "port", "timeout"})
+		assertError(t, err, errors.New(`unknown keys at path: server, ["prot" "tiemout"], the allowed keys are: ["host" "port" "timeout"]`))
+		assertNil(t, got)
+
+		if !errors.Is(err, ErrUnknownKeys) {
+			t.Fatalf("expected the error to wrap ErrUnknownKeys, got: %v", err)
+		}
+	})
+
+	t.Run("return an error if the value is not found or not an object", func(t *testing.T) {
+		_, err := config.GetConfigStrict("missing", nil)
+		assertError(t, err, valueNotFoundError("missing"))
+
+		_, err = config.GetConfigStrict("d", nil)
+		assertError(t, err, errors.New("value: 1 at path: d is not an object"))
+	})
+}
+
+func TestForEach(t *testing.T) {
+	config := &Config{root: Object{
+		"services": Object{"b": Object{"port": Int(2)}, "a": Object{"port": Int(1)}, "c": Int(3)},
+		"d":        Int(4),
+	}}
+
+	t.Run("iterate the child objects in sorted key order skipping the other values", func(t *testing.T) {
+		var keys []string
+		var ports []int
+		config.ForEach("services", func(key string, sub *Config) {
+			keys = append(keys, key)
+			ports = append(ports, sub.GetInt("port"))
+		})
+		assertDeepEqual(t, keys, []string{"a", "b"})
+		assertDeepEqual(t, ports, []int{1, 2})
+	})
+
+	t.Run("do nothing if the value is not found or is not an object", func(t *testing.T) {
+		called := false
+		config.ForEach("e", func(string, *Config) { called = true })
+		config.ForEach("d", func(string, *Config) { called = true })
+		assertEquals(t, called, false)
+	})
+}
+
+func TestPrefixed(t *testing.T) {
+	config := &Config{root: Object{"enabled": Boolean(true)}}
+
+	t.Run("nest the config under the dotted prefix", func(t *testing.T) {
+		got := config.Prefixed("plugins.auth")
+		assertDeepEqual(t, got.root, Object{"plugins": Object{"auth": Object{"enabled": Boolean(true)}}})
+		assertEquals(t, got.GetBoolean("plugins.auth.enabled"), true)
+		assertDeepEqual(t, got.GetConfig("plugins.auth"), config)
+	})
+
+	t.Run("not modify the config", func(t *testing.T) {
+		config.Prefixed("a").GetObject("a")["enabled"] = Boolean(false)
+		assertEquals(t, config.GetBoolean("enabled"), true)
+	})
+
+	t.Run("return a copy of the config for the empty prefix", func(t *testing.T) {
+		assertDeepEqual(t, config.Prefixed(""), config)
+	})
+}
+
 func TestGetStringMap(t *testing.T) {
 	object := Object{"b": Int(1)}
-	config := &Config{Object{"a": object}}
+	config := &Config{root: Object{"a": object}}
 	got := config.GetObject("a")
 	assertDeepEqual(t, got, object)
 }
 
 func TestGetStringMapString(t *testing.T) {
-	config := &Config{Object{"a": Object{"b": String("c"), "e": Int(1)}, "d": Array{}}}
+	config := &Config{root: Object{"a": Object{"b": String("c"), "e": Int(1)}, "d": Array{}}}
 
 	t.Run("get object as map[string]string", func(t *testing.T) {
 		got := config.GetStringMapString("a")
@@ -76,8 +171,93 @@ func TestGetStringMapString(t *testing.T) {
 	})
 }
 
+func TestGetIntMap(t *testing.T) {
+	config := &Config{root: Object{
+		"weights": Object{"a": Int(1), "b": String("2"), "c": Object{"d": Int(3)}, "e": Array{Int(4)}, "f": null},
+		"invalid": Object{"a": String("x")},
+	}}
+
+	t.Run("get the object as map[string]int skipping the non-scalar values", func(t *testing.T) {
+		assertDeepEqual(t, config.GetIntMap("weights"), map[string]int{"a": 1, "b": 2})
+	})
+
+	t.Run("return nil for a non-existing int map", func(t *testing.T) {
+		assertNil(t, config.GetIntMap("missing"))
+	})
+
+	t.Run("panic if a value cannot be converted to int", func(t *testing.T) {
+		assertPanic(t, func() { config.GetIntMap("invalid") }, "cannot parse value: x to int!")
+	})
+}
+
+func TestGetStringSet(t *testing.T) {
+	config := &Config{root: Object{
+		"origins": Array{String("https://a.com"), String("https://b.com"), String("https://a.com")},
+		"tag":     String("web"),
+		"empty":   Array{},
+	}}
+
+	t.Run("return the elements as a set merging the duplicates", func(t *testing.T) {
+		assertDeepEqual(t, config.GetStringSet("origins"), map[string]struct{}{"https://a.com": {}, "https://b.com": {}})
+		assertDeepEqual(t, config.GetStringSet("empty"), map[string]struct{}{})
+	})
+
+	t.Run("return a single value as a one-element set", func(t *testing.T) {
+		assertDeepEqual(t, config.GetStringSet("tag"), map[string]struct{}{"web": {}})
+	})
+
+	t.Run("return an empty set for a non-existing value", func(t *testing.T) {
+		assertDeepEqual(t, config.GetStringSet("missing"), map[string]struct{}{})
+	})
+
+	t.Run("return an error naming the index of the duplicate", func(t *testing.T) {
+		_, err := config.GetStringSetE("origins")
+		assertError(t, err, errors.New(`duplicate value: "https://a.com" at index: 2 of the array at path: origins`))
+
+		set, err := config.GetStringSetE("tag")
+		assertNoError(t, err)
+		assertDeepEqual(t, set, map[string]struct{}{"web": {}})
+	})
+}
+
+func TestGetStringSetNonScalar(t *testing.T) {
+	config := &Config{root: Object{
+		"objects": Array{String("a"), Object{"b": Int(1)}},
+		"arrays":  Array{Array{String("a")}},
+		"nulls":   Array{String("a"), null},
+		"object":  Object{"b": Int(1)},
+		"null":    null,
+	}}
+
+	t.Run("panic if the value or an element is an object, an array or null", func(t *testing.T) {
+		assertPanic(t, func() { config.GetStringSet("objects") }, "cannot parse value: {b:1} to string!")
+		assertPanic(t, func() { config.GetStringSet("arrays") }, "cannot parse value: [a] to string!")
+		assertPanic(t, func() { config.GetStringSet("nulls") }, "cannot parse value: null to string!")
+		assertPanic(t, func() { config.GetStringSet("object") }, "cannot parse value: {b:1} to string!")
+		assertPanic(t, func() { config.GetStringSet("null") }, "cannot parse value: null to string!")
+	})
+
+	t.Run("return an error if the value or an element is an object, an array or null", func(t *testing.T) {
+		_, err := config.GetStringSetE("objects")
+		assertError(t, err, errors.New("could not parse the value: {b:1} at index: 1 of the array at path: objects to string"))
+
+		_, err = config.GetStringSetE("arrays")
+		assertError(t, err, errors.New("could not parse the value: [a] at index: 0 of the array at path: arrays to string"))
+
+		_, err = config.GetStringSetE("nulls")
+		assertError(t, err, errors.New("could not parse the value: null at index: 1 of the array at path: nulls to string"))
+
+		_, err = config.GetStringSetE("object")
+		assertError(t, err, errors.New("could not parse the value: {b:1} at path: object to string"))
+
+		set, err := config.GetStringSetE("null")
+		assertError(t, err, errors.New("could not parse the value: null at path: null to string"))
+		assertNil(t, set)
+	})
+}
+
 func TestGetArray(t *testing.T) {
-	config := &Config{Object{"a": Array{Int(1), Int(2)}, "b": Object{"c": String("d")}}}
+	config := &Config{root: Object{"a": Array{Int(1), Int(2)}, "b": Object{"c": String("d")}}}
 
 	t.Run("get array", func(t *testing.T) {
 		got := config.GetArray("a")
@@ -96,8 +276,72 @@ func TestGetArray(t *testing.T) {
 	})
 }
 
+func TestGetValueList(t *testing.T) {
+	config := &Config{root: Object{"a": Array{Int(1), Object{"b": String("c")}, Array{Boolean(true)}, null}, "d": Int(1)}}
+
+	t.Run("get the elements of a heterogeneous array", func(t *testing.T) {
+		got := config.GetValueList("a")
+		assertDeepEqual(t, got, []Value{Int(1), Object{"b": String("c")}, Array{Boolean(true)}, null})
+		assertEquals(t, got[1].Type(), ObjectType)
+	})
+
+	t.Run("return a copy of the elements", func(t *testing.T) {
+		config.GetValueList("a")[0] = Int(5)
+		assertEquals(t, config.GetArray("a")[0], Value(Int(1)))
+	})
+
+	t.Run("return nil for a non-existing array", func(t *testing.T) {
+		if got := config.GetValueList("e"); got != nil {
+			t.Errorf("expected: nil, got: %v", got)
+		}
+	})
+
+	t.Run("panic if the value is not an array", func(t *testing.T) {
+		assertPanic(t, func() { config.GetValueList("d") })
+	})
+}
+
+func TestListGettersWithDefault(t *testing.T) {
+	config, err := ParseString(`ints: [1, 2], strings: [a, b], times: ["2024-01-01T00:00:00Z"], empty: []`)
+	assertNoError(t, err)
+
+	t.Run("return the list if the value is found", func(t *testing.T) {
+		assertDeepEqual(t, config.GetArrayWithDefault("ints", Array{Int(0)}), Array{Int(1), Int(2)})
+		assertDeepEqual(t, config.GetValueListWithDefault("ints", nil), []Value{Int(1), Int(2)})
+		assertDeepEqual(t, config.GetIntSliceWithDefault("ints", []int{0}), []int{1, 2})
+		assertDeepEqual(t, config.GetStringSliceWithDefault("strings", []string{"z"}), []string{"a", "b"})
+
+		times, err := config.GetTimeListWithDefault("times", "", nil)
+		assertNoError(t, err)
+		assertDeepEqual(t, times, []time.Time{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)})
+	})
+
+	t.Run("return the default value if the value is not found", func(t *testing.T) {
+		assertDeepEqual(t, config.GetArrayWithDefault("missing", Array{Int(0)}), Array{Int(0)})
+		assertDeepEqual(t, config.GetValueListWithDefault("missing", []Value{Int(0)}), []Value{Int(0)})
+		assertDeepEqual(t, config.GetIntSliceWithDefault("missing", []int{0}), []int{0})
+		assertDeepEqual(t, config.GetStringSliceWithDefault("missing", []string{"z"}), []string{"z"})
+
+		def := []time.Time{time.Unix(0, 0)}
+		times, err := config.GetTimeListWithDefault("missing", "", def)
+		assertNoError(t, err)
+		assertDeepEqual(t, times, def)
+	})
+
+	t.Run("return an empty list instead of the default value for an explicitly empty array", func(t *testing.T) {
+		assertDeepEqual(t, config.GetArrayWithDefault("empty", Array{Int(0)}), Array{})
+		assertDeepEqual(t, config.GetValueListWithDefault("empty", []Value{Int(0)}), []Value{})
+		assertDeepEqual(t, config.GetIntSliceWithDefault("empty", []int{0}), []int{})
+		assertDeepEqual(t, config.GetStringSliceWithDefault("empty", []string{"z"}), []string{})
+
+		times, err := config.GetTimeListWithDefault("empty", "", []time.Time{time.Unix(0, 0)})
+		assertNoError(t, err)
+		assertDeepEqual(t, times, []time.Time{})
+	})
+}
+
 func TestGetIntSlice(t *testing.T) {
-	config := &Config{Object{"a": Array{Int(1), Int(2)}, "b": Array{String("c"), Int(1)}}}
+	config := &Config{root: Object{"a": Array{Int(1), Int(2)}, "b": Array{String("c"), Int(1)}}}
 
 	t.Run("get array as int slice", func(t *testing.T) {
 		got := config.GetIntSlice("a")
@@ -117,7 +361,7 @@ func TestGetIntSlice(t *testing.T) {
 }
 
 func TestGetStringSlice(t *testing.T) {
-	config := &Config{Object{"a": Array{String("a"), String("b")}, "b": Array{Int(1), String("c")}}}
+	config := &Config{root: Object{"a": Array{String("a"), String("b")}, "b": Array{Int(1), String("c")}}}
 
 	t.Run("get array as string slice", func(t *testing.T) {
 		got := config.GetStringSlice("a")
@@ -138,7 +382,7 @@ func TestGetStringSlice(t *testing.T) {
 }
 
 func TestGetString(t *testing.T) {
-	config := &Config{Object{"a": String("b"), "c": Int(2)}}
+	config := &Config{root: Object{"a": String("b"), "c": Int(2)}}
 
 	t.Run("get string", func(t *testing.T) {
 		assertEquals(t, config.GetString("a"), "b")
@@ -153,8 +397,29 @@ func TestGetString(t *testing.T) {
 	})
 }
 
+func TestGetStringExpanded(t *testing.T) {
+	t.Setenv("HOCON_TEST_HOME", "/home/hocon")
+	config := &Config{root: Object{"a": String("${HOCON_TEST_HOME}/data"), "b": String("$HOCON_TEST_HOME"), "c": String("${HOCON_TEST_UNDEFINED}x")}}
+
+	t.Run("expand the braced environment variable", func(t *testing.T) {
+		assertEquals(t, config.GetStringExpanded("a"), "/home/hocon/data")
+	})
+
+	t.Run("expand the environment variable without braces", func(t *testing.T) {
+		assertEquals(t, config.GetStringExpanded("b"), "/home/hocon")
+	})
+
+	t.Run("expand the undefined environment variable to empty string", func(t *testing.T) {
+		assertEquals(t, config.GetStringExpanded("c"), "x")
+	})
+
+	t.Run("return zero value(empty string) for a non-existing string", func(t *testing.T) {
+		assertEquals(t, config.GetStringExpanded("d"), "")
+	})
+}
+
 func TestGetInt(t *testing.T) {
-	config := &Config{Object{"a": String("aa"), "b": String("3"), "c": Int(2), "d": Array{Int(5)}}}
+	config := &Config{root: Object{"a": String("aa"), "b": String("3"), "c": Int(2), "d": Array{Int(5)}}}
 
 	t.Run("get int", func(t *testing.T) {
 		assertEquals(t, config.GetInt("c"), 2)
@@ -178,7 +443,7 @@ func TestGetInt(t *testing.T) {
 }
 
 func TestGetFloat32(t *testing.T) {
-	config := &Config{Object{"a": String("aa"), "b": String("3.2"), "c": Float32(2.4), "d": Array{Int(5)}, "e": Float64(2.5)}}
+	config := &Config{root: Object{"a": String("aa"), "b": String("3.2"), "c": Float32(2.4), "d": Array{Int(5)}, "e": Float64(2.5)}}
 
 	t.Run("get float32", func(t *testing.T) {
 		assertEquals(t, config.GetFloat32("c"), float32(2.4))
@@ -203,10 +468,16 @@ func TestGetFloat32(t *testing.T) {
 	t.Run("panic if the value is not a float32 or a string", func(t *testing.T) {
 		assertPanic(t, func() { config.GetFloat32("d") })
 	})
+
+	t.Run("panic if the value is a float64 that does not fit in float32", func(t *testing.T) {
+		config := &Config{root: Object{"a": Float64(3.5e40), "b": Float64(-3.5e40)}}
+		assertPanic(t, func() { config.GetFloat32("a") }, "cannot parse value: 3.5e+40 to float32, it is out of range!")
+		assertPanic(t, func() { config.GetFloat32("b") }, "cannot parse value: -3.5e+40 to float32, it is out of range!")
+	})
 }
 
 func TestGetFloat64(t *testing.T) {
-	config := &Config{Object{"a": String("aa"), "b": String("3.2"), "c": Float32(2.4), "d": Array{Int(5)}, "e": Float64(2.5)}}
+	config := &Config{root: Object{"a": String("aa"), "b": String("3.2"), "c": Float32(2.4), "d": Array{Int(5)}, "e": Float64(2.5)}}
 
 	t.Run("get float64", func(t *testing.T) {
 		assertEquals(t, config.GetFloat64("e"), 2.5)
@@ -234,7 +505,7 @@ func TestGetFloat64(t *testing.T) {
 }
 
 func TestGetBoolean(t *testing.T) {
-	config := &Config{Object{
+	config := &Config{root: Object{
 		"a": Boolean(true),
 		"b": Boolean(false),
 		"c": String("true"),
@@ -280,8 +551,36 @@ func TestGetBoolean(t *testing.T) {
 	}
 }
 
+func TestGetBooleanList(t *testing.T) {
+	config := &Config{root: Object{
+		"a": Array{Boolean(true), String("no"), String("on"), String("false")},
+		"b": String("yes"),
+		"c": Array{Boolean(true), Int(1)},
+		"d": Array{},
+	}}
+
+	t.Run("return the elements accepting all the boolean spellings", func(t *testing.T) {
+		assertDeepEqual(t, config.GetBooleanList("a"), []bool{true, false, true, false})
+		assertDeepEqual(t, config.GetBooleanList("d"), []bool{})
+	})
+
+	t.Run("return a single boolean as a one-element slice", func(t *testing.T) {
+		assertDeepEqual(t, config.GetBooleanList("b"), []bool{true})
+	})
+
+	t.Run("return nil for a non-existing list", func(t *testing.T) {
+		assertNil(t, config.GetBooleanList("z"))
+	})
+
+	t.Run("return an error naming the index of the element that is not a boolean", func(t *testing.T) {
+		_, err := config.GetBooleanListE("c")
+		assertError(t, err, errors.New("could not parse the value: 1 at index: 1 of the array at path: c to boolean"))
+		assertPanic(t, func() { config.GetBooleanList("c") }, err.Error())
+	})
+}
+
 func TestGetDuration(t *testing.T) {
-	config := &Config{Object{"a": Duration(5 * time.Second), "b": String("bb")}}
+	config := &Config{root: Object{"a": Duration(5 * time.Second), "b": String("bb")}}
 
 	t.Run("get Duration at the given path", func(t *testing.T) {
 		got := config.GetDuration("a")
@@ -296,141 +595,1174 @@ func TestGetDuration(t *testing.T) {
 	t.Run("panic if the value is not a duration", func(t *testing.T) {
 		assertPanic(t, func() { config.GetDuration("b") })
 	})
+
+	units := map[string]time.Duration{
+		"ns": time.Nanosecond, "nanoseconds": time.Nanosecond, "us": time.Microsecond, "microseconds": time.Microsecond,
+		"ms": time.Millisecond, "milliseconds": time.Millisecond, "s": time.Second, "seconds": time.Second,
+		"m": time.Minute, "minutes": time.Minute, "h": time.Hour, "hours": time.Hour, "d": 24 * time.Hour, "days": 24 * time.Hour,
+	}
+
+	for unit, duration := range units {
+		for _, form := range []string{"30" + unit, "30 " + unit} {
+			t.Run(fmt.Sprintf("get the duration written as the string: %q", form), func(t *testing.T) {
+				config := &Config{root: Object{"a": String(form)}}
+				assertEquals(t, config.GetDuration("a"), 30*duration)
+			})
+		}
+	}
+
+	for unit, duration := range units {
+		if duration == time.Nanosecond {
+			continue
+		}
+
+		for _, form := range []string{"1.5" + unit, "1.5 " + unit} {
+			t.Run(fmt.Sprintf("keep the sub-unit precision of the fractional duration: %q", form), func(t *testing.T) {
+				config := &Config{root: Object{"a": String(form)}}
+				assertEquals(t, config.GetDuration("a"), duration*3/2)
+			})
+		}
+	}
+
+	var parsedTestCases = []struct {
+		input    string
+		expected time.Duration
+	}{
+		{"1.5s", 1500 * time.Millisecond},
+		{"0.5h", 30 * time.Minute},
+		{"0.3 seconds", 300 * time.Millisecond},
+		{"-1.5s", -1500 * time.Millisecond},
+		{"2.5ms", 2500 * time.Microsecond},
+		{"0.25d", 6 * time.Hour},
+	}
+
+	for _, tc := range parsedTestCases {
+		t.Run(fmt.Sprintf("get the parsed fractional duration: %s", tc.input), func(t *testing.T) {
+			config, err := ParseString("a: " + tc.input)
+			assertNoError(t, err)
+			assertEquals(t, config.GetDuration("a"), tc.expected)
+		})
+	}
+
+	t.Run("panic if the duration string has an unknown unit", func(t *testing.T) {
+		config := &Config{root: Object{"a": String("30 parsecs")}}
+		assertPanic(t, func() { config.GetDuration("a") })
+	})
 }
 
-func TestWithFallback(t *testing.T) {
-	config1 := &Config{Object{"a": String("aa"), "b": String("bb")}}
-	config2 := &Config{Object{"a": String("aaa"), "c": String("cc")}}
-	config3 := &Config{Array{Int(1), Int(2)}}
+func TestGetByteSize(t *testing.T) {
+	config := &Config{root: Object{"a": Int(1024), "b": Boolean(true), "c": String("5 lightyears")}}
 
-	t.Run("merge the given fallback config with the current config if the root of both of them are of type Object (for the same keys current config should override the fallback)", func(t *testing.T) {
-		expected := &Config{Object{"a": String("aa"), "b": String("bb"), "c": String("cc")}}
-		got := config1.WithFallback(config2)
-		assertDeepEqual(t, got, expected)
+	t.Run("get the integer as the number of bytes", func(t *testing.T) {
+		assertEquals(t, config.GetByteSize("a"), int64(1024))
 	})
 
-	t.Run("return the current config if the root of the given fallback config is not an Object", func(t *testing.T) {
-		got := config1.WithFallback(config3)
-		assertDeepEqual(t, got, config1)
+	t.Run("return zero for non-existing size", func(t *testing.T) {
+		assertEquals(t, config.GetByteSize("d"), int64(0))
 	})
 
-	t.Run("return the current config if the root of it is not an Object", func(t *testing.T) {
-		got := config3.WithFallback(config1)
-		assertDeepEqual(t, got, config3)
+	t.Run("panic if the value is not a size", func(t *testing.T) {
+		assertPanic(t, func() { config.GetByteSize("b") })
+		assertPanic(t, func() { config.GetByteSize("c") })
+	})
+
+	t.Run("panic for the lowercase m unit that is parsed as minutes", func(t *testing.T) {
+		config, err := ParseString("s = 10m, t = 10M")
+		assertNoError(t, err)
+		assertPanic(t, func() { config.GetByteSize("s") },
+			"cannot parse the duration: 10m0s to byte size, use the M or Mi unit for the mebibytes!")
+		assertEquals(t, config.GetByteSize("t"), int64(10<<20))
+
+		config = &Config{root: Object{"s": String("10m")}}
+		assertPanic(t, func() { config.GetByteSize("s") }, "unknown size unit: m in value: 10m")
 	})
+
+	units := map[string]int64{
+		"B": 1, "bytes": 1, "kB": 1e3, "kilobytes": 1e3, "K": 1 << 10, "KiB": 1 << 10, "kibibytes": 1 << 10,
+		"MB": 1e6, "megabytes": 1e6, "M": 1 << 20, "MiB": 1 << 20, "mebibytes": 1 << 20,
+		"GB": 1e9, "gigabytes": 1e9, "G": 1 << 30, "GiB": 1 << 30, "gibibytes": 1 << 30,
+		"TB": 1e12, "terabytes": 1e12, "T": 1 << 40, "TiB": 1 << 40, "tebibytes": 1 << 40,
+	}
+
+	for unit, size := range units {
+		for _, form := range []string{"2" + unit, "2 " + unit} {
+			t.Run(fmt.Sprintf("get the size written as the string: %q", form), func(t *testing.T) {
+				config := &Config{root: Object{"a": String(form)}}
+				assertEquals(t, config.GetByteSize("a"), 2*size)
+			})
+		}
+
+		if size == 1 {
+			continue
+		}
+
+		for _, form := range []string{"1.5" + unit, "1.5 " + unit} {
+			t.Run(fmt.Sprintf("get the fractional size written as the string: %q", form), func(t *testing.T) {
+				config := &Config{root: Object{"a": String(form)}}
+				assertEquals(t, config.GetByteSize("a"), size*3/2)
+			})
+		}
+	}
 }
 
-func TestFind(t *testing.T) {
-	t.Run("return nil if path does not contain any dot and there is no value with the given path", func(t *testing.T) {
-		object := Object{"a": Int(1)}
-		got := object.find("b")
-		assertNil(t, got)
+func TestGetInRange(t *testing.T) {
+	config, err := ParseString(`{port: 8080, badPort: 70000, ratio: 0.5, timeout: 30s}`)
+	assertNoError(t, err)
+
+	t.Run("return the values in the range", func(t *testing.T) {
+		port, err := config.GetIntInRange("port", 1, 65535)
+		assertNoError(t, err)
+		assertEquals(t, port, 8080)
+
+		ratio, err := config.GetFloat64InRange("ratio", 0, 1)
+		assertNoError(t, err)
+		assertEquals(t, ratio, 0.5)
+
+		timeout, err := config.GetDurationInRange("timeout", time.Second, 30*time.Second)
+		assertNoError(t, err)
+		assertEquals(t, timeout, 30*time.Second)
 	})
 
-	t.Run("find the value with the path that does not contain any dot", func(t *testing.T) {
-		object := Object{"a": Int(1)}
-		got := object.find("a")
-		assertEquals(t, got, Int(1))
+	t.Run("return ErrOutOfRange naming the path and the range", func(t *testing.T) {
+		port, err := config.GetIntInRange("badPort", 1, 65535)
+		assertEquals(t, errors.Is(err, ErrOutOfRange), true)
+		assertError(t, err, errors.New("value out of range at path: badPort, 70000 is not in the range [1, 65535]"))
+		assertEquals(t, port, 0)
+
+		_, err = config.GetFloat64InRange("ratio", 0.6, 1)
+		assertError(t, err, errors.New("value out of range at path: ratio, 0.5 is not in the range [0.6, 1]"))
+
+		_, err = config.GetDurationInRange("timeout", time.Second, 10*time.Second)
+		assertError(t, err, errors.New("value out of range at path: timeout, 30s is not in the range [1s, 10s]"))
 	})
 
-	t.Run("return nil if path contains dot and there is no value with the sub-path", func(t *testing.T) {
-		object := Object{"a": Object{"b": Int(1)}}
-		got := object.find("c.b")
-		assertNil(t, got)
+	t.Run("return ErrOutOfRange for NaN and the infinities", func(t *testing.T) {
+		config := &Config{root: Object{"nan": Float64(math.NaN()), "inf": Float64(math.Inf(1))}}
+
+		_, err := config.GetFloat64InRange("nan", 0, 1)
+		assertError(t, err, errors.New("value out of range at path: nan, NaN is not in the range [0, 1]"))
+
+		_, err = config.GetFloat64InRange("inf", 0, math.Inf(1))
+		assertError(t, err, errors.New("value out of range at path: inf, +Inf is not in the range [0, +Inf]"))
 	})
 
-	t.Run("find the value with the path that contains dots", func(t *testing.T) {
-		object := Object{"a": Object{"b": Int(1)}}
-		got := object.find("a.b")
-		assertEquals(t, got, Int(1))
+	t.Run("return ErrValueNotFound if the value is not found", func(t *testing.T) {
+		_, err := config.GetIntInRange("missing", 1, 2)
+		assertEquals(t, errors.Is(err, ErrValueNotFound), true)
+
+		_, err = config.GetFloat64InRange("missing", 1, 2)
+		assertEquals(t, errors.Is(err, ErrValueNotFound), true)
+
+		_, err = config.GetDurationInRange("missing", 1, 2)
+		assertEquals(t, errors.Is(err, ErrValueNotFound), true)
 	})
 }
 
-func TestObject_String(t *testing.T) {
-	t.Run("return the string of an empty object", func(t *testing.T) {
-		got := Object{}.String()
-		assertEquals(t, got, "{}")
+func TestGetWithDefault(t *testing.T) {
+	config, err := ParseString("timeout: 1.5s, size: 1.5KiB")
+	assertNoError(t, err)
+
+	t.Run("get the duration or the default value", func(t *testing.T) {
+		assertEquals(t, config.GetDurationWithDefault("timeout", time.Minute), 1500*time.Millisecond)
+		assertEquals(t, config.GetDurationWithDefault("missing", time.Minute), time.Minute)
 	})
 
-	t.Run("return the string of an object that contains a single element", func(t *testing.T) {
-		got := Object{"a": Int(1)}.String()
-		assertEquals(t, got, "{a:1}")
+	t.Run("get the byte size or the default value", func(t *testing.T) {
+		assertEquals(t, config.GetByteSizeWithDefault("size", 10), int64(1536))
+		assertEquals(t, config.GetByteSizeWithDefault("missing", 10), int64(10))
 	})
+}
 
-	t.Run("return the string of an object that contains multiple elements", func(t *testing.T) {
-		got := Object{"a": Int(1), "b": Int(2)}.String()
-		if got != "{a:1, b:2}" && got != "{b:2, a:1}" {
-			fail(t, got, "{a:1, b:2}")
-		}
+func TestGetBytes(t *testing.T) {
+	config := &Config{root: Object{"a": String("aGVsbG8="), "b": String("68656c6c6f"), "c": String("!!")}}
+
+	t.Run("decode the base64 value by default", func(t *testing.T) {
+		got, err := config.GetBytes("a")
+		assertNoError(t, err)
+		assertDeepEqual(t, got, []byte("hello"))
 	})
 
-	t.Run("return the string of an object that contains a string element with the ':' character", func(t *testing.T) {
-		got := Object{"a": String("0.0.0.0:80")}.String()
-		assertEquals(t, got, "{a:\"0.0.0.0:80\"}")
+	t.Run("decode the hex value if the hex encoding is given", func(t *testing.T) {
+		got, err := config.GetBytes("b", HexEncoding)
+		assertNoError(t, err)
+		assertDeepEqual(t, got, []byte("hello"))
 	})
 
-	t.Run("return the string of an object that contains multiple elements with the ':' character", func(t *testing.T) {
-		got := Object{"a": String("0.0.0.0:80"), "b": Int(2)}.String()
-		if got != "{a:\"0.0.0.0:80\", b:2}" && got != "{b:2, a:\"0.0.0.0:80\"}" {
-			fail(t, got, "{a:1, b:2}")
-		}
+	t.Run("return nil for a non-existing value", func(t *testing.T) {
+		got, err := config.GetBytes("z")
+		assertNoError(t, err)
+		assertNil(t, got)
+	})
+
+	t.Run("return an error naming the path if the value cannot be decoded", func(t *testing.T) {
+		got, err := config.GetBytes("c", HexEncoding)
+		assertError(t, err, errors.New("could not decode the value at path: c, encoding/hex: invalid byte: U+0021 '!'"))
+		assertNil(t, got)
 	})
 
+	t.Run("return an error naming the path if the value is not a string", func(t *testing.T) {
+		config := &Config{root: Object{"object": Object{"a": Int(1)}, "array": Array{String("aGVsbG8=")}, "number": Int(1234)}}
+
+		for _, path := range []string{"object", "array", "number"} {
+			got, err := config.GetBytes(path, HexEncoding)
+			assertError(t, err, fmt.Errorf("could not decode the value: %s at path: %s, the value is not a string", config.Get(path), path))
+			assertNil(t, got)
+		}
+	})
 }
 
-func TestArray_String(t *testing.T) {
-	t.Run("return the string of an empty array", func(t *testing.T) {
-		got := Array{}.String()
-		assertEquals(t, got, "[]")
+func TestGetEnum(t *testing.T) {
+	type level string
+
+	config := &Config{root: Object{"a": String("warn"), "b": String("verbose")}}
+	valid := []level{"debug", "info", "warn"}
+
+	t.Run("get the enum value", func(t *testing.T) {
+		got, err := GetEnum(config, "a", valid, "info")
+		assertNoError(t, err)
+		assertEquals(t, got, level("warn"))
 	})
 
-	t.Run("return the string of an array that contains a single element", func(t *testing.T) {
-		got := Array{Int(1)}.String()
-		assertEquals(t, got, "[1]")
+	t.Run("return the default value if the value is not found", func(t *testing.T) {
+		got, err := GetEnum(config, "c", valid, "info")
+		assertNoError(t, err)
+		assertEquals(t, got, level("info"))
 	})
 
-	t.Run("return the string of an array that contains multiple elements", func(t *testing.T) {
-		got := Array{Int(1), Int(2)}.String()
-		assertEquals(t, got, "[1,2]")
+	t.Run("return an error with the allowed values if the value is not valid", func(t *testing.T) {
+		_, err := GetEnum(config, "b", valid, "info")
+		assertError(t, err, errors.New(`invalid value: "verbose" at path: b, allowed values: ["debug" "info" "warn"]`))
 	})
 
-	t.Run("return the string of an array that contains a single elements with the ':' character", func(t *testing.T) {
-		got := Array{String("0.0.0.0:80")}.String()
-		assertEquals(t, got, "[\"0.0.0.0:80\"]")
+	t.Run("return an error if the value is not a string", func(t *testing.T) {
+		config := &Config{root: Object{"object": Object{"a": Int(1)}, "array": Array{String("warn")}}}
+
+		for _, path := range []string{"object", "array"} {
+			got, err := GetEnum(config, path, valid, "info")
+			assertError(t, err, fmt.Errorf(`invalid value: %s at path: %s, the value is not a string, allowed values: ["debug" "info" "warn"]`,
+				config.Get(path), path))
+			assertEquals(t, got, level("info"))
+		}
 	})
 
-	t.Run("return the string of an array that contains multiple elements with the ':' character", func(t *testing.T) {
-		got := Array{String("0.0.0.0:80"), String("localhost:443")}.String()
-		assertEquals(t, got, "[\"0.0.0.0:80\",\"localhost:443\"]")
+	t.Run("match the content of the string without trimming the quotes in it", func(t *testing.T) {
+		config := &Config{root: Object{"a": String(`"warn"`)}}
+
+		_, err := GetEnum(config, "a", valid, "info")
+		assertError(t, err, errors.New(`invalid value: "\"warn\"" at path: a, allowed values: ["debug" "info" "warn"]`))
 	})
 }
 
-func TestGet(t *testing.T) {
-	t.Run("return nil if the root of config is not an Object", func(t *testing.T) {
-		config := &Config{Array{Int(1)}}
-		got := config.Get("a")
-		assertNil(t, got)
+func TestGetIntByName(t *testing.T) {
+	config, err := ParseString(`priorities { low = 1, "very.high" = 9, text = x }, task.priority = ${priorities.low}`)
+	assertNoError(t, err)
+
+	t.Run("return the integer of the name", func(t *testing.T) {
+		got, err := config.GetIntByName("priorities", "low")
+		assertNoError(t, err)
+		assertEquals(t, got, 1)
+
+		got, err = config.GetIntByName("priorities", "very.high")
+		assertNoError(t, err)
+		assertEquals(t, got, 9)
 	})
 
-	t.Run("find the value if the root of config is an object and a value exist with the given path", func(t *testing.T) {
-		config := &Config{Object{"a": Int(1)}}
-		got := config.Get("a")
-		assertEquals(t, got, Int(1))
+	t.Run("return an error listing the available names if the name is not found", func(t *testing.T) {
+		_, err := config.GetIntByName("priorities", "medium")
+		assertError(t, err, errors.New(`could not find the name: "medium" at path: priorities, available names: ["low" "text" "very.high"]`))
 	})
 
-	t.Run("return nil if the root of config is an object but value with the given path does not exist", func(t *testing.T) {
-		config := &Config{Object{"a": Int(1)}}
-		got := config.Get("b")
-		assertNil(t, got)
+	t.Run("return an error if the base object is not found or the value is not an integer", func(t *testing.T) {
+		_, err := config.GetIntByName("missing", "low")
+		assertError(t, err, valueNotFoundError("missing"))
+
+		_, err = config.GetIntByName("task.priority", "low")
+		assertError(t, err, errors.New("value: 1 at path: task.priority is not an object"))
+
+		_, err = config.GetIntByName("priorities", "text")
+		assertError(t, err, errors.New(`value: x of the name: "text" at path: priorities is not an integer`))
 	})
 }
 
-func TestNewBooleanFromString(t *testing.T) {
-	var testCases = []struct {
-		input    string
-		expected Boolean
-	}{
-		{"true", Boolean(true)},
+func TestGetLogLevel(t *testing.T) {
+	config, err := ParseString(`{debug: DEBUG, warning: Warning, error: error, bad: verbose}`)
+	assertNoError(t, err)
+
+	t.Run("map the level names case-insensitively", func(t *testing.T) {
+		for path, expected := range map[string]slog.Level{"debug": slog.LevelDebug, "warning": slog.LevelWarn, "error": slog.LevelError} {
+			got, err := config.GetLogLevel(path)
+			assertNoError(t, err)
+			assertEquals(t, got, expected)
+		}
+	})
+
+	t.Run("return an error listing the valid names if the level is not recognized", func(t *testing.T) {
+		_, err := config.GetLogLevel("bad")
+		assertError(t, err, errors.New(`invalid log level: "verbose" at path: bad, allowed values: ["debug" "error" "info" "warn" "warning"]`))
+	})
+
+	t.Run("return ErrValueNotFound if the value is not found", func(t *testing.T) {
+		_, err := config.GetLogLevel("missing")
+		assertEquals(t, errors.Is(err, ErrValueNotFound), true)
+	})
+
+	t.Run("return the default level if the value is not found", func(t *testing.T) {
+		got, err := config.GetLogLevelWithDefault("missing", slog.LevelWarn)
+		assertNoError(t, err)
+		assertEquals(t, got, slog.LevelWarn)
+
+		got, err = config.GetLogLevelWithDefault("debug", slog.LevelWarn)
+		assertNoError(t, err)
+		assertEquals(t, got, slog.LevelDebug)
+
+		_, err = config.GetLogLevelWithDefault("bad", slog.LevelWarn)
+		assertError(t, err, errors.New(`invalid log level: "verbose" at path: bad, allowed values: ["debug" "error" "info" "warn" "warning"]`))
+	})
+}
+
+func TestGetTime(t *testing.T) {
+	config, err := ParseString(`{start: "2024-01-01T10:30:00Z", day: "2024-01-02", bad: "noon", times: ["2024-01-01T00:00:00Z", "2024-06-01T00:00:00Z"], badTimes: ["2024-01-01T00:00:00Z", "x"]}`)
+	assertNoError(t, err)
+
+	t.Run("parse the value with RFC3339 if the layout is empty", func(t *testing.T) {
+		got, err := config.GetTime("start", "")
+		assertNoError(t, err)
+		assertEquals(t, got, time.Date(2024, 1, 1, 10, 30, 0, 0, time.UTC))
+	})
+
+	t.Run("parse the value with the given layout", func(t *testing.T) {
+		got, err := config.GetTime("day", "2006-01-02")
+		assertNoError(t, err)
+		assertEquals(t, got, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC))
+	})
+
+	t.Run("return the zero time and ErrValueNotFound if the value is not found", func(t *testing.T) {
+		got, err := config.GetTime("missing", "")
+		assertEquals(t, errors.Is(err, ErrValueNotFound), true)
+		assertError(t, err, errors.New("value not found at path: missing"))
+		assertEquals(t, got, time.Time{})
+	})
+
+	t.Run("return an error naming the path if the value cannot be parsed", func(t *testing.T) {
+		_, err := config.GetTime("bad", "")
+		assertError(t, err, errors.New(`could not parse the time at path: bad, parsing time "noon" as "2006-01-02T15:04:05Z07:00": cannot parse "noon" as "2006"`))
+	})
+
+	t.Run("return an error naming the path if the value is not a string", func(t *testing.T) {
+		config := &Config{root: Object{"year": Int(2024), "object": Object{"a": String("2024")}, "years": Array{String("2024"), Int(2025)}}}
+
+		_, err := config.GetTime("year", "2006")
+		assertError(t, err, errors.New("could not parse the time: 2024 at path: year, the value is not a string"))
+
+		_, err = config.GetTime("object", "2006")
+		assertError(t, err, errors.New(`could not parse the time: {a:2024} at path: object, the value is not a string`))
+
+		_, err = config.GetTimeList("years", "2006")
+		assertError(t, err, errors.New("could not parse the time: 2025 at path: years.1, the value is not a string"))
+	})
+
+	t.Run("parse the elements of the array", func(t *testing.T) {
+		got, err := config.GetTimeList("times", "")
+		assertNoError(t, err)
+		assertDeepEqual(t, got, []time.Time{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)})
+	})
+
+	t.Run("return an error naming the index of the element that cannot be parsed", func(t *testing.T) {
+		got, err := config.GetTimeList("badTimes", "")
+		assertError(t, err, errors.New(`could not parse the time at path: badTimes.1, parsing time "x" as "2006-01-02T15:04:05Z07:00": cannot parse "x" as "2006"`))
+		assertNil(t, got)
+	})
+
+	t.Run("return an error if the value is not an array", func(t *testing.T) {
+		_, err := config.GetTimeList("start", "")
+		assertError(t, err, errors.New("value at path: start is not an array"))
+	})
+
+	t.Run("return ErrValueNotFound if the array is not found", func(t *testing.T) {
+		_, err := config.GetTimeList("missing", "")
+		assertEquals(t, errors.Is(err, ErrValueNotFound), true)
+	})
+}
+
+func TestGetRegexp(t *testing.T) {
+	config, err := ParseString(`{route: "^/users/(\\d+)$", host: "a:b", bad: "(", routes: ["^/a$", "^/b$"], badRoutes: ["^/a$", "[x"]}`)
+	assertNoError(t, err)
+
+	t.Run("compile the string value", func(t *testing.T) {
+		got, err := config.GetRegexp("route")
+		assertNoError(t, err)
+		assertEquals(t, got.String(), `^/users/(\d+)$`)
+		assertEquals(t, got.MatchString("/users/42"), true)
+
+		got, err = config.GetRegexp("host")
+		assertNoError(t, err)
+		assertEquals(t, got.String(), "a:b")
+	})
+
+	t.Run("return ErrValueNotFound if the value is not found", func(t *testing.T) {
+		got, err := config.GetRegexp("missing")
+		assertEquals(t, errors.Is(err, ErrValueNotFound), true)
+		assertNil(t, got)
+	})
+
+	t.Run("return an error naming the path if the pattern is invalid", func(t *testing.T) {
+		got, err := config.GetRegexp("bad")
+		assertError(t, err, errors.New("could not compile the regexp at path: bad, error parsing regexp: missing closing ): `(`"))
+		assertNil(t, got)
+	})
+
+	t.Run("compile the elements of the array", func(t *testing.T) {
+		got, err := config.GetRegexpList("routes")
+		assertNoError(t, err)
+		assertEquals(t, len(got), 2)
+		assertEquals(t, got[1].String(), "^/b$")
+	})
+
+	t.Run("return an error naming the index of the invalid pattern", func(t *testing.T) {
+		got, err := config.GetRegexpList("badRoutes")
+		assertError(t, err, errors.New("could not compile the regexp at path: badRoutes.1, error parsing regexp: missing closing ]: `[x`"))
+		assertNil(t, got)
+	})
+
+	t.Run("return an error if the value is not an array", func(t *testing.T) {
+		_, err := config.GetRegexpList("route")
+		assertError(t, err, errors.New("value at path: route is not an array"))
+	})
+}
+
+func TestGetPercent(t *testing.T) {
+	config, err := ParseString(`{percent: "80%", spaced: " 12.5 % ", fraction: 0.25, whole: 80, one: 1, over: "150%", negative: -0.1, text: high}`)
+	assertNoError(t, err)
+
+	var testCases = []struct {
+		path     string
+		mode     []PercentMode
+		expected float64
+	}{
+		{"percent", nil, 0.8},
+		{"spaced", nil, 0.125},
+		{"fraction", nil, 0.25},
+		{"one", nil, 1},
+		{"whole", []PercentMode{BarePercentage}, 0.8},
+		{"percent", []PercentMode{PercentSignRequired}, 0.8},
+	}
+
+	for _, tc := range testCases {
+		t.Run("read the percentage at "+tc.path, func(t *testing.T) {
+			got, err := config.GetPercent(tc.path, tc.mode...)
+			assertNoError(t, err)
+			assertEquals(t, got, tc.expected)
+		})
+	}
+
+	t.Run("return an error if the fraction is not in the range [0, 1]", func(t *testing.T) {
+		_, err := config.GetPercent("whole")
+		assertError(t, err, errors.New("value out of range at path: whole, 80 is not in the range [0, 1]"))
+
+		_, err = config.GetPercent("over")
+		assertError(t, err, errors.New("value out of range at path: over, 1.5 is not in the range [0, 1]"))
+
+		_, err = config.GetPercent("negative", BarePercentage)
+		if !errors.Is(err, ErrOutOfRange) {
+			t.Fatalf("expected an out of range error, got: %v", err)
+		}
+	})
+
+	t.Run("return an error wrapping ErrOutOfRange for NaN and the infinities", func(t *testing.T) {
+		config := &Config{root: Object{"nan": String("NaN"), "inf": String("+Inf%"), "negativeInf": Float64(math.Inf(-1))}}
+
+		_, err := config.GetPercent("nan")
+		assertError(t, err, errors.New("value out of range at path: nan, NaN is not in the range [0, 1]"))
+
+		_, err = config.GetPercent("inf")
+		assertError(t, err, errors.New("value out of range at path: inf, +Inf is not in the range [0, 1]"))
+
+		_, err = config.GetPercent("negativeInf")
+		assertEquals(t, errors.Is(err, ErrOutOfRange), true)
+	})
+
+	t.Run("return an error naming the path if the value is not a percentage", func(t *testing.T) {
+		_, err := config.GetPercent("text")
+		assertError(t, err, errors.New(`could not parse the percentage: "high" at path: text`))
+
+		_, err = config.GetPercent("fraction", PercentSignRequired)
+		assertError(t, err, errors.New(`could not parse the percentage: "0.25" at path: fraction, the percent sign is required`))
+
+		_, err = config.GetPercent("missing")
+		assertError(t, err, valueNotFoundError("missing"))
+	})
+}
+
+func TestGetURL(t *testing.T) {
+	config, err := ParseString(`{endpoint: "https://example.com:8443/api?v=1", relative: "/api", bad: "http://[::1", endpoints: ["http://a", "http://b"], badEndpoints: ["http://a", "b"]}`)
+	assertNoError(t, err)
+
+	t.Run("parse the string value", func(t *testing.T) {
+		got, err := config.GetURL("endpoint", AbsoluteURL)
+		assertNoError(t, err)
+		assertEquals(t, got.Host, "example.com:8443")
+		assertEquals(t, got.Path, "/api")
+
+		got, err = config.GetURL("relative")
+		assertNoError(t, err)
+		assertEquals(t, got.String(), "/api")
+	})
+
+	t.Run("return an error naming the path and the value if the URL is invalid", func(t *testing.T) {
+		got, err := config.GetURL("bad")
+		assertError(t, err, errors.New(`could not parse the URL: "http://[::1" at path: bad, parse "http://[::1": missing ']' in host`))
+		assertNil(t, got)
+	})
+
+	t.Run("return an error if the URL is not absolute but required to be", func(t *testing.T) {
+		_, err := config.GetURL("relative", AbsoluteURL)
+		assertError(t, err, errors.New(`could not parse the URL: "/api" at path: relative, the URL is not absolute`))
+	})
+
+	t.Run("return ErrValueNotFound if the value is not found", func(t *testing.T) {
+		_, err := config.GetURL("missing")
+		assertEquals(t, errors.Is(err, ErrValueNotFound), true)
+
+		_, err = config.GetURLList("missing")
+		assertEquals(t, errors.Is(err, ErrValueNotFound), true)
+	})
+
+	t.Run("parse the elements of the array", func(t *testing.T) {
+		got, err := config.GetURLList("endpoints", AbsoluteURL)
+		assertNoError(t, err)
+		assertEquals(t, len(got), 2)
+		assertEquals(t, got[1].Host, "b")
+
+		_, err = config.GetURLList("badEndpoints", AbsoluteURL)
+		assertError(t, err, errors.New(`could not parse the URL: "b" at path: badEndpoints.1, the URL is not absolute`))
+
+		_, err = config.GetURLList("endpoint")
+		assertError(t, err, errors.New("value at path: endpoint is not an array"))
+	})
+}
+
+func TestIsQuoted(t *testing.T) {
+	config, err := ParseString(`
+		quoted: "a"
+		unquoted: a
+		concatenated: "a" b
+		multiLine: """a"""
+		number: 1
+		nested.key: "a"
+		array: ["a", b]
+		include "testdata/nested/y.conf"`)
+	assertNoError(t, err)
+
+	var testCases = []struct {
+		path     string
+		expected bool
+	}{
+		{"quoted", true},
+		{"unquoted", false},
+		{"concatenated", false},
+		{"multiLine", true},
+		{"number", false},
+		{"nested.key", true},
+		{"array.0", true},
+		{"array.1", false},
+		{"y", true},
+		{"nonExisting", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("return %v for the path: %s", tc.expected, tc.path), func(t *testing.T) {
+			assertEquals(t, config.IsQuoted(tc.path), tc.expected)
+		})
+	}
+}
+
+func TestIsQuotedAfterMerge(t *testing.T) {
+	fallback, err := ParseString(`a: "x", b: "y", c: "z", d: "w"`)
+	assertNoError(t, err)
+
+	config, err := ParseString(`a: x, b: "y", c: null, e: "v"`)
+	assertNoError(t, err)
+
+	t.Run("keep the quoted strings of the values taken from the merged configs", func(t *testing.T) {
+		for _, merged := range []*Config{config.WithFallback(fallback, NullFallsThrough), MergeConfigs([]*Config{fallback, config})} {
+			assertEquals(t, merged.IsQuoted("a"), false)
+			assertEquals(t, merged.IsQuoted("b"), true)
+			assertEquals(t, merged.IsQuoted("d"), true)
+			assertEquals(t, merged.IsQuoted("e"), true)
+		}
+	})
+
+	t.Run("keep the quoted string of the fallback if the null falls through", func(t *testing.T) {
+		assertEquals(t, config.WithFallback(fallback, NullFallsThrough).IsQuoted("c"), true)
+		assertEquals(t, config.WithFallback(fallback).IsQuoted("c"), false)
+	})
+}
+
+func TestRawText(t *testing.T) {
+	config, err := ParseString(`
+		host: example.com
+		url = "http://"${host} # comment
+		server { port: 80 }
+		array: [ "a" , 1 ]
+		multiLine: """a
+b"""
+		include "testdata/nested/y.conf"`, WithRawText())
+	assertNoError(t, err)
+
+	var testCases = []struct {
+		path     string
+		expected string
+	}{
+		{"host", "example.com"},
+		{"url", `"http://"${host}`},
+		{"server", "{ port: 80 }"},
+		{"server.port", "80"},
+		{"array", `[ "a" , 1 ]`},
+		{"array.0", `"a"`},
+		{"multiLine", "\"\"\"a\nb\"\"\""},
+		{"y", `"foo"`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("return the raw text of the path: %s", tc.path), func(t *testing.T) {
+			text, ok := config.RawText(tc.path)
+			assertEquals(t, ok, true)
+			assertEquals(t, text, tc.expected)
+		})
+	}
+
+	t.Run("report false if the value is not found", func(t *testing.T) {
+		_, ok := config.RawText("nonExisting")
+		assertEquals(t, ok, false)
+	})
+
+	t.Run("report false if the configuration is not parsed with the WithRawText option", func(t *testing.T) {
+		config, err := ParseString("a: 1")
+		assertNoError(t, err)
+		_, ok := config.RawText("a")
+		assertEquals(t, ok, false)
+	})
+}
+
+func TestMergeConfigsVerbose(t *testing.T) {
+	defaults := &Config{root: Object{"db": Object{"host": String("localhost"), "port": Int(5432)}, "debug": Boolean(false)}}
+	production := &Config{root: Object{"db": Object{"host": String("db"), "port": Int(5432)}, "debug": Boolean(true)}}
+	local := &Config{root: Object{"debug": Boolean(false), "extra": Int(1)}}
+
+	t.Run("merge the configs with the later ones overriding and return the overridden values", func(t *testing.T) {
+		got, overrides := MergeConfigsVerbose([]*Config{defaults, production, local})
+		assertDeepEqual(t, got.root, Object{"db": Object{"host": String("db"), "port": Int(5432)}, "debug": Boolean(false), "extra": Int(1)})
+
+		expected := []Override{
+			{Path: "db.host", Old: String("localhost"), New: String("db")},
+			{Path: "debug", Old: Boolean(false), New: Boolean(true)},
+			{Path: "debug", Old: Boolean(true), New: Boolean(false)},
+		}
+		assertDeepEqual(t, overrides, expected)
+	})
+
+	t.Run("do not modify the given configs", func(t *testing.T) {
+		MergeConfigs([]*Config{defaults, production})
+		assertDeepEqual(t, defaults.GetString("db.host"), "localhost")
+	})
+
+	t.Run("ignore the configs with a non-object root", func(t *testing.T) {
+		got := MergeConfigs([]*Config{defaults, {root: Array{Int(1)}}})
+		assertDeepEqual(t, got.root, defaults.root)
+	})
+
+	t.Run("override the earlier values with the nulls like the NullWins mode", func(t *testing.T) {
+		config := &Config{root: Object{"db": Object{"host": null}, "debug": null}}
+
+		got := MergeConfigs([]*Config{defaults, config})
+		assertDeepEqual(t, got.root, Object{"db": Object{"host": null, "port": Int(5432)}, "debug": null})
+		assertDeepEqual(t, got.root, config.WithFallback(defaults, NullWins).root)
+		assertDeepEqual(t, MergeConfigs([]*Config{defaults, config}, NullWins).root, got.root)
+	})
+
+	t.Run("keep the earlier values for the nulls with the NullFallsThrough mode", func(t *testing.T) {
+		config := &Config{root: Object{"db": Object{"host": null}, "debug": null, "extra": null}}
+
+		got, overrides := MergeConfigsVerbose([]*Config{defaults, config}, NullFallsThrough)
+		assertDeepEqual(t, got.root, Object{"db": Object{"host": String("localhost"), "port": Int(5432)}, "debug": Boolean(false), "extra": null})
+		assertDeepEqual(t, got.root, config.WithFallback(defaults, NullFallsThrough).root)
+		assertDeepEqual(t, MergeConfigs([]*Config{defaults, config}, NullFallsThrough).root, got.root)
+		assertDeepEqual(t, overrides, []Override(nil))
+	})
+
+	t.Run("do not share the arrays with the given configs", func(t *testing.T) {
+		config := &Config{root: Object{"hosts": Array{String("a"), Object{"b": Array{Int(1)}}}}}
+
+		got, _ := MergeConfigsVerbose([]*Config{config})
+		got.GetArray("hosts")[0] = String("changed")
+		got.GetArray("hosts.1.b")[0] = Int(2)
+
+		assertDeepEqual(t, config.root, Object{"hosts": Array{String("a"), Object{"b": Array{Int(1)}}}})
+	})
+}
+
+func TestMergeMap(t *testing.T) {
+	config := &Config{root: Object{"db": Object{"host": String("localhost"), "port": Int(5432)}, "tags": Array{String("a")}, "n": null}}
+
+	t.Run("merge the map into a new config recursively", func(t *testing.T) {
+		got, err := config.MergeMap(map[string]interface{}{
+			"db":   map[string]interface{}{"host": "db", "pool": map[string]interface{}{"size": 10}},
+			"tags": []string{"b", "c"},
+			"n":    map[string]interface{}{"x": true},
+			"new":  1.5,
+		})
+		assertNoError(t, err)
+
+		expected := Object{
+			"db":   Object{"host": String("db"), "port": Int(5432), "pool": Object{"size": Int(10)}},
+			"tags": Array{String("b"), String("c")},
+			"n":    Object{"x": Boolean(true)},
+			"new":  Float64(1.5),
+		}
+		assertDeepEqual(t, got.root, expected)
+		assertEquals(t, config.GetString("db.host"), "localhost")
+	})
+
+	t.Run("replace an object with null", func(t *testing.T) {
+		got, err := config.MergeMap(map[string]interface{}{"db": nil})
+		assertNoError(t, err)
+		assertEquals(t, got.Get("db"), Value(null))
+	})
+
+	t.Run("return an error if an object would be replaced with a non-object value", func(t *testing.T) {
+		got, err := config.MergeMap(map[string]interface{}{"db": "localhost"})
+		assertError(t, err, errors.New("could not merge the value at path: db, cannot merge an object with a non-object value"))
+		assertNil(t, got)
+	})
+
+	t.Run("return an error if a non-object value would be replaced with an object", func(t *testing.T) {
+		got, err := config.MergeMap(map[string]interface{}{"db": map[string]interface{}{"port": map[string]interface{}{"a": 1}}})
+		assertError(t, err, errors.New("could not merge the value at path: db.port, cannot merge a non-object value with an object"))
+		assertNil(t, got)
+	})
+
+	t.Run("return an error if a value of the map is not supported", func(t *testing.T) {
+		got, err := config.MergeMap(map[string]interface{}{"a": struct{}{}})
+		assertError(t, err, errors.New("could not merge the map, unsupported type: struct {}"))
+		assertNil(t, got)
+	})
+
+	t.Run("return an error if the root of the config is not an object", func(t *testing.T) {
+		got, err := (&Config{root: Array{}}).MergeMap(map[string]interface{}{"a": 1})
+		assertError(t, err, errors.New("could not merge the map, the root of the config is not an object"))
+		assertNil(t, got)
+	})
+}
+
+func TestSelectProfile(t *testing.T) {
+	config, err := ParseString(`
+		profiles {
+			default { host: localhost, port: 8080, db { pool: 5 } }
+			dev { debug: true }
+			prod { host: "example.com", db { pool: 20 } }
+			version: 1
+		}`)
+	assertNoError(t, err)
+
+	t.Run("select the profile merged over the default profile", func(t *testing.T) {
+		got, err := config.SelectProfile("profiles", "prod")
+		assertNoError(t, err)
+		assertDeepEqual(t, got.root, Object{"host": String("example.com"), "port": Int(8080), "db": Object{"pool": Int(20)}})
+
+		got, err = config.SelectProfile("profiles", "dev")
+		assertNoError(t, err)
+		assertDeepEqual(t, got.root, Object{"host": String("localhost"), "port": Int(8080), "db": Object{"pool": Int(5)}, "debug": Boolean(true)})
+		assertEquals(t, config.GetInt("profiles.default.db.pool"), 5)
+	})
+
+	t.Run("select the profile without a default profile", func(t *testing.T) {
+		config := &Config{root: Object{"envs": Object{"dev": Object{"a": Int(1)}}}}
+		got, err := config.SelectProfile("envs", "dev")
+		assertNoError(t, err)
+		assertDeepEqual(t, got.root, Object{"a": Int(1)})
+	})
+
+	t.Run("return an error listing the available profiles if the profile is not found", func(t *testing.T) {
+		got, err := config.SelectProfile("profiles", "staging")
+		assertError(t, err, errors.New(`could not find the profile: "staging" at path: profiles, available profiles: ["dev" "prod"]`))
+		assertNil(t, got)
+	})
+
+	t.Run("return an error if the profiles are not found", func(t *testing.T) {
+		got, err := config.SelectProfile("environments", "dev")
+		assertError(t, err, errors.New("could not find the profiles at path: environments"))
+		assertNil(t, got)
+	})
+}
+
+func TestWithValue(t *testing.T) {
+	config := &Config{root: Object{"a": Object{"b": Int(1)}, "c": Int(2)}}
+
+	t.Run("return a copy with the value set at the path", func(t *testing.T) {
+		got := config.WithValue("a.d", String("e"))
+		assertDeepEqual(t, got.root, Object{"a": Object{"b": Int(1), "d": String("e")}, "c": Int(2)})
+		assertDeepEqual(t, config.root, Object{"a": Object{"b": Int(1)}, "c": Int(2)})
+	})
+
+	t.Run("create the missing objects and replace the non-object values on the path", func(t *testing.T) {
+		got := config.WithValue("x.y", Int(3)).WithValue(`c."d.e"`, Int(4))
+		assertDeepEqual(t, got.root, Object{"a": Object{"b": Int(1)}, "c": Object{"d.e": Int(4)}, "x": Object{"y": Int(3)}})
+	})
+}
+
+func TestUnset(t *testing.T) {
+	config := &Config{root: Object{"a": Object{"b": Object{"c": Int(1)}}, "d": Int(2)}}
+
+	t.Run("return a copy without the value at the path", func(t *testing.T) {
+		got := config.Unset("d")
+		assertDeepEqual(t, got.root, Object{"a": Object{"b": Object{"c": Int(1)}}})
+		assertDeepEqual(t, config.root, Object{"a": Object{"b": Object{"c": Int(1)}}, "d": Int(2)})
+	})
+
+	t.Run("keep the empty parent objects by default", func(t *testing.T) {
+		got := config.Unset("a.b.c")
+		assertDeepEqual(t, got.root, Object{"a": Object{"b": Object{}}, "d": Int(2)})
+	})
+
+	t.Run("prune the parent objects that become empty", func(t *testing.T) {
+		got := config.Unset("a.b.c", true)
+		assertDeepEqual(t, got.root, Object{"d": Int(2)})
+		assertDeepEqual(t, config.root, Object{"a": Object{"b": Object{"c": Int(1)}}, "d": Int(2)})
+	})
+
+	t.Run("return an equivalent copy if the path does not exist", func(t *testing.T) {
+		assertDeepEqual(t, config.Unset("a.x.y").root, config.root)
+		assertDeepEqual(t, config.Unset("d.e").root, config.root)
+	})
+}
+
+func TestWithFallback(t *testing.T) {
+	config1 := &Config{root: Object{"a": String("aa"), "b": String("bb")}}
+	config2 := &Config{root: Object{"a": String("aaa"), "c": String("cc")}}
+	config3 := &Config{root: Array{Int(1), Int(2)}}
+
+	t.Run("merge the given fallback config with the current config if the root of both of them are of type Object (for the same keys current config should override the fallback)", func(t *testing.T) {
+		expected := &Config{root: Object{"a": String("aa"), "b": String("bb"), "c": String("cc")}}
+		got := config1.WithFallback(config2)
+		assertDeepEqual(t, got, expected)
+	})
+
+	t.Run("override the fallback values with the explicit nulls by default", func(t *testing.T) {
+		current := &Config{root: Object{"a": null, "b": Object{"c": null}}}
+		fallback := &Config{root: Object{"a": String("aa"), "b": Object{"c": Int(1)}}}
+		got := current.WithFallback(fallback)
+		assertDeepEqual(t, got.root, Object{"a": null, "b": Object{"c": null}})
+		assertDeepEqual(t, current.WithFallback(fallback, NullWins).root, got.root)
+	})
+
+	t.Run("use the fallback values instead of the nulls if the NullFallsThrough mode is given", func(t *testing.T) {
+		current := &Config{root: Object{"a": null, "b": Object{"c": null}, "d": null, "e": null}}
+		fallback := &Config{root: Object{"a": String("aa"), "b": Object{"c": Int(1)}, "e": Object{"f": Int(2)}}}
+		got := current.WithFallback(fallback, NullFallsThrough)
+		assertDeepEqual(t, got.root, Object{"a": String("aa"), "b": Object{"c": Int(1)}, "d": null, "e": Object{"f": Int(2)}})
+	})
+
+	t.Run("return the current config if the root of the given fallback config is not an Object", func(t *testing.T) {
+		got := config1.WithFallback(config3)
+		assertDeepEqual(t, got, config1)
+	})
+
+	t.Run("return the current config if the root of it is not an Object", func(t *testing.T) {
+		got := config3.WithFallback(config1)
+		assertDeepEqual(t, got, config3)
+	})
+}
+
+func TestFind(t *testing.T) {
+	t.Run("return nil if path does not contain any dot and there is no value with the given path", func(t *testing.T) {
+		object := Object{"a": Int(1)}
+		got := object.find("b")
+		assertNil(t, got)
+	})
+
+	t.Run("find the value with the path that does not contain any dot", func(t *testing.T) {
+		object := Object{"a": Int(1)}
+		got := object.find("a")
+		assertEquals(t, got, Int(1))
+	})
+
+	t.Run("return nil if path contains dot and there is no value with the sub-path", func(t *testing.T) {
+		object := Object{"a": Object{"b": Int(1)}}
+		got := object.find("c.b")
+		assertNil(t, got)
+	})
+
+	t.Run("find the value with the path that contains dots", func(t *testing.T) {
+		object := Object{"a": Object{"b": Int(1)}}
+		got := object.find("a.b")
+		assertEquals(t, got, Int(1))
+	})
+
+	t.Run("find the value with the path that mixes the object keys and the array indices", func(t *testing.T) {
+		object := Object{"clusters": Array{Object{"nodes": Array{Int(1), Object{"address": String("localhost")}}}}}
+		got := object.find("clusters.0.nodes.1.address")
+		assertEquals(t, got, String("localhost"))
+	})
+
+	t.Run("find the array elements from the end with the negative indices", func(t *testing.T) {
+		object := Object{"servers": Array{Object{"host": String("a")}, Object{"host": String("b")}}}
+		assertEquals(t, object.find("servers.-1.host"), String("b"))
+		assertEquals(t, object.find("servers.-2.host"), String("a"))
+	})
+
+	t.Run("return nil if the array index is out of range or not a number", func(t *testing.T) {
+		object := Object{"a": Array{Int(1)}}
+		assertNil(t, object.find("a.1"))
+		assertNil(t, object.find("a.-2"))
+		assertNil(t, object.find("a.b"))
+	})
+
+	t.Run("return nil if the path descends into a non-container value", func(t *testing.T) {
+		object := Object{"a": Int(1)}
+		got := object.find("a.b")
+		assertNil(t, got)
+	})
+}
+
+func TestObject_String(t *testing.T) {
+	t.Run("return the string of an empty object", func(t *testing.T) {
+		got := Object{}.String()
+		assertEquals(t, got, "{}")
+	})
+
+	t.Run("return the string of an object that contains a single element", func(t *testing.T) {
+		got := Object{"a": Int(1)}.String()
+		assertEquals(t, got, "{a:1}")
+	})
+
+	t.Run("return the string of an object that contains multiple elements", func(t *testing.T) {
+		got := Object{"a": Int(1), "b": Int(2)}.String()
+		if got != "{a:1, b:2}" && got != "{b:2, a:1}" {
+			fail(t, got, "{a:1, b:2}")
+		}
+	})
+
+	t.Run("return the string of an object that contains a string element with the ':' character", func(t *testing.T) {
+		got := Object{"a": String("0.0.0.0:80")}.String()
+		assertEquals(t, got, "{a:\"0.0.0.0:80\"}")
+	})
+
+	t.Run("return the string of an object that contains multiple elements with the ':' character", func(t *testing.T) {
+		got := Object{"a": String("0.0.0.0:80"), "b": Int(2)}.String()
+		if got != "{a:\"0.0.0.0:80\", b:2}" && got != "{b:2, a:\"0.0.0.0:80\"}" {
+			fail(t, got, "{a:1, b:2}")
+		}
+	})
+
+}
+
+func TestArray_String(t *testing.T) {
+	t.Run("return the string of an empty array", func(t *testing.T) {
+		got := Array{}.String()
+		assertEquals(t, got, "[]")
+	})
+
+	t.Run("return the string of an array that contains a single element", func(t *testing.T) {
+		got := Array{Int(1)}.String()
+		assertEquals(t, got, "[1]")
+	})
+
+	t.Run("return the string of an array that contains multiple elements", func(t *testing.T) {
+		got := Array{Int(1), Int(2)}.String()
+		assertEquals(t, got, "[1,2]")
+	})
+
+	t.Run("return the string of an array that contains a single elements with the ':' character", func(t *testing.T) {
+		got := Array{String("0.0.0.0:80")}.String()
+		assertEquals(t, got, "[\"0.0.0.0:80\"]")
+	})
+
+	t.Run("return the string of an array that contains multiple elements with the ':' character", func(t *testing.T) {
+		got := Array{String("0.0.0.0:80"), String("localhost:443")}.String()
+		assertEquals(t, got, "[\"0.0.0.0:80\",\"localhost:443\"]")
+	})
+}
+
+func TestGetPointer(t *testing.T) {
+	config, err := ParseString(`{servers: [{host: a}, {host: b}], "a.b": {"c/d": 1, "e~f": 2}, "": 3}`)
+	assertNoError(t, err)
+
+	var testCases = []struct {
+		pointer  string
+		expected Value
+	}{
+		{"/servers/1/host", String("b")},
+		{"/a.b/c~1d", Int(1)},
+		{"/a.b/e~0f", Int(2)},
+		{"/", Int(3)},
+	}
+
+	for _, tc := range testCases {
+		t.Run("find the value at "+tc.pointer, func(t *testing.T) {
+			got, ok := config.GetPointer(tc.pointer)
+			assertEquals(t, ok, true)
+			assertEquals(t, got, tc.expected)
+		})
+	}
+
+	t.Run("return the root for the empty pointer", func(t *testing.T) {
+		got, ok := config.GetPointer("")
+		assertEquals(t, ok, true)
+		assertDeepEqual(t, got, config.root)
+	})
+
+	for _, pointer := range []string{"servers/0", "/servers/2", "/servers/-1", "/servers/01", "/servers/-", "/servers/0/host/x", "/missing"} {
+		t.Run("report false for "+pointer, func(t *testing.T) {
+			got, ok := config.GetPointer(pointer)
+			assertEquals(t, ok, false)
+			assertNil(t, got)
+		})
+	}
+}
+
+func TestResolvePath(t *testing.T) {
+	config := &Config{root: Object{"a": Object{"b.c": Array{Int(1), Object{"d e": String("f"), "": Int(2)}}}}}
+
+	var testCases = []struct {
+		path      string
+		canonical string
+		expected  Value
+	}{
+		{`a`, `a`, config.Get("a")},
+		{`"a"."b.c"`, `a."b.c"`, config.Get(`a."b.c"`)},
+		{`a."b.c".00`, `a."b.c".0`, Int(1)},
+		{`"a"."b.c".1."d e"`, `a."b.c".1."d e"`, String("f")},
+		{`a."b.c".1.""`, `a."b.c".1.""`, Int(2)},
+		{`a."b.c".-2`, `a."b.c".0`, Int(1)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("resolve the path: %s", tc.path), func(t *testing.T) {
+			canonical, value, ok := config.ResolvePath(tc.path)
+			assertEquals(t, ok, true)
+			assertEquals(t, canonical, tc.canonical)
+			assertDeepEqual(t, value, tc.expected)
+		})
+	}
+
+	t.Run("report false if the value is not found", func(t *testing.T) {
+		_, _, ok := config.ResolvePath("a.b.c")
+		assertEquals(t, ok, false)
+	})
+}
+
+func TestValuesOfType(t *testing.T) {
+	config := &Config{root: Object{
+		"secrets": Object{"db": Object{"password": String("p"), "port": Int(5432)}, "tokens": Array{String("t1"), Int(1)}, "a.b": String("q")},
+		"name":    String("n"),
+	}}
+
+	t.Run("return the values of the type in the subtree with their paths", func(t *testing.T) {
+		assertDeepEqual(t, config.ValuesOfType("secrets", StringType), []Value{String("q"), String("p"), String("t1")})
+		assertDeepEqual(t, config.PathsOfType("secrets", StringType), []string{`secrets."a.b"`, "secrets.db.password", "secrets.tokens.0"})
+	})
+
+	t.Run("return the values of the whole config for the empty path", func(t *testing.T) {
+		assertDeepEqual(t, config.PathsOfType("", NumberType), []string{"secrets.db.port", "secrets.tokens.1"})
+	})
+
+	t.Run("include the value at the path if it is of the type", func(t *testing.T) {
+		assertDeepEqual(t, config.ValuesOfType("name", StringType), []Value{String("n")})
+	})
+
+	t.Run("return nil if the value is not found", func(t *testing.T) {
+		assertNil(t, config.ValuesOfType("nonExisting", StringType))
+	})
+}
+
+func TestMatchPaths(t *testing.T) {
+	config, err := ParseString(`{
+		servers { a { port: 80, enabled: true }, b { port: 81, tls { enabled: false } } }
+		dbMain.port: 5432, dbReplica.port: 5433
+		"x.y" { enabled: true }
+		list: [1, 2]
+	}`)
+	assertNoError(t, err)
+
+	t.Run("match a single key with *", func(t *testing.T) {
+		assertDeepEqual(t, config.MatchPaths("servers.*.port"), []string{"servers.a.port", "servers.b.port"})
+		assertDeepEqual(t, config.MatchPaths("db*.port"), []string{"dbMain.port", "dbReplica.port"})
+	})
+
+	t.Run("match any number of keys with **", func(t *testing.T) {
+		assertDeepEqual(t, config.MatchPaths("**.enabled"), []string{"servers.a.enabled", "servers.b.tls.enabled", `"x.y".enabled`})
+		assertDeepEqual(t, config.MatchPaths("servers.**"), []string{"servers.a.enabled", "servers.a.port", "servers.b.port", "servers.b.tls.enabled"})
+	})
+
+	t.Run("match the arrays as leaves and the quoted keys", func(t *testing.T) {
+		assertDeepEqual(t, config.MatchPaths("list"), []string{"list"})
+		assertDeepEqual(t, config.MatchPaths(`"x.y".*`), []string{`"x.y".enabled`})
+	})
+
+	t.Run("return nil if no path matches", func(t *testing.T) {
+		assertNil(t, config.MatchPaths("servers.*"))
+		assertNil(t, config.MatchPaths("list.0"))
+	})
+}
+func TestSplitPath(t *testing.T) {
+	var testCases = []struct {
+		path     string
+		expected []string
+	}{
+		{"a.b.c", []string{"a", "b", "c"}},
+		{`"a.b".c`, []string{"a.b", "c"}},
+		{`a."b.c"`, []string{"a", "b.c"}},
+		{`"com.example.key"`, []string{"com.example.key"}},
+		{`"a\".b".c`, []string{`a".b`, "c"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("split the path: %s", tc.path), func(t *testing.T) {
+			assertDeepEqual(t, splitPath(tc.path), tc.expected)
+		})
+	}
+}
+
+func TestHasPath(t *testing.T) {
+	config := &Config{root: Object{"a": Object{"b": null}, "c": Array{Int(1)}}}
+	assertEquals(t, config.HasPath("a.b"), true)
+	assertEquals(t, config.HasPath("c.0"), true)
+	assertEquals(t, config.HasPath("a.x"), false)
+	assertEquals(t, config.HasPath("c.1"), false)
+}
+
+func TestGet(t *testing.T) {
+	t.Run("return nil if the root of config is not an Object", func(t *testing.T) {
+		config := &Config{root: Array{Int(1)}}
+		got := config.Get("a")
+		assertNil(t, got)
+	})
+
+	t.Run("find the value if the root of config is an object and a value exist with the given path", func(t *testing.T) {
+		config := &Config{root: Object{"a": Int(1)}}
+		got := config.Get("a")
+		assertEquals(t, got, Int(1))
+	})
+
+	t.Run("find the value by index if the root of config is an Array", func(t *testing.T) {
+		config := &Config{root: Array{Int(1), Object{"a": Int(2)}}}
+		assertEquals(t, config.Get("1.a"), Int(2))
+		assertEquals(t, config.GetInt("0"), 1)
+	})
+
+	t.Run("return nil if the root of config is an object but value with the given path does not exist", func(t *testing.T) {
+		config := &Config{root: Object{"a": Int(1)}}
+		got := config.Get("b")
+		assertNil(t, got)
+	})
+}
+
+func TestNewBooleanFromString(t *testing.T) {
+	var testCases = []struct {
+		input    string
+		expected Boolean
+	}{
+		{"true", Boolean(true)},
 		{"yes", Boolean(true)},
 		{"on", Boolean(true)},
 		{"false", Boolean(false)},
@@ -451,6 +1783,159 @@ func TestNewBooleanFromString(t *testing.T) {
 	})
 }
 
+func TestRegisterBooleanSpellings(t *testing.T) {
+	t.Cleanup(func() { registeredBooleans.spellings = map[string]bool{} })
+
+	t.Run("register the custom spellings and match them case-insensitively", func(t *testing.T) {
+		err := RegisterBooleanSpellings([]string{"Enabled"}, []string{"disabled"})
+		assertNoError(t, err)
+		assertEquals(t, newBooleanFromString("enabled"), Boolean(true))
+		assertEquals(t, newBooleanFromString("DISABLED"), Boolean(false))
+		assertEquals(t, (&Config{root: Object{"a": String("ENABLED")}}).GetBoolean("a"), true)
+	})
+
+	t.Run("parse the registered spellings as boolean values", func(t *testing.T) {
+		got, err := ParseString("a: enabled, b: disabled")
+		assertNoError(t, err)
+		assertDeepEqual(t, got.root, Object{"a": Boolean(true), "b": Boolean(false)})
+	})
+
+	t.Run("return an error if the same spelling is given for both true and false", func(t *testing.T) {
+		err := RegisterBooleanSpellings([]string{"active"}, []string{"ACTIVE"})
+		assertError(t, err, errors.New(`boolean spelling: "ACTIVE" cannot be registered as false, it is already true`))
+		assertEquals(t, isBooleanString("active"), false)
+	})
+
+	t.Run("return an error if the spelling conflicts with a default spelling", func(t *testing.T) {
+		err := RegisterBooleanSpellings(nil, []string{"Yes"})
+		assertError(t, err, errors.New(`boolean spelling: "Yes" cannot be registered as false, it is already true`))
+	})
+
+	t.Run("return an error if the spelling conflicts with a registered spelling", func(t *testing.T) {
+		err := RegisterBooleanSpellings([]string{"disabled"}, nil)
+		assertError(t, err, errors.New(`boolean spelling: "disabled" cannot be registered as true, it is already false`))
+	})
+
+	t.Run("return an error if the spelling is empty", func(t *testing.T) {
+		err := RegisterBooleanSpellings([]string{""}, nil)
+		assertError(t, err, errors.New("boolean spelling cannot be empty"))
+	})
+}
+
+func TestWithBooleanSpellings(t *testing.T) {
+	t.Run("parse the spellings only in the parse they are given to", func(t *testing.T) {
+		got, err := ParseString("a: Enabled, b: disabled", WithBooleanSpellings([]string{"enabled"}, []string{"DISABLED"}))
+		assertNoError(t, err)
+		assertDeepEqual(t, got.root, Object{"a": Boolean(true), "b": Boolean(false)})
+
+		got, err = ParseString("a: enabled")
+		assertNoError(t, err)
+		assertDeepEqual(t, got.root, Object{"a": String("enabled")})
+	})
+
+	t.Run("parse the same spelling differently in the concurrent parses", func(t *testing.T) {
+		var wg sync.WaitGroup
+
+		results := make([]Value, 20)
+		for i := range results {
+			wg.Add(1)
+
+			go func(i int) {
+				defer wg.Done()
+
+				option := WithBooleanSpellings([]string{"active"}, nil)
+				if i%2 == 1 {
+					option = WithBooleanSpellings(nil, []string{"active"})
+				}
+
+				config, err := ParseString("a: active", option)
+				if err == nil {
+					results[i] = config.Get("a")
+				}
+			}(i)
+		}
+
+		wg.Wait()
+
+		for i, result := range results {
+			assertEquals(t, result, Value(Boolean(i%2 == 0)))
+		}
+	})
+
+	t.Run("read the quoted spellings with the getters and Unmarshal of the parsed config", func(t *testing.T) {
+		config, err := ParseString(`a: "enabled", b: ["disabled", "Enabled"], c {d: "disabled"}`,
+			WithBooleanSpellings([]string{"enabled"}, []string{"disabled"}))
+		assertNoError(t, err)
+		assertEquals(t, config.GetBoolean("a"), true)
+		assertDeepEqual(t, config.GetBooleanList("b"), []bool{false, true})
+		assertEquals(t, config.GetConfig("c").GetBoolean("d"), false)
+
+		var target struct{ A bool }
+		assertNoError(t, config.Unmarshal(&target))
+		assertEquals(t, target.A, true)
+
+		plain, err := ParseString(`a: "enabled"`)
+		assertNoError(t, err)
+		assertPanic(t, func() { plain.GetBoolean("a") }, "cannot parse value: enabled to boolean!")
+	})
+
+	t.Run("ignore the registered spellings if the option is given", func(t *testing.T) {
+		t.Cleanup(func() { registeredBooleans.spellings = map[string]bool{} })
+
+		err := RegisterBooleanSpellings([]string{"registered"}, nil)
+		assertNoError(t, err)
+
+		config, err := ParseString(`a: registered, b: "registered"`, WithBooleanSpellings([]string{"enabled"}, nil))
+		assertNoError(t, err)
+		assertDeepEqual(t, config.root, Object{"a": String("registered"), "b": String("registered")})
+		assertPanic(t, func() { config.GetBoolean("b") }, "cannot parse value: registered to boolean!")
+
+		config, err = ParseString("a: registered")
+		assertNoError(t, err)
+		assertDeepEqual(t, config.root, Object{"a": Boolean(true)})
+	})
+
+	t.Run("return an error if the spellings are invalid", func(t *testing.T) {
+		got, err := ParseString("a: active", WithBooleanSpellings([]string{"active"}, []string{"ACTIVE"}))
+		assertError(t, err, errors.New(`boolean spelling: "ACTIVE" cannot be registered as false, it is already true`))
+		assertNil(t, got)
+
+		_, err = ParseString("a: 1", WithBooleanSpellings(nil, []string{"on"}))
+		assertError(t, err, errors.New(`boolean spelling: "on" cannot be registered as false, it is already true`))
+	})
+}
+
+func TestResolutionReport(t *testing.T) {
+	t.Run("return an empty report if the config does not contain any substitution", func(t *testing.T) {
+		config := &Config{root: Object{"a": Int(1)}}
+		assertDeepEqual(t, config.ResolutionReport(), ResolutionReport{})
+	})
+
+	t.Run("report the resolved, environment and unresolved optional substitutions", func(t *testing.T) {
+		t.Setenv("REPORT_TEST_ENV", "env")
+		config, err := ParseString("a:1, b:${a}, c:[${a}], d:${REPORT_TEST_ENV}, e:${?missing}")
+		assertNoError(t, err)
+		expected := ResolutionReport{
+			Resolved:            []string{"a", "a"},
+			FromEnv:             []string{"REPORT_TEST_ENV"},
+			UnresolvedOptional:  []string{"missing"},
+			SubstitutionSources: []string{"a"},
+		}
+		assertDeepEqual(t, config.ResolutionReport(), expected)
+	})
+
+	t.Run("report the top-level keys that are substituted into the other top-level keys once", func(t *testing.T) {
+		config, err := ParseString(`
+			vars { host: localhost, port: 80 }
+			base: ["/srv"]
+			base += "/app"
+			url: "http://"${vars.host}":"${vars.port}${base.1}
+			server { url: ${url}, local: ${server.url} }`)
+		assertNoError(t, err)
+		assertDeepEqual(t, config.ResolutionReport().SubstitutionSources, []string{"base", "url", "vars"})
+	})
+}
+
 func TestSubstitution_String(t *testing.T) {
 	t.Run("return the string of required substitution", func(t *testing.T) {
 		substitution := &Substitution{path: "a", optional: false}
@@ -474,6 +1959,24 @@ func TestValueWithAlternative_String(t *testing.T) {
 	})
 }
 
+func TestObject_Entries(t *testing.T) {
+	t.Run("return the entries in sorted order of the keys", func(t *testing.T) {
+		object := Object{"b": Int(2), "a": Object{"c": null}, "": String("x")}
+		assertDeepEqual(t, object.Entries(), []Entry{{"", String("x")}, {"a", Object{"c": null}}, {"b", Int(2)}})
+	})
+
+	t.Run("not change the object if the entries are changed", func(t *testing.T) {
+		object := Object{"a": Int(1)}
+		entries := object.Entries()
+		entries[0] = Entry{Key: "b", Value: Int(2)}
+		assertDeepEqual(t, object, Object{"a": Int(1)})
+	})
+
+	t.Run("return an empty slice for an empty object", func(t *testing.T) {
+		assertDeepEqual(t, Object{}.Entries(), []Entry{})
+	})
+}
+
 func TestToConfig(t *testing.T) {
 	object := Object{"a": Int(1)}
 	got := object.ToConfig()
@@ -493,3 +1996,83 @@ func TestContainsObject(t *testing.T) {
 		assertEquals(t, got, true)
 	})
 }
+
+func TestAsHelpers(t *testing.T) {
+	t.Run("return the value with the matching type", func(t *testing.T) {
+		object, ok := AsObject(Object{"a": Int(1)})
+		assertEquals(t, ok, true)
+		assertDeepEqual(t, object, Object{"a": Int(1)})
+
+		array, ok := AsArray(Array{Int(1)})
+		assertEquals(t, ok, true)
+		assertDeepEqual(t, array, Array{Int(1)})
+
+		str, ok := AsString(String("a"))
+		assertEquals(t, ok, true)
+		assertEquals(t, str, "a")
+	})
+
+	t.Run("return false for the values with a different type", func(t *testing.T) {
+		_, ok := AsObject(Array{})
+		assertEquals(t, ok, false)
+
+		_, ok = AsArray(Object{})
+		assertEquals(t, ok, false)
+
+		_, ok = AsString(Int(1))
+		assertEquals(t, ok, false)
+
+		_, ok = AsString(nil)
+		assertEquals(t, ok, false)
+	})
+
+	t.Run("convert the numbers and the strings of numbers", func(t *testing.T) {
+		i, ok := AsInt(Int(5))
+		assertEquals(t, ok, true)
+		assertEquals(t, i, 5)
+
+		i, ok = AsInt(String("7"))
+		assertEquals(t, ok, true)
+		assertEquals(t, i, 7)
+
+		_, ok = AsInt(Float64(1.5))
+		assertEquals(t, ok, false)
+
+		f, ok := AsFloat64(Int(2))
+		assertEquals(t, ok, true)
+		assertEquals(t, f, 2.0)
+
+		f, ok = AsFloat64(String("0.5"))
+		assertEquals(t, ok, true)
+		assertEquals(t, f, 0.5)
+
+		_, ok = AsFloat64(String("a"))
+		assertEquals(t, ok, false)
+	})
+
+	t.Run("convert the booleans and the boolean spellings", func(t *testing.T) {
+		b, ok := AsBoolean(Boolean(true))
+		assertEquals(t, ok, true)
+		assertEquals(t, b, true)
+
+		b, ok = AsBoolean(String("off"))
+		assertEquals(t, ok, true)
+		assertEquals(t, b, false)
+
+		_, ok = AsBoolean(String("maybe"))
+		assertEquals(t, ok, false)
+	})
+
+	t.Run("convert the durations and the strings with a duration unit", func(t *testing.T) {
+		d, ok := AsDuration(Duration(time.Second))
+		assertEquals(t, ok, true)
+		assertEquals(t, d, time.Second)
+
+		d, ok = AsDuration(String("10 ms"))
+		assertEquals(t, ok, true)
+		assertEquals(t, d, 10*time.Millisecond)
+
+		_, ok = AsDuration(Int(10))
+		assertEquals(t, ok, false)
+	})
+}
@@ -0,0 +1,128 @@
+package hocon
+
+import "strconv"
+
+// Operation is the kind of a Change between two configurations
+type Operation int
+
+// Operation constants
+const (
+	Added Operation = iota
+	Removed
+	Modified
+)
+
+func (o Operation) String() string {
+	switch o {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Modified:
+		return "modified"
+	default:
+		return "unknown"
+	}
+}
+
+// Change describes a difference between two configurations at the given dotted path,
+// Old is nil for the added values and New is nil for the removed values
+type Change struct {
+	Path      string
+	Operation Operation
+	Old       Value
+	New       Value
+}
+
+// Diff function compares the resolved trees of the given configurations by path and returns the changes
+// needed to turn the previous configuration into the current one, sorted by path.
+// Objects are compared key by key and arrays element by element with the indexes as the path segments,
+// a nil configuration is treated as an empty one
+func Diff(previous, current *Config) []Change {
+	var changes []Change
+	diffValues("", rootOf(previous), rootOf(current), &changes)
+
+	return changes
+}
+
+func rootOf(config *Config) Value {
+	if config == nil || config.root == nil {
+		return Object{}
+	}
+
+	return config.GetRoot()
+}
+
+func diffValues(path string, previous, current Value, changes *[]Change) {
+	switch {
+	case previous == nil && current == nil:
+		return
+	case previous == nil:
+		*changes = append(*changes, Change{Path: path, Operation: Added, New: current})
+		return
+	case current == nil:
+		*changes = append(*changes, Change{Path: path, Operation: Removed, Old: previous})
+		return
+	}
+
+	previousObject, isPreviousObject := previous.(Object)
+	currentObject, isCurrentObject := current.(Object)
+	if isPreviousObject && isCurrentObject {
+		diffObjects(path, previousObject, currentObject, changes)
+		return
+	}
+
+	previousArray, isPreviousArray := previous.(Array)
+	currentArray, isCurrentArray := current.(Array)
+	if isPreviousArray && isCurrentArray {
+		diffArrays(path, previousArray, currentArray, changes)
+		return
+	}
+
+	if previous.Type() != current.Type() || previous.String() != current.String() {
+		*changes = append(*changes, Change{Path: path, Operation: Modified, Old: previous, New: current})
+	}
+}
+
+func diffObjects(path string, previous, current Object, changes *[]Change) {
+	keys := Object{}
+	for key := range previous {
+		keys[key] = nil
+	}
+
+	for key := range current {
+		keys[key] = nil
+	}
+
+	for _, key := range keys.sortedKeys() {
+		diffValues(joinDiffPath(path, key), previous[key], current[key], changes)
+	}
+}
+
+func diffArrays(path string, previous, current Array, changes *[]Change) {
+	length := len(previous)
+	if len(current) > length {
+		length = len(current)
+	}
+
+	for i := 0; i < length; i++ {
+		var previousElement, currentElement Value
+		if i < len(previous) {
+			previousElement = previous[i]
+		}
+
+		if i < len(current) {
+			currentElement = current[i]
+		}
+
+		diffValues(joinDiffPath(path, strconv.Itoa(i)), previousElement, currentElement, changes)
+	}
+}
+
+func joinDiffPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + dotToken + key
+}
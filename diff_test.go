@@ -0,0 +1,49 @@
+package hocon
+
+import "testing"
+
+func TestDiff(t *testing.T) {
+	t.Run("return no changes for equal configurations", func(t *testing.T) {
+		previous, current := &Config{root: Object{"a": Int(1)}}, &Config{root: Object{"a": Int(1)}}
+		assertDeepEqual(t, len(Diff(previous, current)), 0)
+	})
+
+	t.Run("return the added, removed and modified values sorted by path", func(t *testing.T) {
+		previous := &Config{root: Object{
+			"database": Object{"host": String("localhost"), "port": Int(5432)},
+			"timeout":  Int(5),
+		}}
+		current := &Config{root: Object{
+			"database": Object{"host": String("db"), "port": Int(5432), "user": String("admin")},
+		}}
+		expected := []Change{
+			{Path: "database.host", Operation: Modified, Old: String("localhost"), New: String("db")},
+			{Path: "database.user", Operation: Added, New: String("admin")},
+			{Path: "timeout", Operation: Removed, Old: Int(5)},
+		}
+		assertDeepEqual(t, Diff(previous, current), expected)
+	})
+
+	t.Run("compare the arrays element by element", func(t *testing.T) {
+		previous := &Config{root: Object{"a": Array{Int(1), Int(2)}}}
+		current := &Config{root: Object{"a": Array{Int(1), Int(3), Int(4)}}}
+		expected := []Change{
+			{Path: "a.1", Operation: Modified, Old: Int(2), New: Int(3)},
+			{Path: "a.2", Operation: Added, New: Int(4)},
+		}
+		assertDeepEqual(t, Diff(previous, current), expected)
+	})
+
+	t.Run("return a modification if the type of the value changes", func(t *testing.T) {
+		previous := &Config{root: Object{"a": String("1")}}
+		current := &Config{root: Object{"a": Object{"b": Int(1)}}}
+		expected := []Change{{Path: "a", Operation: Modified, Old: String("1"), New: Object{"b": Int(1)}}}
+		assertDeepEqual(t, Diff(previous, current), expected)
+	})
+
+	t.Run("treat a nil configuration as an empty one", func(t *testing.T) {
+		current := &Config{root: Object{"a": Int(1)}}
+		expected := []Change{{Path: "a", Operation: Added, New: Int(1)}}
+		assertDeepEqual(t, Diff(nil, current), expected)
+	})
+}
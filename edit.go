@@ -0,0 +1,55 @@
+package hocon
+
+import (
+	"fmt"
+	"strings"
+)
+
+// span is the range of a value in the parsed source, from the start offset to the end offset (exclusive)
+type span struct {
+	start, end int
+}
+
+// sourceSpans is the parsed source with the spans of the values written in it, keyed by the paths of the values
+// with the keys quoted as needed (e.g. "a.b".c), so the keys containing a dot are not mixed up with the nested keys
+type sourceSpans struct {
+	text  string
+	spans map[string]span
+}
+
+// SetPreserving method returns the source text of the configuration with the value at the given path replaced by the given
+// value, only the text of the old value is rewritten so the formatting, the comments and the order of the keys are preserved,
+// e.g. to change a single setting of a file edited by hand. The value is converted like the Builder.Set method does and it is
+// rendered as a HOCON value. The configuration must be parsed with the WithRawText option and the value must be written in
+// the parsed source, if it is defined more than once its last definition is replaced. The configuration itself is not modified
+func (c *Config) SetPreserving(path string, value interface{}) (string, error) {
+	if c.source == nil {
+		return "", fmt.Errorf("could not set the value at path: %s, the source is not recorded, parse the configuration with the WithRawText option", path)
+	}
+
+	valueSpan, ok := c.source.spans[joinPath(splitPath(path))]
+	if !ok {
+		return "", fmt.Errorf("could not set the value at path: %s, the value is not written in the parsed source", path)
+	}
+
+	converted, err := toValue(value)
+	if err != nil {
+		return "", fmt.Errorf("could not set the value at path: %s, %w", path, err)
+	}
+
+	var builder strings.Builder
+
+	before := c.source.text[:valueSpan.start]
+	builder.WriteString(before)
+
+	// the separator can be omitted before an object (e.g. server { ... }), the other values of the fields require it
+	if trimmed := strings.TrimRight(before, " \t"); converted.Type() != ObjectType && trimmed != "" &&
+		!strings.ContainsAny(trimmed[len(trimmed)-1:], ":=[,\n") {
+		builder.WriteString(equalsToken + " ")
+	}
+
+	(&renderer{w: &builder, reparseable: true}).render(converted)
+	builder.WriteString(c.source.text[valueSpan.end:])
+
+	return builder.String(), nil
+}
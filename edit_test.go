@@ -0,0 +1,90 @@
+package hocon
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSetPreserving(t *testing.T) {
+	input := "# header\nserver {\n  host = localhost # the host\n  port: 8080\n}\nlist = [1, {a: 1}]  // numbers\ntimeout = 10 seconds\n"
+
+	config, err := ParseString(input, WithRawText())
+	assertNoError(t, err)
+
+	var testCases = []struct {
+		description string
+		path        string
+		value       interface{}
+		expected    string
+	}{
+		{"replace a nested value keeping the comments", "server.host", "a:b",
+			"# header\nserver {\n  host = \"a:b\" # the host\n  port: 8080\n}\nlist = [1, {a: 1}]  // numbers\ntimeout = 10 seconds\n"},
+		{"replace a value written with a colon", "server.port", 9090,
+			"# header\nserver {\n  host = localhost # the host\n  port: 9090\n}\nlist = [1, {a: 1}]  // numbers\ntimeout = 10 seconds\n"},
+		{"replace an array", "list", []string{"x", "y"},
+			"# header\nserver {\n  host = localhost # the host\n  port: 8080\n}\nlist = [\"x\",\"y\"]  // numbers\ntimeout = 10 seconds\n"},
+		{"replace an array element", "list.1", false,
+			"# header\nserver {\n  host = localhost # the host\n  port: 8080\n}\nlist = [1, false]  // numbers\ntimeout = 10 seconds\n"},
+		{"replace a concatenation", "timeout", 5 * time.Second,
+			"# header\nserver {\n  host = localhost # the host\n  port: 8080\n}\nlist = [1, {a: 1}]  // numbers\ntimeout = 5000ms\n"},
+		{"add the separator if an object written without it is replaced", "server", "none",
+			"# header\nserver = \"none\"\nlist = [1, {a: 1}]  // numbers\ntimeout = 10 seconds\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			got, err := config.SetPreserving(tc.path, tc.value)
+			assertNoError(t, err)
+			assertEquals(t, got, tc.expected)
+
+			_, err = ParseString(got)
+			assertNoError(t, err)
+		})
+	}
+
+	t.Run("tell the quoted keys containing a dot from the nested keys", func(t *testing.T) {
+		config, err := ParseString("\"a.b\": 1\na { b: 2 }\n", WithRawText())
+		assertNoError(t, err)
+
+		got, err := config.SetPreserving(`"a.b"`, 3)
+		assertNoError(t, err)
+		assertEquals(t, got, "\"a.b\": 3\na { b: 2 }\n")
+
+		got, err = config.SetPreserving("a.b", 4)
+		assertNoError(t, err)
+		assertEquals(t, got, "\"a.b\": 1\na { b: 4 }\n")
+	})
+
+	t.Run("edit only the source of the document of the stream", func(t *testing.T) {
+		configs, err := ParseStream(strings.NewReader("{a: 1}\n{a: 2}\n"), WithRawText())
+		assertNoError(t, err)
+
+		got, err := configs[0].SetPreserving("a", 3)
+		assertNoError(t, err)
+		assertEquals(t, got, "{a: 3}\n")
+
+		got, err = configs[1].SetPreserving("a", 4)
+		assertNoError(t, err)
+		assertEquals(t, got, "{a: 4}\n")
+	})
+
+	t.Run("return an error if the value is not written in the parsed source", func(t *testing.T) {
+		_, err := config.SetPreserving("missing", 1)
+		assertError(t, err, errors.New("could not set the value at path: missing, the value is not written in the parsed source"))
+	})
+
+	t.Run("return an error if the source is not recorded", func(t *testing.T) {
+		config, err := ParseString(input)
+		assertNoError(t, err)
+
+		_, err = config.SetPreserving("server.host", "a")
+		assertError(t, err, errors.New("could not set the value at path: server.host, the source is not recorded, parse the configuration with the WithRawText option"))
+	})
+
+	t.Run("return an error if the value cannot be converted", func(t *testing.T) {
+		_, err := config.SetPreserving("server.host", struct{}{})
+		assertError(t, err, errors.New("could not set the value at path: server.host, unsupported type: struct {}"))
+	})
+}
@@ -0,0 +1,114 @@
+package hocon
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// EnvKeyMapping selects how the names of the environment variables are mapped to the paths of the config
+type EnvKeyMapping int
+
+// EnvKeyMapping constants
+const (
+	EnvUnderscoreToDot       EnvKeyMapping = iota // each underscore separates the keys, e.g. APP_DB_HOST -> db.host
+	EnvDoubleUnderscoreToDot                      // double underscores separate the keys, e.g. APP_DB__MAX_SIZE -> db.max_size
+)
+
+// ParseEnv function creates a Config from the environment variables whose names start with the given prefix followed
+// by an underscore (e.g. APP_DB_HOST for the APP prefix), the rest of the names are lower-cased and mapped to the paths
+// with the given mapping (EnvUnderscoreToDot by default). The values are parsed as integers and booleans if possible
+// and kept as strings otherwise. If a path is set both to a value and to an object (e.g. APP_DB and APP_DB_HOST),
+// the object wins. The variables that map to a path with an empty key are ignored
+func ParseEnv(prefix string, mapping ...EnvKeyMapping) *Config {
+	if len(mapping) > 0 {
+		return parseEnv(prefix, mapping[0], nil)
+	}
+
+	return parseEnv(prefix, EnvUnderscoreToDot, nil)
+}
+
+// ParseEnvWithOptions function creates a Config from the environment variables like the ParseEnv function does,
+// the values are parsed as booleans with the spellings of the WithBooleanSpellings option if given, and the created
+// Config reads the boolean strings with the same spellings. The other options are ignored, returns an error
+// if an option is invalid
+func ParseEnvWithOptions(prefix string, mapping EnvKeyMapping, opts ...Option) (*Config, error) {
+	options := newOptions(opts)
+	if options.err != nil {
+		return nil, options.err
+	}
+
+	return parseEnv(prefix, mapping, options.booleans), nil
+}
+
+func parseEnv(prefix string, mapping EnvKeyMapping, booleans booleanSpellings) *Config {
+	separator := "_"
+	if mapping == EnvDoubleUnderscoreToDot {
+		separator = "__"
+	}
+
+	prefix = strings.TrimSuffix(prefix, "_")
+	root := Object{}
+
+	for _, variable := range os.Environ() {
+		name, value, _ := strings.Cut(variable, "=")
+
+		if prefix != "" {
+			if !strings.HasPrefix(name, prefix+"_") {
+				continue
+			}
+
+			name = name[len(prefix)+1:]
+		}
+
+		keys := strings.Split(strings.ToLower(name), separator)
+		if containsEmptyKey(keys) {
+			continue
+		}
+
+		setProperty(root, keys, envValue(value, booleans))
+	}
+
+	config := root.ToConfig()
+	config.booleans = booleans
+
+	return config
+}
+
+func containsEmptyKey(keys []string) bool {
+	for _, key := range keys {
+		if key == "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// envValue parses the integers and the booleans of the values, the numbers with a leading zero or a plus sign
+// (e.g. 0755, 007, +1) are kept as strings like the parser keeps the leading zero numbers
+func envValue(value string, booleans booleanSpellings) Value {
+	if !strings.HasPrefix(value, "+") && !hasLeadingZero(strings.TrimPrefix(value, "-")) {
+		if i, err := strconv.Atoi(value); err == nil {
+			return Int(i)
+		}
+	}
+
+	if b, ok := booleans.lookup(value); ok {
+		return Boolean(b)
+	}
+
+	return String(value)
+}
+
+// LoadWithEnvOverrides function parses the resource at the given path with the ParseResource function and overrides
+// its values with the environment variables with the given prefix, that are mapped to the paths with the given mapping
+// like the ParseEnv function does, e.g. APP_DB_PORT=5433 overrides db.port with the APP prefix
+func LoadWithEnvOverrides(path string, envPrefix string, mapping ...EnvKeyMapping) (*Config, error) {
+	config, err := ParseResource(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseEnv(envPrefix, mapping...).WithFallback(config), nil
+}
@@ -0,0 +1,95 @@
+package hocon
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseEnv(t *testing.T) {
+	t.Setenv("ENVTEST_DB_HOST", "example.com")
+	t.Setenv("ENVTEST_DB_PORT", "5433")
+	t.Setenv("ENVTEST_DEBUG", "true")
+	t.Setenv("ENVTEST_POOL__MAX_SIZE", "20")
+	t.Setenv("ENVTEST_DB", "ignored")
+	t.Setenv("ENVTESTING_OTHER", "1")
+
+	t.Run("map the underscores to the dots and parse the values", func(t *testing.T) {
+		got := ParseEnv("ENVTEST")
+		assertDeepEqual(t, got.root, Object{
+			"db":    Object{"host": String("example.com"), "port": Int(5433)},
+			"debug": Boolean(true),
+		})
+	})
+
+	t.Run("map the double underscores to the dots", func(t *testing.T) {
+		got := ParseEnv("ENVTEST_", EnvDoubleUnderscoreToDot)
+		assertDeepEqual(t, got.root, Object{
+			"db_host": String("example.com"),
+			"db_port": Int(5433),
+			"debug":   Boolean(true),
+			"pool":    Object{"max_size": Int(20)},
+			"db":      String("ignored"),
+		})
+	})
+
+	t.Run("keep the numbers with a leading zero or a plus sign as strings", func(t *testing.T) {
+		t.Setenv("ENVZEROS_NAME", "007")
+		t.Setenv("ENVZEROS_MODE", "0755")
+		t.Setenv("ENVZEROS_OFFSET", "-05")
+		t.Setenv("ENVZEROS_SHIFT", "+1")
+		t.Setenv("ENVZEROS_COUNT", "0")
+		t.Setenv("ENVZEROS_DELTA", "-5")
+
+		got := ParseEnv("ENVZEROS")
+		assertDeepEqual(t, got.root, Object{
+			"name":   String("007"),
+			"mode":   String("0755"),
+			"offset": String("-05"),
+			"shift":  String("+1"),
+			"count":  Int(0),
+			"delta":  Int(-5),
+		})
+	})
+}
+
+func TestParseEnvWithOptions(t *testing.T) {
+	t.Setenv("ENVOPTIONS_FEATURE", "enabled")
+	t.Setenv("ENVOPTIONS_DB__PORT", "5433")
+
+	t.Run("parse the values with the boolean spellings of the options", func(t *testing.T) {
+		got, err := ParseEnvWithOptions("ENVOPTIONS", EnvDoubleUnderscoreToDot, WithBooleanSpellings([]string{"enabled"}, nil))
+		assertNoError(t, err)
+		assertDeepEqual(t, got.root, Object{"feature": Boolean(true), "db": Object{"port": Int(5433)}})
+
+		got, err = ParseEnvWithOptions("ENVOPTIONS", EnvDoubleUnderscoreToDot)
+		assertNoError(t, err)
+		assertDeepEqual(t, got.root, Object{"feature": String("enabled"), "db": Object{"port": Int(5433)}})
+	})
+
+	t.Run("return an error if an option is invalid", func(t *testing.T) {
+		got, err := ParseEnvWithOptions("ENVOPTIONS", EnvUnderscoreToDot, WithBooleanSpellings([]string{""}, nil))
+		assertError(t, err, errors.New("boolean spelling cannot be empty"))
+		assertNil(t, got)
+	})
+}
+
+func TestLoadWithEnvOverrides(t *testing.T) {
+	t.Run("override the values of the resource with the environment variables", func(t *testing.T) {
+		t.Setenv("ENVOVERRIDE_CONNECTION_PORT", "6543")
+		t.Setenv("ENVOVERRIDE_POOL_MIN", "1")
+
+		got, err := LoadWithEnvOverrides("testdata/db.conf", "ENVOVERRIDE")
+		assertNoError(t, err)
+		assertDeepEqual(t, got.root, Object{
+			"connection": Object{"host": String("localhost"), "port": Int(6543)},
+			"pool":       Object{"size": Int(10), "min": Int(1)},
+			"name":       String("db"),
+		})
+	})
+
+	t.Run("return the error if the resource cannot be parsed", func(t *testing.T) {
+		got, err := LoadWithEnvOverrides("nonExistPath", "ENVOVERRIDE")
+		assertError(t, err, errors.New("could not parse resource: open nonExistPath: no such file or directory"))
+		assertNil(t, got)
+	})
+}
@@ -1,19 +1,97 @@
 package hocon
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrSubstitutionCycle is the error (wrapped with the paths of the cycle) returned if the substitutions refer to each other in a cycle
+var ErrSubstitutionCycle = errors.New("substitution cycle")
+
+// ErrValueNotFound is the error (wrapped with the path) returned by the getters that report the missing values as errors
+var ErrValueNotFound = errors.New("value not found")
+
+// ErrOutOfRange is the error (wrapped with the path and the range) returned by the getters that validate the range of the values
+var ErrOutOfRange = errors.New("value out of range")
+
+// ErrUnknownKeys is the error (wrapped with the path and the keys) returned if an object contains keys that are not allowed
+var ErrUnknownKeys = errors.New("unknown keys")
+
+// MissingSubstitution describes a required substitution that could not be resolved and where it is written
+type MissingSubstitution struct {
+	Path   string
+	File   string // file that the substitution is written in, empty for the parsed string itself
+	Line   int
+	Column int
+}
+
+// MissingSubstitutionsError is the error returned with the WithAllMissingSubstitutions option if any required substitution
+// cannot be resolved, it lists all of them in the order they are written instead of failing on the first one
+type MissingSubstitutionsError struct {
+	Missing []MissingSubstitution
+}
+
+func (m *MissingSubstitutionsError) Error() string {
+	missing := make([]string, len(m.Missing))
+
+	for i, substitution := range m.Missing {
+		location := fmt.Sprintf("%d:%d", substitution.Line, substitution.Column)
+		if substitution.File != "" {
+			location = substitution.File + ":" + location
+		}
+
+		missing[i] = fmt.Sprintf("${%s} at: %s", substitution.Path, location)
+	}
+
+	return "could not resolve the substitutions to a value: " + strings.Join(missing, ", ")
+}
 
 // ParseError represents an error occurred while parsing a resource or string to a hocon configuration
 type ParseError struct {
 	errType string
 	message string
+	file    string // path of the (included) file the error occurred in, empty for the parsed string itself
 	line    int
 	column  int
 }
 
 func (p *ParseError) Error() string {
+	if p.file != "" {
+		return fmt.Sprintf("%s at: %s:%d:%d, %s", p.errType, p.file, p.line, p.column, p.message)
+	}
+
 	return fmt.Sprintf("%s at: %d:%d, %s", p.errType, p.line, p.column, p.message)
 }
 
+// inFile sets the file of the error if it is a ParseError that is not already attributed to a (nested) included file,
+// the line and the column of the error are relative to that file
+func inFile(err error, file string) error {
+	var parseErr *ParseError
+	if errors.As(err, &parseErr) && parseErr.file == "" {
+		parseErr.file = file
+	}
+
+	return err
+}
+
+// shiftPosition moves the position of the ParseError from the part of the input that is parsed separately
+// to the whole input, given the line and the column where the part starts
+func shiftPosition(err error, line, column int) error {
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || parseErr.line == 0 {
+		return err
+	}
+
+	if parseErr.line == 1 {
+		parseErr.column += column - 1
+	}
+
+	parseErr.line += line - 1
+
+	return err
+}
+
 func parseError(errType, message string, line, column int) *ParseError {
 	return &ParseError{errType: errType, message: message, line: line, column: column}
 }
@@ -42,6 +120,29 @@ func invalidObjectError(message string, line, column int) *ParseError {
 	return parseError("invalid config object!", message, line, column)
 }
 
+func mismatchedBracketsError(opener bracket, found string, line, column int) *ParseError {
+	message := fmt.Sprintf("%q opened at: %d:%d is expected to be closed with %q but found %q at: %d:%d",
+		opener.token, opener.line, opener.column, opener.closer(), found, line, column)
+
+	return parseError("mismatched brackets!", message, opener.line, opener.column)
+}
+
+func conflictingTypesError(conflict *mergeConflict, existingSource, newSource string, line, column int) *ParseError {
+	message := fmt.Sprintf("cannot merge %s from %s with %s from %s at path: %s",
+		typeName(conflict.existing), existingSource, typeName(conflict.new), newSource, joinPath(conflict.path))
+
+	return parseError("conflicting types!", message, line, column)
+}
+
+func maxDepthError(maxDepth, line, column int) *ParseError {
+	return parseError("maximum depth exceeded!", fmt.Sprintf("the values cannot be nested deeper than %d levels", maxDepth), line, column)
+}
+
+func controlCharacterError(char rune, line, column int) *ParseError {
+	message := fmt.Sprintf("control character %U is not allowed, it must be escaped in the quoted strings", char)
+	return parseError("invalid character!", message, line, column)
+}
+
 func invalidKeyError(key string, line, column int) *ParseError {
 	return parseError("invalid key!", fmt.Sprintf("%q is a forbidden character in keys", key), line, column)
 }
@@ -50,6 +151,10 @@ func invalidValueError(message string, line, column int) *ParseError {
 	return parseError("invalid value!", message, line, column)
 }
 
+func invalidIncludeFragmentError(message string, line, column int) *ParseError {
+	return parseError("invalid include fragment!", message, line, column)
+}
+
 func unclosedMultiLineStringError() *ParseError {
 	return parseError("unclosed multi-line string!", "", 0, 0)
 }
@@ -69,3 +174,37 @@ func leadingCommaError(line, column int) *ParseError {
 func invalidConcatenationError() *ParseError {
 	return parseError("invalid concatenation!", "objects cannot be concatenated with other types", 0, 0)
 }
+
+const forbiddenCommentType = "forbidden comment!"
+
+func forbiddenCommentError(style string, line, column int) *ParseError {
+	return parseError(forbiddenCommentType, fmt.Sprintf("%q comments are not allowed", style), line, column)
+}
+
+func invalidJSONError(message string, line, column int) *ParseError {
+	return parseError("invalid JSON!", message, line, column)
+}
+
+func scannerError(message string, line, column int) *ParseError {
+	return parseError("scanner error!", message, line, column)
+}
+
+func internalError(message string, line, column int) *ParseError {
+	return parseError("internal error!", message, line, column)
+}
+
+func valueNotFoundError(path string) error {
+	return fmt.Errorf("%w at path: %s", ErrValueNotFound, path)
+}
+
+func outOfRangeError(path string, value, min, max interface{}) error {
+	return fmt.Errorf("%w at path: %s, %v is not in the range [%v, %v]", ErrOutOfRange, path, value, min, max)
+}
+
+func unknownKeysError(path string, keys []string, allowedKeys []string) error {
+	return fmt.Errorf("%w at path: %s, %q, the allowed keys are: %q", ErrUnknownKeys, path, keys, allowedKeys)
+}
+
+func substitutionCycleError(paths []string) error {
+	return fmt.Errorf("%w: %s", ErrSubstitutionCycle, strings.Join(paths, " -> "))
+}
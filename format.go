@@ -0,0 +1,363 @@
+package hocon
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Format function parses the given hocon string and re-renders it in the canonical style, e.g. to format the configuration
+// files in a pre-commit hook. The keys keep their order, the comments, the includes, the substitutions and the values
+// are kept as written, only the layout changes: one field per line separated with ": " (or " += ") without commas,
+// the objects on their own lines indented with two spaces, the arrays of scalars on a single line and at most one blank
+// line between the fields. Formatting the formatted output returns it unchanged. The parse errors are returned as they are
+func Format(input string) (string, error) {
+	parser := newParser(strings.NewReader(input), WithDryRunIncludes())
+	parser.options.unresolved = true
+
+	if _, err := parser.parse(); err != nil {
+		return "", err
+	}
+
+	tokens, err := Tokenize(strings.NewReader(input))
+	if err != nil {
+		return "", err
+	}
+
+	f := &formatter{source: input, tokens: tokens, lineStarts: []int{0}}
+	for i, r := range input {
+		if r == '\n' {
+			f.lineStarts = append(f.lineStarts, i+1)
+		}
+	}
+
+	f.format()
+
+	if len(f.lines) == 0 {
+		return "", nil
+	}
+
+	return strings.Join(f.lines, "\n") + "\n", nil
+}
+
+// formatter lays out the tokens of a valid HOCON source, the values are copied from the source as written
+type formatter struct {
+	source     string
+	lineStarts []int // offsets of the lines of the source
+	tokens     []Token
+	next       int      // index of the next token
+	line       int      // line that the last consumed token ends on
+	lines      []string // formatted output
+}
+
+func (f *formatter) format() {
+	f.items(0, "", f.field, func() bool {
+		token := f.tokens[f.next]
+		if token.Text != objectStartToken && token.Text != arrayStartToken {
+			return false
+		}
+
+		f.newLine(0)
+		f.value(0)
+
+		return true
+	})
+}
+
+// items formats the fields or the elements up to the given closing token, with the comments and the blank lines
+// between them. The root handles the braces or the brackets around the root value
+func (f *formatter) items(depth int, closing string, item func(depth int), root ...func() bool) {
+	first := true
+
+	for f.next < len(f.tokens) {
+		token := f.tokens[f.next]
+
+		switch {
+		case token.Kind == PunctuationKind && token.Text == closing:
+			return
+		case token.Kind == PunctuationKind && token.Text == commaToken:
+			f.take()
+			continue
+		case token.Kind == CommentKind && token.Line == f.line && len(f.lines) > 0: // a comment after a value on its line
+			f.lines[len(f.lines)-1] += " " + f.take().Text
+			continue
+		}
+
+		if !first && token.Line > f.line+1 {
+			f.lines = append(f.lines, "")
+		}
+
+		first = false
+
+		if token.Kind == CommentKind {
+			f.newLine(depth)
+			f.write(f.take().Text)
+		} else if len(root) == 0 || !root[0]() {
+			item(depth)
+		}
+	}
+}
+
+// field formats a key with its value or an include
+func (f *formatter) field(depth int) {
+	f.newLine(depth)
+
+	if token := f.tokens[f.next]; token.Kind == KeywordKind && token.Text == includeToken {
+		f.take()
+		f.write(includeToken + " ")
+
+		start := f.next
+		if f.take().Kind != StringKind || f.peekText() != "(" { // include "file.conf"
+			f.write(f.span(start, f.next))
+			return
+		}
+
+		for parentheses := 0; f.next < len(f.tokens); { // include required(file("file.conf"))
+			switch f.take().Text {
+			case "(":
+				parentheses++
+			case ")":
+				parentheses--
+			}
+
+			if parentheses == 0 {
+				break
+			}
+		}
+
+		f.write(f.span(start, f.next))
+
+		return
+	}
+
+	start := f.next
+	for f.next < len(f.tokens) && (f.next == start ||
+		!f.isSeparator(f.tokens[f.next]) && !f.isTerminator(f.tokens[f.next]) && f.tokens[f.next].Line == f.line) {
+		f.take()
+	}
+
+	f.write(f.span(start, f.next))
+
+	if f.next == len(f.tokens) || !f.isSeparator(f.tokens[f.next]) {
+		return
+	}
+
+	switch separator := f.tokens[f.next]; {
+	case separator.Text == objectStartToken:
+		f.write(" ")
+	case separator.Text == "+=":
+		f.take()
+		f.write(" += ")
+	default:
+		f.take()
+
+		if f.isStructured() && f.tokens[f.next].Text == objectStartToken {
+			f.write(" ")
+		} else {
+			f.write(": ")
+		}
+	}
+
+	f.value(depth)
+}
+
+// value formats an object, an array or copies a scalar or a concatenation as written
+func (f *formatter) value(depth int) {
+	if !f.isStructured() {
+		f.concatenation()
+		return
+	}
+
+	opening := f.take()
+	closing := objectEndToken
+	if opening.Text == arrayStartToken {
+		closing = arrayEndToken
+	}
+
+	if f.peekText() == closing {
+		f.take()
+		f.write(opening.Text + closing)
+
+		return
+	}
+
+	if closing == arrayEndToken && f.isInline(f.next-1) {
+		f.write(arrayStartToken)
+
+		for count := 0; f.peekText() != arrayEndToken; {
+			if f.peekText() == commaToken {
+				f.take()
+				continue
+			}
+
+			if count > 0 {
+				f.write(", ")
+			}
+
+			f.value(depth)
+			count++
+		}
+
+		f.take()
+		f.write(arrayEndToken)
+
+		return
+	}
+
+	f.write(opening.Text)
+
+	item := f.field
+	if closing == arrayEndToken {
+		item = func(depth int) {
+			f.newLine(depth)
+			f.value(depth)
+		}
+	}
+
+	f.items(depth+1, closing, item)
+	f.newLine(depth)
+	f.write(f.take().Text)
+}
+
+// concatenation copies the tokens of the value up to the end of the line, a separator or a comment as written
+func (f *formatter) concatenation() {
+	start := f.next
+
+	for f.next < len(f.tokens) {
+		token := f.tokens[f.next]
+		if f.next > start && (token.Line != f.line || f.isTerminator(token)) {
+			break
+		}
+
+		if f.isOpening(token) {
+			f.skipGroup()
+			continue
+		}
+
+		f.take()
+	}
+
+	f.write(f.span(start, f.next))
+}
+
+// isStructured reports whether the next value is an object or an array that is not concatenated with other values
+func (f *formatter) isStructured() bool {
+	if f.next == len(f.tokens) {
+		return false
+	}
+
+	if text := f.tokens[f.next].Text; f.tokens[f.next].Kind != PunctuationKind || text != objectStartToken && text != arrayStartToken {
+		return false
+	}
+
+	end := f.groupEnd(f.next)
+	if end+1 == len(f.tokens) {
+		return true
+	}
+
+	after := f.tokens[end+1]
+
+	return after.Line != f.endLine(f.tokens[end]) || f.isTerminator(after)
+}
+
+// isInline reports whether the array starting at the given token is laid out on a single line,
+// i.e. it contains no objects and no comments
+func (f *formatter) isInline(start int) bool {
+	for i := start; i <= f.groupEnd(start); i++ {
+		if f.tokens[i].Kind == CommentKind || f.tokens[i].Kind == PunctuationKind && f.tokens[i].Text == objectStartToken {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (f *formatter) isSeparator(token Token) bool {
+	return token.Kind == PunctuationKind &&
+		(token.Text == colonToken || token.Text == equalsToken || token.Text == "+=" || token.Text == objectStartToken)
+}
+
+func (f *formatter) isTerminator(token Token) bool {
+	return token.Kind == CommentKind || token.Kind == PunctuationKind &&
+		(token.Text == commaToken || token.Text == objectEndToken || token.Text == arrayEndToken)
+}
+
+func (f *formatter) isOpening(token Token) bool {
+	return token.Kind == SubstitutionStartKind ||
+		token.Kind == PunctuationKind && (token.Text == objectStartToken || token.Text == arrayStartToken)
+}
+
+// groupEnd returns the index of the token that closes the object, the array or the substitution opened at the given index
+func (f *formatter) groupEnd(start int) int {
+	depth := 0
+
+	for i := start; i < len(f.tokens); i++ {
+		token := f.tokens[i]
+
+		if f.isOpening(token) {
+			depth++
+		} else if token.Kind == PunctuationKind && (token.Text == objectEndToken || token.Text == arrayEndToken) {
+			depth--
+		}
+
+		if depth == 0 {
+			return i
+		}
+	}
+
+	return len(f.tokens) - 1
+}
+
+func (f *formatter) skipGroup() {
+	for end := f.groupEnd(f.next); f.next <= end; {
+		f.take()
+	}
+}
+
+func (f *formatter) take() Token {
+	token := f.tokens[f.next]
+	f.next++
+	f.line = f.endLine(token)
+
+	return token
+}
+
+func (f *formatter) peekText() string {
+	if f.next == len(f.tokens) {
+		return ""
+	}
+
+	return f.tokens[f.next].Text
+}
+
+func (f *formatter) endLine(token Token) int {
+	return token.Line + strings.Count(token.Text, "\n")
+}
+
+// span returns the source text from the start of the first token to the end of the last token before the given end index
+func (f *formatter) span(start, end int) string {
+	if start == end {
+		return ""
+	}
+
+	return f.source[f.offset(f.tokens[start]) : f.offset(f.tokens[end-1])+f.tokens[end-1].Length]
+}
+
+// offset converts the position of the token, whose column counts the characters, to the byte offset in the source
+func (f *formatter) offset(token Token) int {
+	offset := f.lineStarts[token.Line-1]
+
+	for column := 1; column < token.Column; column++ {
+		_, size := utf8.DecodeRuneInString(f.source[offset:])
+		offset += size
+	}
+
+	return offset
+}
+
+func (f *formatter) newLine(depth int) {
+	f.lines = append(f.lines, strings.Repeat("  ", depth))
+}
+
+func (f *formatter) write(text string) {
+	f.lines[len(f.lines)-1] += text
+}
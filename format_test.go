@@ -0,0 +1,58 @@
+package hocon
+
+import (
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	t.Run("render the input in the canonical style keeping the order, the comments and the values as written", func(t *testing.T) {
+		input := "# Header\n// second line\n\n\nb = 1\na { x = ${b} px, y: \"q:r\" } # trailing\nl = [1,\n ${b}]\nl += 3\n"
+		expected := "# Header\n// second line\n\nb: 1\na {\n  x: ${b} px\n  y: \"q:r\"\n} # trailing\nl: [1, ${b}]\nl += 3\n"
+
+		got, err := Format(input)
+		assertNoError(t, err)
+		assertEquals(t, got, expected)
+	})
+
+	t.Run("keep the includes and the comments between the fields", func(t *testing.T) {
+		input := "a = 1\n// comment\nb = 2, include \"testdata/a.conf\"\ninclude required(file(\"missing.conf\"))\nc: {}\nd = []"
+		expected := "a: 1\n// comment\nb: 2\ninclude \"testdata/a.conf\"\ninclude required(file(\"missing.conf\"))\nc {}\nd: []\n"
+
+		got, err := Format(input)
+		assertNoError(t, err)
+		assertEquals(t, got, expected)
+	})
+
+	t.Run("lay out the arrays with objects or comments one element per line", func(t *testing.T) {
+		got, err := Format("x = [ # numbers\n  1, 2 // two\n]\ny = [[1, 2], [3]]\n")
+		assertNoError(t, err)
+		assertEquals(t, got, "x: [ # numbers\n  1\n  2 // two\n]\ny: [[1, 2], [3]]\n")
+
+		got, err = Format("[1, {a: 2}]")
+		assertNoError(t, err)
+		assertEquals(t, got, "[\n  1\n  {\n    a: 2\n  }\n]\n")
+	})
+
+	t.Run("return the formatted input unchanged", func(t *testing.T) {
+		for _, input := range []string{
+			"# Header\nb = 1\nc = ${?HOME}\nc = ${?X}\ne = x \"y\"  z",
+			"[1, {a: 2}]",
+			"# only the header",
+			"{\"a\": 1, \"b\": [1, 2,\n3], \"c\": {\"d\": null}}",
+			"a.b.c = 1\nserver {\n  # inner\n\n\n  port = 80 # port\n}\nz: \"\"\"multi\nline\"\"\"",
+		} {
+			formatted, err := Format(input)
+			assertNoError(t, err)
+
+			got, err := Format(formatted)
+			assertNoError(t, err)
+			assertEquals(t, got, formatted)
+		}
+	})
+
+	t.Run("return the parse errors at their positions in the input", func(t *testing.T) {
+		got, err := Format("# Header\na = {")
+		assertError(t, err, invalidObjectError("parenthesis do not match", 2, 6))
+		assertEquals(t, got, "")
+	})
+}
@@ -0,0 +1,163 @@
+package hocon
+
+import (
+	"io"
+	"strings"
+	"text/scanner"
+	"unicode"
+)
+
+// lexer wraps the text/scanner with the lexing shared by the parser and the Tokenize function
+type lexer struct {
+	*scanner.Scanner
+	onError func(message string, position scanner.Position)
+
+	octalError    string // the octal literal error of the token being scanned, reported once the token is complete
+	octalPosition scanner.Position
+}
+
+// newLexer creates the lexer of the given source, the errors of the scanner are passed to onError if it is not nil
+func newLexer(src io.Reader, onError func(message string, position scanner.Position)) *lexer {
+	l := &lexer{Scanner: new(scanner.Scanner), onError: onError}
+	l.Init(src)
+	l.Whitespace ^= 1<<'\t' | 1<<' ' // do not skip tabs and spaces
+	l.Error = l.scannerError
+	leadingHyphen := false
+	l.IsIdentRune = func(ch rune, i int) bool {
+		if i == 0 {
+			leadingHyphen = ch == '-'
+		} else if i == 1 && leadingHyphen && unicode.IsDigit(ch) {
+			return false // a negative number, not an identifier
+		}
+
+		return ch == '_' || ch == '-' || unicode.IsLetter(ch) || unicode.IsDigit(ch) && i > 0
+	}
+
+	return l
+}
+
+// Scan scans the next token and reports the octal literal error of it unless the token has a leading zero,
+// e.g. 08540 is not an octal literal but a string
+func (l *lexer) Scan() rune {
+	tok := l.Scanner.Scan()
+
+	if l.octalError != "" {
+		if !hasLeadingZero(l.TokenText()) {
+			l.report(l.octalError, l.octalPosition)
+		}
+
+		l.octalError = ""
+	}
+
+	return tok
+}
+
+func (l *lexer) scannerError(s *scanner.Scanner, message string) {
+	position := s.Position
+	if !position.IsValid() {
+		position = s.Pos()
+	}
+
+	if strings.HasSuffix(message, "in octal literal") { // the token text is not available before the token is complete
+		l.octalError, l.octalPosition = message, position
+		return
+	}
+
+	l.report(message, position)
+}
+
+func (l *lexer) report(message string, position scanner.Position) {
+	if l.onError != nil {
+		l.onError(message, position)
+	}
+}
+
+// scanSkippingSpaces scans the next token after the tabs and the spaces, which are scanned as separate tokens,
+// and returns it with the skipped whitespaces as they are written
+func (l *lexer) scanSkippingSpaces() (rune, string) {
+	tok := l.Scan()
+	if tok != '\t' && tok != ' ' {
+		return tok, ""
+	}
+
+	var builder strings.Builder
+
+	for tok == '\t' || tok == ' ' {
+		builder.WriteRune(tok)
+		tok = l.Scan()
+	}
+
+	return tok, builder.String()
+}
+
+// restOfLine consumes the rest of the current line without scanning it as tokens, e.g. the text of a # comment
+func (l *lexer) restOfLine() string {
+	var builder strings.Builder
+
+	for next := l.Peek(); next != '\n' && next != scanner.EOF; next = l.Peek() {
+		builder.WriteRune(l.Next())
+	}
+
+	return builder.String()
+}
+
+// restOfMultiLineString consumes the multi-line string whose first two quotes are scanned as an empty string,
+// returns its content and whether it is closed. The quotes right before the closing ones belong to the content,
+// e.g. """a""""" is a""
+func (l *lexer) restOfMultiLineString() (string, bool) {
+	l.Next() // the third opening quote
+
+	var builder strings.Builder
+
+	adjacentQuoteCount := 0
+
+	for next := l.Next(); next != scanner.EOF; next = l.Next() {
+		builder.WriteRune(next)
+
+		if next == '"' {
+			adjacentQuoteCount++
+		} else {
+			adjacentQuoteCount = 0
+		}
+
+		if adjacentQuoteCount >= 3 && l.Peek() != '"' {
+			return builder.String()[:builder.Len()-3], true
+		}
+	}
+
+	return builder.String(), false
+}
+
+type unitKind int
+
+const (
+	noUnit unitKind = iota
+	durationUnit
+	sizeUnit
+)
+
+// numberUnit classifies the scanned token that follows a number on the given line after the given whitespaces,
+// the durations (e.g. 10s, 10 seconds) and the size units (e.g. 512MB, 512 megabytes) are the units of the number.
+// The single letter size units (e.g. 1 b) must be written without a whitespace as they cannot be told apart from
+// the key of the next field, the other identifiers are not units
+func (l *lexer) numberUnit(tok rune, line int, whitespaces string) unitKind {
+	if tok != scanner.Ident || l.Position.Line != line {
+		return noUnit
+	}
+
+	unit := l.TokenText()
+	if _, ok := durationUnits[unit]; ok {
+		return durationUnit
+	}
+
+	if _, ok := byteSizeUnits[unit]; !ok || whitespaces != "" && len(unit) == 1 {
+		return noUnit
+	}
+
+	switch l.Peek() {
+	case ':', '=', '{', '.', '+': // the identifier is the key of the next field
+		return noUnit
+	}
+
+	return sizeUnit
+}
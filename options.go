@@ -0,0 +1,255 @@
+package hocon
+
+import "io"
+
+// Option configures the parsing behavior, it can be passed to the ParseString, ParseStringWithBase and ParseResource functions
+type Option func(*options)
+
+type options struct {
+	includePredicate        func(IncludeToken) bool
+	includeResolver         func(IncludeToken) (io.ReadCloser, error)
+	additiveIncludes        bool
+	arrayMerge              ArrayMergePolicy
+	dryRunIncludes          bool
+	nestedSubstitutions     bool
+	allMissingSubstitutions bool
+	commentStyles           CommentStyle
+	rawText                 bool
+	syntax                  Syntax
+	strictScanner           bool
+	maxIncludeSize          int64
+	ignoreTrailing          bool
+	strictIncludeEnv        bool
+	largeIntegers           LargeIntegerMode
+	lazyResolution          bool
+	source                  func(path string) (Value, bool)
+	sourceBeforeEnv         bool
+	strictMerge             bool
+	unresolved              bool             // skip the resolution of the substitutions, set by the Format function that only validates the input
+	booleans                booleanSpellings // lower-cased boolean spellings of the WithBooleanSpellings option
+	err                     error            // the first invalid option, returned by the parsing functions
+	envFallback             bool
+	maxDepth                int
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{commentStyles: AllComments, envFallback: true}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// WithIncludePredicate option registers a predicate that decides whether an include directive is processed,
+// includes that the predicate returns false for are skipped as if they resolved to an empty object.
+// By default every include is processed
+func WithIncludePredicate(predicate func(IncludeToken) bool) Option {
+	return func(o *options) { o.includePredicate = predicate }
+}
+
+// WithAdditiveIncludes option makes the included objects fill in only the keys that are not already set
+// in the including object, like the WithFallback method does for configs. By default included keys override the existing ones
+func WithAdditiveIncludes() Option {
+	return func(o *options) { o.additiveIncludes = true }
+}
+
+// WithDryRunIncludes option validates the include directives without loading them, e.g. to lint a configuration in CI
+// where the included files are not available. Every include, including the required ones, resolves to an empty object
+// and is recorded to be inspected with the Includes method of the parsed Config. The includes skipped by the predicate
+// of the WithIncludePredicate option are not recorded
+func WithDryRunIncludes() Option {
+	return func(o *options) { o.dryRunIncludes = true }
+}
+
+// WithNestedSubstitutions option enables the non-standard substitutions whose paths contain other substitutions,
+// e.g. ${profiles.${ENV}.url}, the nested substitutions are resolved first and their values are inserted into the path.
+// Without the option a nested substitution is reported as an invalid substitution
+func WithNestedSubstitutions() Option {
+	return func(o *options) { o.nestedSubstitutions = true }
+}
+
+// WithAllMissingSubstitutions option makes the parsing report all the required substitutions that cannot be resolved
+// together with their positions, as a MissingSubstitutionsError, e.g. to fix all the missing environment variables at once
+// instead of one per run. By default the parsing fails on the first one. It has no effect with the WithLazyResolution option
+func WithAllMissingSubstitutions() Option {
+	return func(o *options) { o.allMissingSubstitutions = true }
+}
+
+// ArrayMergePolicy selects how an array of an included file is combined with an array of the including file
+// at the same path, the policy applies to the arrays nested in the merged objects as well
+type ArrayMergePolicy int
+
+// ArrayMergePolicy constants
+const (
+	ReplaceArrays ArrayMergePolicy = iota // the later array replaces the earlier one
+	AppendArrays                          // the included array is appended to the array of the including file
+	PrependArrays                         // the included array is prepended to the array of the including file
+)
+
+// WithIncludeArrayMerge option sets how the arrays of the included files are combined with the arrays that the including file
+// sets at the same paths, before or after the include, e.g. to extend the default lists of a library instead of replacing them.
+// The objects are merged and the other values override each other as usual, by default the arrays are replaced as well
+func WithIncludeArrayMerge(policy ArrayMergePolicy) Option {
+	return func(o *options) { o.arrayMerge = policy }
+}
+
+// CommentStyle is a set of the comment styles accepted while parsing
+type CommentStyle int
+
+// CommentStyle constants
+const (
+	HashComments  CommentStyle = 1 << iota // comments starting with #
+	SlashComments                          // comments starting with //
+	AllComments   = HashComments | SlashComments
+)
+
+// WithCommentStyles option restricts the accepted comment styles, a comment in any other style
+// is reported as a ParseError instead of being stripped. By default both styles are accepted
+func WithCommentStyles(styles CommentStyle) Option {
+	return func(o *options) { o.commentStyles = styles }
+}
+
+// WithRawText option records the source text of the values as written, before the substitutions are resolved
+// and the strings are unquoted, to be retrieved with the Config.RawText method. It is disabled by default
+// as it keeps the parsed input in memory
+func WithRawText() Option {
+	return func(o *options) { o.rawText = true }
+}
+
+// Syntax selects how strictly the input is parsed
+type Syntax int
+
+// Syntax constants
+const (
+	AutoSyntax  Syntax = iota // strict JSON for the resources with the .json extension, HOCON otherwise
+	HOCONSyntax               // full HOCON, JSON is accepted as a subset of it
+	JSONSyntax                // strict JSON, the HOCON-only syntax is rejected
+)
+
+// WithSyntax option overrides the syntax of the input, by default the ParseResource function selects it by the extension
+// of the resource and the other functions parse HOCON
+func WithSyntax(syntax Syntax) Option {
+	return func(o *options) { o.syntax = syntax }
+}
+
+// WithStrictScanner option fails the parsing with a ParseError if the scanner reports an error while tokenizing
+// the input (e.g. an invalid digit in an octal literal), by default these errors are kept as the warnings of the Config
+func WithStrictScanner() Option {
+	return func(o *options) { o.strictScanner = true }
+}
+
+// WithIncludeResolver option registers a resolver that opens the included resources instead of the file system,
+// e.g. to include from a map or an fs.FS. The resolver receives the include path resolved against the directory of
+// the including resource (e.g. "conf/db.conf" for include "db.conf" in the resource "conf/app.conf", the paths of the
+// root document are resolved against its base directory), and the include is skipped if it is not required and
+// the resolver returns an error wrapping os.ErrNotExist.
+// The parser closes the returned resource, a nil resolver falls back to the file system
+func WithIncludeResolver(resolver func(IncludeToken) (io.ReadCloser, error)) Option {
+	return func(o *options) { o.includeResolver = resolver }
+}
+
+// WithMaxIncludeSize option limits the size of each included resource to the given number of bytes,
+// the parsing fails if an included resource is larger. By default the size is unlimited
+func WithMaxIncludeSize(bytes int64) Option {
+	return func(o *options) { o.maxIncludeSize = bytes }
+}
+
+// WithLenientTrailingContent option ignores anything after the closing bracket of the root object or array,
+// e.g. to parse the first of the concatenated documents. Without this option only the whitespaces and the comments
+// are allowed after the root, and any other trailing token fails the parsing
+func WithLenientTrailingContent() Option {
+	return func(o *options) { o.ignoreTrailing = true }
+}
+
+// WithStrictIncludeVariables option fails the parsing if an environment variable referenced in an include path
+// (e.g. include "config-${ENV}.conf") is not set, by default the unset variables expand to empty string
+func WithStrictIncludeVariables() Option {
+	return func(o *options) { o.strictIncludeEnv = true }
+}
+
+// LargeIntegerMode selects how the integers that do not fit in int are parsed
+type LargeIntegerMode int
+
+// LargeIntegerMode constants
+const (
+	LargeIntegersError    LargeIntegerMode = iota // fail the parsing with the overflow error
+	LargeIntegersAsFloat                          // keep the value as a Float64, precision is lost beyond 2^53
+	LargeIntegersAsString                         // keep the digits as a String, e.g. for the large numeric IDs
+)
+
+// WithLargeIntegers option selects how the integers that overflow int are parsed, by default the parsing fails.
+// The floats are read with the GetFloat64 method and the strings with the GetString method, the GetInt method
+// panics for both as the value cannot be represented as an int
+func WithLargeIntegers(mode LargeIntegerMode) Option {
+	return func(o *options) { o.largeIntegers = mode }
+}
+
+// WithLazyResolution option keeps the substitutions unresolved while parsing and resolves them on the first access
+// instead, e.g. for the large configurations most of whose substitutions are never read. The resolved values are cached
+// in the tree, and an unresolvable substitution makes the getter that reaches it panic with the resolution error
+// (ErrSubstitutionCycle for the cycles) instead of failing the parsing, use the Resolve method to report it up front
+func WithLazyResolution() Option {
+	return func(o *options) { o.lazyResolution = true }
+}
+
+// WithSubstitutionResolver option registers a source (e.g. a secrets manager) that the substitutions which are not found
+// in the configuration are resolved against, after the environment variables unless the WithResolverBeforeEnv option
+// is given. The resolver receives the path of the substitution and the substitutions of the value it returns are
+// resolved like the ones in the configuration. A nil resolver is ignored
+func WithSubstitutionResolver(resolver func(path string) (Value, bool)) Option {
+	return func(o *options) { o.source = resolver }
+}
+
+// WithResolverBeforeEnv option consults the resolver of the WithSubstitutionResolver option before the environment variables
+func WithResolverBeforeEnv() Option {
+	return func(o *options) { o.sourceBeforeEnv = true }
+}
+
+// WithStrictMerge option fails the parsing with a ParseError if an object and a non-object value are merged at the same
+// path, e.g. an included file sets a key to a number and the including file sets it to an object. The error names
+// the path and the files that the two values come from. By default the later value silently replaces the earlier one
+func WithStrictMerge() Option {
+	return func(o *options) { o.strictMerge = true }
+}
+
+// WithBooleanSpellings option accepts additional spellings for the true and false values (e.g. enabled/disabled) while
+// parsing, like the deprecated RegisterBooleanSpellings function but without affecting the other parses in the process.
+// The parsed Config reads the boolean strings with the same spellings, and the spellings registered with the
+// RegisterBooleanSpellings function are ignored. The spellings are matched case-insensitively, the parsing fails
+// if a spelling is empty or would map to both true and false
+func WithBooleanSpellings(trueSpellings, falseSpellings []string) Option {
+	return func(o *options) {
+		if o.booleans == nil {
+			o.booleans = booleanSpellings{}
+		}
+
+		if err := addBooleanSpellings(o.booleans, trueSpellings, falseSpellings); err != nil && o.err == nil {
+			o.err = err
+		}
+	}
+}
+
+// WithEnvFallback option selects whether the substitutions that are not found in the configuration are resolved against
+// the environment variables, it is enabled by default. Disabling it keeps the parsing independent of the environment,
+// e.g. in tests
+func WithEnvFallback(enabled bool) Option {
+	return func(o *options) { o.envFallback = enabled }
+}
+
+// WithMaxDepth option limits the nesting depth of the objects and the arrays (the root is at depth 1, including the objects
+// of the path expressions like a.b.c), the parsing fails with a ParseError if the input is nested deeper,
+// e.g. to reject the malicious inputs. By default the depth is unlimited
+func WithMaxDepth(depth int) Option {
+	return func(o *options) { o.maxDepth = depth }
+}
+
+// WithStrictMode option enables all the strict checks: the WithStrictScanner, WithStrictIncludeVariables
+// and WithStrictMerge options
+func WithStrictMode() Option {
+	return func(o *options) {
+		o.strictScanner = true
+		o.strictIncludeEnv = true
+		o.strictMerge = true
+	}
+}
@@ -1,11 +1,15 @@
 package hocon
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"os"
 	"path"
+	"sort"
 	"strconv"
 	"strings"
 	"text/scanner"
@@ -26,6 +30,7 @@ const (
 	commentToken     = "#"
 )
 
+// forbiddenCharacters are the characters that cannot appear in the unquoted keys and strings, it is never modified
 var forbiddenCharacters = map[string]bool{
 	"$": true, `"`: true, objectStartToken: true, objectEndToken: true, arrayStartToken: true, arrayEndToken: true,
 	colonToken: true, equalsToken: true, commaToken: true, "+": true, commentToken: true, "`": true, "^": true, "?": true,
@@ -33,57 +38,293 @@ var forbiddenCharacters = map[string]bool{
 }
 
 type parser struct {
-	scanner                 *scanner.Scanner
+	scanner                 *lexer
 	currentRune             rune
 	lastConsumedWhitespaces string // used in concatenation not to lose whitespaces between values
 	filepath                string
+	baseDir                 string // directory that the relative include paths are resolved against
+	options                 *options
+	path                    []string // keys of the value being extracted, relative to the root of the parsed configuration
+	lastValueQuoted         bool     // whether the last extracted value was a quoted string
+	metadata                *metadata
+	source                  *bytes.Buffer     // consumed input, kept only if the raw text of the values is recorded
+	input                   io.Reader         // reader that fills the source, drained to keep the trailing content as well
+	spans                   map[string]span   // offsets of the values in the source by their paths, excluding the included values
+	lastTokenEnd            int               // offset right after the previous token
+	err                     error             // the error detected where it cannot be returned (e.g. while advancing), reported at the end
+	header                  []string          // comment lines before the first token of the document
+	origins                 map[string]string // files that the values come from keyed by their paths, recorded only with the WithStrictMerge option
+	includedOrigins         map[string]string // origins of the values of the last included resource
+	depth                   int               // nesting depth of the object or the array being extracted
+	root                    Object            // root object being extracted, shared with the parsers of the included files
+	includedArrays          map[string]bool   // paths of the arrays set by the includes, recorded only with the WithIncludeArrayMerge option
+	stream                  bool              // whether the root value can be followed by the next document of a stream
+	documentEnd             int               // offset of the next document of the stream, 0 if the input is consumed
 }
 
-func newParser(src io.Reader) *parser {
-	s := newScanner(src)
-	currWd := "."
+// metadata stores the information collected about the values while parsing, keyed by their paths
+type metadata struct {
+	quoted    map[string]bool
+	raw       map[string]string          // source text of the values as written, recorded only with the WithRawText option
+	warnings  []string                   // problems that do not fail the parsing
+	includes  []IncludeToken             // includes that are not loaded, recorded only with the WithDryRunIncludes option
+	included  int                        // number of the included files that are parsed
+	positions map[*Substitution]position // positions of the substitutions for the errors found while resolving them
+}
 
-	return &parser{scanner: s, filepath: currWd}
+func newMetadata() *metadata {
+	return &metadata{quoted: map[string]bool{}, raw: map[string]string{}, positions: map[*Substitution]position{}}
 }
 
-func newFileParser(src *os.File) *parser {
-	s := newScanner(src)
+func newParser(src io.Reader, opts ...Option) *parser {
+	currWd := "."
+	return newParserWithBase(src, currWd, opts...)
+}
 
-	return &parser{scanner: s, filepath: src.Name()}
+func newParserWithBase(src io.Reader, baseDir string, opts ...Option) *parser {
+	return newParserWithOptions(src, "", baseDir, newOptions(opts))
 }
 
-func newScanner(src io.Reader) *scanner.Scanner {
-	s := new(scanner.Scanner)
-	s.Init(src)
-	s.Whitespace ^= 1<<'\t' | 1<<' '            // do not skip tabs and spaces
-	s.Error = func(*scanner.Scanner, string) {} // do not print errors to stderr
-	s.IsIdentRune = func(ch rune, i int) bool {
-		return ch == '_' || ch == '-' || unicode.IsLetter(ch) || unicode.IsDigit(ch) && i > 0
+func newParserWithOptions(src io.Reader, filepath, baseDir string, options *options) *parser {
+	p := &parser{filepath: filepath, baseDir: baseDir, options: options, metadata: newMetadata(), origins: map[string]string{},
+		includedArrays: map[string]bool{}}
+	src = &controlCharacterReader{reader: src, parser: p, line: 1}
+
+	if options.rawText {
+		p.source = &bytes.Buffer{}
+		src = io.TeeReader(src, p.source) // keep the consumed input to slice the raw text of the values from it
+		p.input = src
+		p.spans = map[string]span{}
+	}
+
+	p.scanner = newLexer(src, p.reportScannerError)
+
+	if options.commentStyles&SlashComments == 0 {
+		p.scanner.Mode &^= scanner.SkipComments // return the comments as tokens to report them
 	}
 
-	return s
+	return p
 }
 
 // ParseString function parses the given hocon string, creates the configuration tree and
 // returns a pointer to the Config, returns a ParseError if any error occurs while parsing
-func ParseString(input string) (*Config, error) {
-	parser := newParser(strings.NewReader(input))
+func ParseString(input string, opts ...Option) (*Config, error) {
+	return parseString(input, nil, opts)
+}
+
+// parseString parses the given hocon string like ParseString, the statistics of the parsing are collected
+// into the given stats if it is not nil
+func parseString(input string, stats *ParseStats, opts []Option) (*Config, error) {
+	start := time.Now()
+
+	parser := newParser(strings.NewReader(input), opts...)
+	if err := parser.validateSyntax([]byte(input), ""); err != nil {
+		return nil, err
+	}
+
+	config, err := parser.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	if stats != nil {
+		stats.collect(config, parser.metadata.included, time.Since(start))
+	}
+
+	return config, nil
+}
+
+// ParseStringOrDefault function parses the given hocon string like ParseString and applies the given defaults as
+// the fallback of the parsed configuration (e.g. the defaults embedded in the binary, like a reference.conf),
+// the result is a copy of the defaults if the input is blank. The defaults are not modified
+func ParseStringOrDefault(input string, defaults *Config, opts ...Option) (*Config, error) {
+	if defaults == nil {
+		return ParseString(input, opts...)
+	}
+
+	if strings.TrimSpace(input) == "" {
+		return defaults.copy(), nil
+	}
+
+	config, err := ParseString(input, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return config.WithFallback(defaults), nil
+}
+
+// ParseStringWithBase function parses the given hocon string like ParseString, but resolves the relative
+// include paths against the given baseDir instead of the current working directory, absolute include paths are unaffected
+func ParseStringWithBase(input string, baseDir string, opts ...Option) (*Config, error) {
+	parser := newParserWithBase(strings.NewReader(input), baseDir, opts...)
+	if err := parser.validateSyntax([]byte(input), ""); err != nil {
+		return nil, err
+	}
+
+	return parser.parse()
+}
+
+// ParseReader function reads the hocon input from the given reader until EOF and parses it like ParseString,
+// returns the error if the input cannot be read or parsed
+func ParseReader(r io.Reader, opts ...Option) (*Config, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("could not read the input: %w", err)
+	}
+
+	parser := newParser(bytes.NewReader(data), opts...)
+	if err := parser.validateSyntax(data, ""); err != nil {
+		return nil, err
+	}
+
 	return parser.parse()
 }
 
 // ParseResource parses the resource at the given path, creates the configuration tree and
-// returns a pointer to the Config, returns the error if any error occurs while parsing
-func ParseResource(path string) (*Config, error) {
-	file, err := os.Open(path)
+// returns a pointer to the Config, returns the error if any error occurs while parsing, the parse errors report the path.
+// Resources with the .json extension are parsed as strict JSON unless the syntax is set with the WithSyntax option
+func ParseResource(resourcePath string, opts ...Option) (*Config, error) {
+	data, err := os.ReadFile(resourcePath)
 	if err != nil {
 		return nil, fmt.Errorf("could not parse resource: %w", err)
 	}
 
-	return newFileParser(file).parse()
+	return parseNamed(data, resourcePath, opts)
 }
 
-func (p *parser) parse() (*Config, error) {
-	p.advance()
+// ParseFile function parses the given open file like ParseResource, the name of the file selects the syntax,
+// the relative include paths are resolved against its directory and the parse errors report it.
+// The file is read from its current offset and it is not closed
+func ParseFile(f *os.File, opts ...Option) (*Config, error) {
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse resource: %w", err)
+	}
+
+	return parseNamed(data, f.Name(), opts)
+}
+
+// parseNamed parses the content of the named resource or file, the name selects the syntax, the relative include paths
+// are resolved against its directory and the parse errors report it
+func parseNamed(data []byte, name string, opts []Option) (*Config, error) {
+	parser := newParserWithOptions(bytes.NewReader(data), name, path.Dir(name), newOptions(opts))
+	if err := parser.validateSyntax(data, name); err != nil {
+		return nil, inFile(err, name)
+	}
+
+	config, err := parser.parse()
+	if err != nil {
+		return nil, inFile(err, name)
+	}
+
+	return config, nil
+}
+
+// ParseStream function reads the input from the given reader until EOF and parses it as a stream of documents,
+// e.g. the objects written one per line, each document is parsed like ParseString and resolves its own substitutions.
+// A document ends where its root object or array is closed, so all documents but the last one must be enclosed in
+// braces or brackets. The error of a malformed document reports its index and the position in the whole input
+func ParseStream(r io.Reader, opts ...Option) ([]*Config, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("could not read the input: %w", err)
+	}
+
+	var configs []*Config
+
+	// skip the whitespaces between the documents in place, the rest of the input is not copied for every document
+	for start := skipSpaces(data, 0); start < len(data); start = skipSpaces(data, start) {
+		parser := newParser(bytes.NewReader(data[start:]), opts...)
+		parser.stream = true
+
+		config, err := parser.parse()
+
+		end := len(data)
+		if parser.documentEnd > 0 {
+			end = start + parser.documentEnd
+		}
+
+		if err == nil {
+			err = parser.validateSyntax(data[start:end], "")
+		}
+
+		if err != nil {
+			line, column := lineAndColumn(data, start)
+			return nil, fmt.Errorf("could not parse the document at index: %d, %w", len(configs), shiftPosition(err, line, column))
+		}
+
+		configs = append(configs, config)
+		start = end
+	}
+
+	return configs, nil
+}
+
+// skipSpaces returns the offset of the first non-whitespace character of the data at or after the given offset
+func skipSpaces(data []byte, offset int) int {
+	return len(data) - len(bytes.TrimLeftFunc(data[offset:], unicode.IsSpace))
+}
+
+// validateSyntax rejects the input with the HOCON-only syntax (unquoted strings, comments, substitutions etc.)
+// if it is parsed as strict JSON, either explicitly or by the .json extension of the given resource name
+func (p *parser) validateSyntax(data []byte, name string) error {
+	syntax := p.options.syntax
+	if syntax == AutoSyntax && strings.EqualFold(path.Ext(name), ".json") {
+		syntax = JSONSyntax
+	}
+
+	if syntax != JSONSyntax {
+		return nil
+	}
+
+	var value interface{}
+
+	var syntaxError *json.SyntaxError
+	if err := json.Unmarshal(data, &value); errors.As(err, &syntaxError) {
+		line, column := lineAndColumn(data, int(syntaxError.Offset)-1) // the offset is right after the invalid character
+		return invalidJSONError(syntaxError.Error(), line, column)
+	} else if err != nil {
+		return invalidJSONError(err.Error(), 0, 0)
+	}
+
+	return nil
+}
+
+// lineAndColumn converts the byte offset in the given data to the 1-based line and column
+func lineAndColumn(data []byte, offset int) (int, int) {
+	if offset > len(data) {
+		offset = len(data)
+	} else if offset < 0 {
+		offset = 0
+	}
+
+	line := bytes.Count(data[:offset], []byte("\n")) + 1
+	column := offset - bytes.LastIndexByte(data[:offset], '\n')
+
+	return line, column
+}
+
+func (p *parser) parse() (config *Config, err error) {
+	if p.options.err != nil {
+		return nil, p.options.err
+	}
+
+	defer func() {
+		if p.err != nil {
+			config, err = nil, p.err
+		}
+	}()
+
+	defer func() {
+		if r := recover(); r != nil { // do not let a malformed input crash the caller
+			config, err = nil, internalError(fmt.Sprint(r), p.scanner.Line, p.scanner.Column)
+		}
+	}()
+
+	p.extractHeader()
+
+	var root Value
 
 	if p.scanner.TokenText() == arrayStartToken {
 		array, err := p.extractArray()
@@ -91,137 +332,694 @@ func (p *parser) parse() (*Config, error) {
 			return nil, err
 		}
 
-		return &Config{root: array}, nil
+		if err := p.checkTrailingContent(invalidArrayError); err != nil {
+			return nil, err
+		}
+
+		root = array
+	} else {
+		object, err := p.extractObject()
+		if err != nil {
+			return nil, err
+		}
+
+		if err := p.checkTrailingContent(invalidObjectError); err != nil {
+			return nil, err
+		}
+
+		root = object
+	}
+
+	if p.options.unresolved {
+		return &Config{root: root, header: p.header, booleans: p.options.booleans}, nil
+	}
+
+	return p.resolve(root)
+}
+
+// extractHeader advances to the first token of the document collecting the comment lines before it as they are written
+func (p *parser) extractHeader() {
+	skipComments := p.scanner.Mode & scanner.SkipComments
+	p.scanner.Mode &^= scanner.SkipComments // return the slash comments as tokens to collect them
+
+	defer func() { p.scanner.Mode |= skipComments }()
+
+	for p.advance(); ; p.advance() {
+		switch {
+		case p.currentRune == scanner.Comment:
+			p.header = append(p.header, p.scanner.TokenText())
+		case p.scanner.TokenText() == commentToken:
+			if p.options.commentStyles&HashComments == 0 {
+				p.reportForbiddenComment(commentToken)
+			}
+
+			p.header = append(p.header, commentToken+p.scanner.restOfLine())
+		default:
+			return
+		}
+	}
+}
+
+// resolve resolves the substitutions of the parsed root and creates the Config, the substitutions of an array root
+// are resolved against the array itself, e.g. ${0} refers to its first element
+func (p *parser) resolve(root Value) (*Config, error) {
+	resolver := newResolver(root)
+	resolver.positions = p.metadata.positions
+	resolver.source, resolver.sourceBeforeEnv = p.options.source, p.options.sourceBeforeEnv
+	resolver.noEnv = !p.options.envFallback
+
+	if p.options.lazyResolution {
+		config := &Config{root: root, report: resolver.report, lazy: &lazyResolution{resolver: resolver},
+			quoted: p.metadata.quoted, raw: p.metadata.raw, warnings: p.metadata.warnings, header: p.header, includes: p.metadata.includes, source: p.sourceSpans(),
+			booleans: p.options.booleans}
+
+		return config, nil
+	}
+
+	if p.options.allMissingSubstitutions {
+		resolver.collected, resolver.undefined = map[*Substitution]bool{}, map[string]bool{}
+	}
+
+	if err := resolver.missingSubstitutionsError(resolver.resolve()); err != nil {
+		return nil, err
+	}
+
+	if array, ok := root.(Array); ok {
+		root = withoutAbsentElements(array)
+	}
+
+	config := &Config{root: root, quoted: p.metadata.quoted, raw: p.metadata.raw, warnings: p.metadata.warnings, header: p.header,
+		includes: p.metadata.includes, source: p.sourceSpans(), booleans: p.options.booleans}
+	if !resolver.report.isEmpty() {
+		config.report = resolver.report.sorted()
+	}
+
+	return config, nil
+}
+
+// checkTrailingContent returns an error created with the given function if there is a token other than a comment
+// after the root value, the trailing content is ignored with the WithLenientTrailingContent option
+func (p *parser) checkTrailingContent(newError func(message string, line, column int) *ParseError) error {
+	if p.stream {
+		p.skipComments()
+
+		if p.scanner.TokenText() == "" {
+			return nil
+		}
+
+		// the next document starts here, unless the root consumed nothing (e.g. a stray closing brace)
+		if p.documentEnd = p.scanner.Position.Offset; p.documentEnd > 0 {
+			return nil
+		}
+	}
+
+	if p.options.ignoreTrailing {
+		return nil
+	}
+
+	p.skipComments()
+
+	if token := p.scanner.TokenText(); token != "" {
+		return newError("invalid token "+token, p.scanner.Line, p.scanner.Column)
+	}
+
+	return nil
+}
+
+// extractDigitLeadingKey joins the unquoted key that starts with a digit (e.g. 3d) which is scanned as a number
+// followed by an identifier, keywords (include, true, null) can be used as keys by quoting them
+func (p *parser) extractDigitLeadingKey(key string) string {
+	if (p.currentRune == scanner.Int || p.currentRune == scanner.Float) && unicode.IsLetter(p.scanner.Peek()) {
+		p.advance()
+		key += p.scanner.TokenText()
+	}
+
+	return key
+}
+
+func (p *parser) advance() {
+	p.lastTokenEnd = p.scanner.Pos().Offset
+	p.currentRune, p.lastConsumedWhitespaces = p.scanner.scanSkippingSpaces()
+
+	if p.currentRune == scanner.Comment && p.options.commentStyles&SlashComments == 0 {
+		p.reportForbiddenComment(p.scanner.TokenText()[:2])
+	}
+}
+
+// reportScannerError keeps the errors reported by the scanner (e.g. an invalid digit in an octal literal)
+// as the warnings of the parsed configuration, or fails the parsing with the first of them if it is strict
+func (p *parser) reportScannerError(message string, position scanner.Position) {
+	err := scannerError(message, position.Line, position.Column)
+
+	if p.options.strictScanner {
+		if p.err == nil {
+			p.err = err
+		}
+
+		return
+	}
+
+	p.metadata.warnings = append(p.metadata.warnings, err.Error())
+}
+
+// reportForbiddenComment records the error for the comment whose style is not allowed by the options,
+// only the first error is kept and it is returned once the parsing ends
+func (p *parser) reportForbiddenComment(style string) {
+	if p.err == nil {
+		p.err = forbiddenCommentError(style, p.scanner.Line, p.scanner.Column)
+	}
+}
+
+// resolver resolves the substitutions in the configuration tree and records the outcome of each of them
+type resolver struct {
+	root            Value
+	report          *ResolutionReport
+	reported        map[*Substitution]bool          // substitutions recorded in the report
+	sources         map[string]bool                 // top-level keys recorded as the substitution sources in the report
+	resolving       []string                        // paths of the values being resolved, the last one is the current path, used to detect the cycles
+	source          func(path string) (Value, bool) // resolver of the WithSubstitutionResolver option
+	sourceBeforeEnv bool
+	noEnv           bool                       // do not resolve the substitutions against the environment variables
+	positions       map[*Substitution]position // positions of the substitutions written in the parsed input
+	collected       map[*Substitution]bool     // set to collect all the missing substitutions
+	undefined       map[string]bool            // paths of the fields left undefined by the missing substitutions
+	unresolvedCount int
+	missing         []MissingSubstitution
+}
+
+// position is where a value is written in the parsed input or in an included file
+type position struct {
+	file         string
+	line, column int
+}
+
+func newResolver(root Value) *resolver {
+	return &resolver{root: root, report: &ResolutionReport{}, reported: map[*Substitution]bool{}}
+}
+
+func resolveSubstitutions(root Object, valueOptional ...Value) error {
+	return newResolver(root).resolve(valueOptional...)
+}
+
+func (r *resolver) resolve(valueOptional ...Value) error {
+	var value Value
+	if valueOptional == nil {
+		value = r.root
+	} else {
+		value = valueOptional[0]
+	}
+
+	switch v := value.(type) {
+	case Array:
+		for i, value := range v {
+			if value == nil { // omitted by the lazy resolution of the elements
+				continue
+			}
+
+			err := r.processChild(strconv.Itoa(i), value, func(foundValue Value) { v[i] = foundValue })
+			if err != nil {
+				return err
+			}
+
+			if array, ok := v[i].(Array); ok && hasAbsentElements(array) {
+				v[i] = withoutAbsentElements(array)
+			}
+		}
+	case concatenation:
+		for i, value := range v {
+			err := r.processSubstitution(value, func(foundValue Value) { v[i] = foundValue })
+			if err != nil {
+				return err
+			}
+		}
+
+		if !v.containsObject() && !v.containsArray() {
+			for i, value := range v {
+				if value == nil { // an unresolved optional substitution becomes an empty string in a string concatenation
+					v[i] = String("")
+				}
+			}
+		}
+	case Object:
+		for key := range v {
+			if err := r.resolveField(v, key); err != nil {
+				return err
+			}
+		}
+	default:
+		return invalidValueError("substitutions are only allowed in field values and array elements", 0, 0)
+	}
+
+	return nil
+}
+
+// resolveField resolves the substitutions of the field with the given key, the concatenations of objects are merged
+// and the concatenations of arrays are joined
+func (r *resolver) resolveField(object Object, key string) error {
+	value := object[key]
+	path, unresolved := r.childPath(key), r.unresolvedCount
+
+	// the elements of a concatenation are replaced with their resolved values, keep where the substitutions are written
+	var positions []position
+	if concatenationValue, ok := value.(concatenation); ok {
+		positions = make([]position, len(concatenationValue))
+		for i, element := range concatenationValue {
+			if substitution, ok := element.(*Substitution); ok {
+				positions[i] = r.positions[substitution]
+			}
+		}
+	}
+
+	err := r.processChild(key, value, func(foundValue Value) {
+		if foundValue == nil { // an unresolved optional substitution leaves the field undefined
+			delete(object, key)
+
+			if r.unresolvedCount > unresolved { // the field is undefined because of a missing substitution, not by itself
+				r.undefined[path] = true
+			}
+		} else {
+			object[key] = foundValue
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	if array, ok := object[key].(Array); ok && hasAbsentElements(array) { // the resolved values are not written again
+		object[key] = withoutAbsentElements(array)
+	}
+
+	if concatenationValue, ok := value.(concatenation); ok && concatenationValue.containsObject() {
+		merged := Object{}
+
+		for _, value := range concatenationValue {
+			object, ok := value.(Object)
+			if !ok {
+				return invalidConcatenationError()
+			}
+
+			mergeObjects(merged, object)
+		}
+
+		object[key] = merged
+	} else if ok && concatenationValue.containsArray() {
+		joined := Array{}
+
+		for i, value := range concatenationValue {
+			if value == nil { // unresolved optional substitution
+				continue
+			}
+
+			array, ok := value.(Array)
+			if !ok {
+				err := invalidValueError(fmt.Sprintf("value: %q of the key: %q is not an array", value.String(), key), positions[i].line, positions[i].column)
+				return inFile(err, positions[i].file)
+			}
+
+			joined = append(joined, array...)
+		}
+
+		object[key] = joined
+	}
+
+	return nil
+}
+
+// resolvePath resolves the substitutions on the given path and in the subtree of the value at the path,
+// the other values of the tree are left unresolved. Used to resolve the substitutions lazily on access
+func (r *resolver) resolvePath(path string) error {
+	defer func() { r.resolving = r.resolving[:0] }()
+
+	var current Value = r.root
+
+	keys := splitPath(path)
+	for i, key := range keys {
+		last := i == len(keys)-1
+
+		switch v := current.(type) {
+		case Object:
+			if _, ok := v[key]; !ok {
+				return nil
+			}
+
+			if last || needsResolution(v[key]) {
+				if err := r.resolveField(v, key); err != nil {
+					return err
+				}
+			}
+
+			current = v[key]
+		case Array:
+			index, ok := arrayIndex(key, len(v))
+			if !ok || v[index] == nil { // the element of an unresolved optional substitution is omitted
+				return nil
+			}
+
+			key = strconv.Itoa(index)
+
+			if last || needsResolution(v[index]) {
+				if err := r.processChild(key, v[index], func(foundValue Value) { v[index] = foundValue }); err != nil {
+					return err
+				}
+			}
+
+			current = v[index]
+		default:
+			return nil
+		}
+
+		r.resolving = append(r.resolving, r.childPath(key))
+	}
+
+	return nil
+}
+
+// withoutAbsentElements removes the elements of the unresolved optional substitutions from the array
+func withoutAbsentElements(array Array) Array {
+	for i, element := range array {
+		if element != nil {
+			continue
+		}
+
+		compacted := append(Array{}, array[:i]...)
+		for _, element := range array[i+1:] {
+			if element != nil {
+				compacted = append(compacted, element)
+			}
+		}
+
+		return compacted
+	}
+
+	return array
+}
+
+func hasAbsentElements(array Array) bool {
+	for _, element := range array {
+		if element == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+func needsResolution(value Value) bool {
+	valueType := value.Type()
+	return valueType == SubstitutionType || valueType == valueWithAlternativeType || valueType == ConcatenationType
+}
+
+func (r *resolver) processChild(key string, value Value, resolveFunc func(value Value)) error {
+	if err := r.enter(r.childPath(key)); err != nil {
+		return err
+	}
+
+	defer r.exit()
+
+	return r.processSubstitution(value, resolveFunc)
+}
+
+func (r *resolver) childPath(key string) string {
+	if len(r.resolving) == 0 {
+		return key
+	}
+
+	return r.currentPath() + dotToken + key
+}
+
+func (r *resolver) currentPath() string {
+	if len(r.resolving) == 0 {
+		return ""
+	}
+
+	return r.resolving[len(r.resolving)-1]
+}
+
+// enter marks the value at the given path as being resolved, returns an error if it is already being resolved
+func (r *resolver) enter(path string) error {
+	for i, resolvingPath := range r.resolving {
+		if resolvingPath == path {
+			cycle := append(append([]string{}, r.resolving[i:]...), path)
+			return substitutionCycleError(cycle)
+		}
+	}
+
+	r.resolving = append(r.resolving, path)
+
+	return nil
+}
+
+func (r *resolver) exit() {
+	r.resolving = r.resolving[:len(r.resolving)-1]
+}
+
+func (r *resolver) processSubstitution(value Value, resolveFunc func(value Value)) error {
+	if valueType := value.Type(); valueType == SubstitutionType {
+		processed, err := r.processSubstitutionType(value.(*Substitution))
+		if err != nil {
+			return err
+		}
+		resolveFunc(processed)
+		return nil
+	} else if valueType == valueWithAlternativeType {
+		withAlternative := value.(*valueWithAlternative)
+		// a self-referential alternative refers to the previous value of the field
+		if withAlternative.alternative != nil && normalizePath(withAlternative.alternative.path) != r.currentPath() {
+			processed, err := r.processSubstitutionType(withAlternative.alternative)
+			if err != nil {
+				return err
+			}
+			if processed != nil {
+				resolveFunc(processed)
+				return nil
+			}
+		}
+		resolveFunc(withAlternative.value)
+		return nil
+	} else if valueType == ObjectType || valueType == ArrayType || valueType == ConcatenationType {
+		return r.resolve(value)
+	}
+
+	return nil
+}
+
+func (r *resolver) processSubstitutionType(substitution *Substitution) (Value, error) {
+	original := substitution
+
+	if substitution.parts != nil {
+		path, ok, err := r.expandPath(substitution)
+		if err != nil {
+			return nil, err
+		}
+
+		if !ok && !substitution.optional {
+			return r.unresolved(original)
+		} else if !ok {
+			r.record(&r.report.UnresolvedOptional, original, substitution.path)
+			return nil, nil
+		}
+
+		substitution = &Substitution{path: path, optional: substitution.optional}
+	}
+
+	if foundValue := find(r.root, substitution.path); foundValue != nil {
+		resolved, err := r.resolveFoundValue(normalizePath(substitution.path), foundValue)
+		if err != nil {
+			return nil, err
+		}
+		r.record(&r.report.Resolved, original, substitution.path)
+		r.recordSource(substitution.path)
+		return resolved, nil
+	}
+
+	if r.sourceBeforeEnv {
+		if resolved, ok, err := r.lookupSource(substitution, original); ok || err != nil {
+			return resolved, err
+		}
+	}
+
+	if env, ok := r.lookupEnv(normalizePath(substitution.path)); ok {
+		r.record(&r.report.FromEnv, original, substitution.path)
+		return String(env), nil
+	}
+
+	if !r.sourceBeforeEnv {
+		if resolved, ok, err := r.lookupSource(substitution, original); ok || err != nil {
+			return resolved, err
+		}
+	}
+
+	if !substitution.optional {
+		return r.unresolved(original)
+	}
+	r.record(&r.report.UnresolvedOptional, original, substitution.path)
+	return nil, nil
+}
+
+// record adds the path of the substitution to the given list of the report once, a substitution is processed again
+// if the value it is written in is reached through another substitution before the value is replaced in the tree
+func (r *resolver) record(list *[]string, substitution *Substitution, path string) {
+	if r.reported[substitution] {
+		return
+	}
+
+	r.reported[substitution] = true
+	*list = append(*list, path)
+}
+
+// recordSource adds the top-level key of the substituted path to the report once if the substitution is written
+// in another top-level key, the substitutions of a key referring to itself (e.g. path: ${path}":/bin") are not sources
+func (r *resolver) recordSource(path string) {
+	source, location := splitPath(path)[0], splitPath(r.currentPath())[0]
+	if source == location || r.sources[source] {
+		return
 	}
 
-	object, err := p.extractObject()
-	if err != nil {
-		return nil, err
+	if r.sources == nil {
+		r.sources = map[string]bool{}
 	}
 
-	if token := p.scanner.TokenText(); token != "" {
-		return nil, invalidObjectError("invalid token "+token, p.scanner.Line, p.scanner.Column)
+	r.sources[source] = true
+	r.report.SubstitutionSources = append(r.report.SubstitutionSources, source)
+}
+
+// unresolved returns the error for the required substitution that cannot be resolved, or with the WithAllMissingSubstitutions
+// option collects it and leaves it unresolved like an optional substitution to report all the missing ones at the end
+func (r *resolver) unresolved(substitution *Substitution) (Value, error) {
+	if r.collected == nil {
+		return nil, errors.New("could not resolve substitution: " + substitution.String() + " to a value")
 	}
 
-	err = resolveSubstitutions(object)
-	if err != nil {
-		return nil, err
+	r.unresolvedCount++
+
+	// the substitutions of the fields left undefined by the missing ones are not reported, only their causes
+	if !r.collected[substitution] && !r.undefined[normalizePath(substitution.path)] { // a substitution is processed again if the values referring to it are resolved first
+		r.collected[substitution] = true
+		position := r.positions[substitution]
+		r.missing = append(r.missing, MissingSubstitution{Path: substitution.path, File: position.file, Line: position.line, Column: position.column})
 	}
 
-	return &Config{root: object}, nil
+	return nil, nil
 }
 
-func (p *parser) advance() {
-	p.currentRune = p.scanner.Scan()
+// missingSubstitutionsError returns the error listing the collected missing substitutions in the order they are written,
+// it takes precedence over the given error that may be caused by leaving them unresolved. Returns the given error if none is missing
+func (r *resolver) missingSubstitutionsError(err error) error {
+	if len(r.missing) == 0 {
+		return err
+	}
 
-	var builder strings.Builder
+	sort.SliceStable(r.missing, func(i, j int) bool {
+		a, b := r.missing[i], r.missing[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
 
-	for p.currentRune == '\t' || p.currentRune == ' ' {
-		builder.WriteString(scanner.TokenString(p.currentRune))
-		p.currentRune = p.scanner.Scan()
-	}
+		return a.Line < b.Line || a.Line == b.Line && a.Column < b.Column
+	})
 
-	p.lastConsumedWhitespaces = builder.String()
+	return &MissingSubstitutionsError{Missing: r.missing}
 }
 
-func resolveSubstitutions(root Object, valueOptional ...Value) error {
-	var value Value
-	if valueOptional == nil {
-		value = root
-	} else {
-		value = valueOptional[0]
-	}
+// expandPath resolves the nested substitutions of the path of the given substitution and returns the path with their values,
+// reports false if an optional nested substitution is not resolved. The nested values are inserted as they are, so the periods
+// in them separate the keys of the path
+func (r *resolver) expandPath(substitution *Substitution) (string, bool, error) {
+	var builder strings.Builder
 
-	switch v := value.(type) {
-	case Array:
-		for i, value := range v {
-			err := processSubstitution(root, value, func(foundValue Value) { v[i] = foundValue })
-			if err != nil {
-				return err
-			}
+	for _, part := range substitution.parts {
+		nested, ok := part.(*Substitution)
+		if !ok {
+			builder.WriteString(string(part.(String)))
+			continue
 		}
-	case concatenation:
-		for i, value := range v {
-			err := processSubstitution(root, value, func(foundValue Value) { v[i] = foundValue })
-			if err != nil {
-				return err
-			}
+
+		value, err := r.processSubstitutionType(nested)
+		if err != nil || value == nil {
+			return "", false, err
 		}
-	case Object:
-		for key, value := range v {
-			err := processSubstitution(root, value, func(foundValue Value) { v[key] = foundValue })
-			if err != nil {
-				return err
-			}
 
-			if concatenationValue, ok := value.(concatenation); ok && concatenationValue.containsObject() {
-				merged := Object{}
+		if valueType := value.Type(); valueType == ObjectType || valueType == ArrayType || valueType == NullType {
+			return "", false, fmt.Errorf("could not resolve substitution: %s, the nested substitution: %s does not resolve to a string",
+				substitution, nested)
+		}
 
-				for _, value := range concatenationValue {
-					object, ok := value.(Object)
-					if !ok {
-						return invalidConcatenationError()
-					}
+		builder.WriteString(unquotedString(value))
+	}
 
-					mergeObjects(merged, object)
-				}
+	return builder.String(), true, nil
+}
 
-				root[key] = merged
-			}
-		}
-	default:
-		return invalidValueError("substitutions are only allowed in field values and array elements", 0, 0)
+// lookupEnv looks up the environment variable unless the substitutions do not fall back to the environment
+func (r *resolver) lookupEnv(name string) (string, bool) {
+	if r.noEnv {
+		return "", false
 	}
 
-	return nil
+	return os.LookupEnv(name)
 }
 
-func processSubstitution(root Object, value Value, resolveFunc func(value Value)) error {
-	if valueType := value.Type(); valueType == SubstitutionType {
-		processed, err := processSubstitutionType(root, value.(*Substitution))
-		if err != nil {
-			return err
-		}
-		resolveFunc(processed)
-		return nil
-	} else if valueType == valueWithAlternativeType {
-		withAlternative := value.(*valueWithAlternative)
-		if withAlternative.alternative != nil {
-			processed, err := processSubstitutionType(root, withAlternative.alternative)
-			if err != nil {
-				return err
-			}
-			if processed != nil {
-				resolveFunc(processed)
-				return nil
-			}
-		}
-		resolveFunc(withAlternative.value)
-		return nil
-	} else if valueType == ObjectType || valueType == ArrayType || valueType == ConcatenationType {
-		return resolveSubstitutions(root, value)
+// lookupSource resolves the substitution against the source of the WithSubstitutionResolver option,
+// reports false if there is no source or the source does not have a value for the path
+func (r *resolver) lookupSource(substitution, original *Substitution) (Value, bool, error) {
+	if r.source == nil {
+		return nil, false, nil
 	}
 
-	return nil
+	path := normalizePath(substitution.path)
+
+	value, ok := r.source(path)
+	if !ok {
+		return nil, false, nil
+	}
+
+	resolved, err := r.resolveFoundValue(path, value)
+	if err != nil {
+		return nil, false, err
+	}
+
+	r.record(&r.report.FromResolver, original, substitution.path)
+
+	return resolved, true, nil
 }
 
-func processSubstitutionType(root Object, substitution *Substitution) (Value, error) {
-	if foundValue := root.find(substitution.path); foundValue != nil {
-		return foundValue, nil
-	} else if env, ok := os.LookupEnv(substitution.path); ok {
-		return String(env), nil
-	} else if !substitution.optional {
-		return nil, errors.New("could not resolve substitution: " + substitution.String() + " to a value")
+// resolveFoundValue resolves the substitutions of the value that a substitution refers to, so that the chained
+// substitutions resolve to the final value regardless of the order they are processed in
+func (r *resolver) resolveFoundValue(path string, value Value) (Value, error) {
+	if err := r.enter(path); err != nil {
+		return nil, err
 	}
-	return nil, nil
+
+	defer r.exit()
+
+	resolved := value
+	err := r.processSubstitution(value, func(foundValue Value) { resolved = foundValue })
+
+	return resolved, err
 }
 
 func (p *parser) extractObject(isSubObject ...bool) (Object, error) {
 	object := Object{}
 	parenthesisBalanced := true
 
+	if p.root == nil && len(p.path) == 0 {
+		p.root = object
+	}
+
+	var opener *bracket
+
+	basePath := p.path
+	defer func() { p.path = basePath }()
+
+	defer p.leaveNested()
+
+	if err := p.enterNested(); err != nil {
+		return nil, err
+	}
+
 	if p.scanner.TokenText() == objectStartToken {
 		parenthesisBalanced = false
+		opener = p.openBracket()
 
 		p.advance()
 
@@ -235,13 +1033,20 @@ func (p *parser) extractObject(isSubObject ...bool) (Object, error) {
 	}
 
 	lastRow := 0
+	lastOffset := -1
 
 	for tok := p.scanner.Peek(); tok != scanner.EOF; tok = p.scanner.Peek() {
+		if !p.madeProgress(&lastOffset) {
+			return nil, invalidObjectError("unexpected token "+p.scanner.TokenText(), p.scanner.Line, p.scanner.Column)
+		}
+
+		p.path = basePath
+
 		for p.scanner.TokenText() == commentToken {
 			p.consumeComment()
 		}
 
-		if p.scanner.TokenText() == includeToken {
+		for p.scanner.TokenText() == includeToken { // the includes can follow each other, e.g. at the top of a file
 			p.advance()
 
 			includedObject, err := p.parseIncludedResource()
@@ -249,8 +1054,26 @@ func (p *parser) extractObject(isSubObject ...bool) (Object, error) {
 				return nil, err
 			}
 
-			mergeObjects(object, includedObject)
+			if err := p.checkConflict(object, includedObject, p.includedOrigins); err != nil {
+				return nil, err
+			}
+
+			p.recordIncludedArrays(strings.Join(p.path, dotToken), includedObject)
+
+			includeMerger := merger{keepExisting: p.options.additiveIncludes, mergeArrays: p.combineArrays}
+			includeMerger.mergeAt(strings.Join(p.path, dotToken), object, includedObject)
+			p.mergeOrigins(p.includedOrigins, p.options.additiveIncludes)
 			p.advance()
+			p.skipComments()
+
+			if p.scanner.TokenText() == commaToken {
+				p.advance() // skip ","
+				p.skipComments()
+
+				if p.scanner.TokenText() == commaToken {
+					return nil, adjacentCommasError(p.scanner.Line, p.scanner.Column)
+				}
+			}
 		}
 
 		if !parenthesisBalanced && p.scanner.TokenText() == objectEndToken {
@@ -261,17 +1084,30 @@ func (p *parser) extractObject(isSubObject ...bool) (Object, error) {
 			break
 		}
 
-		key := strings.Trim(p.scanner.TokenText(), `"`)
-		if forbiddenCharacters[key] {
+		if err := p.checkClosingBracket(opener); err != nil {
+			return nil, err
+		}
+
+		isQuotedKey := p.currentRune == scanner.String
+
+		key := unquote(p.scanner.TokenText())
+		if !isQuotedKey {
+			key = p.extractDigitLeadingKey(key)
+		}
+
+		if forbiddenCharacters[key] && !isQuotedKey {
 			return nil, invalidKeyError(key, p.scanner.Line, p.scanner.Column)
 		}
 
-		if key == dotToken {
+		if key == dotToken && !isQuotedKey {
 			return nil, leadingPeriodError(p.scanner.Line, p.scanner.Column)
 		}
 
+		p.path = append(basePath[:len(basePath):len(basePath)], key)
+
 		p.advance()
 		text := p.scanner.TokenText()
+		valueStart := -1 // offset of the value in the source, stays unset for the objects of the dotted keys
 
 		if text == dotToken || text == objectStartToken {
 			if text == dotToken {
@@ -288,35 +1124,60 @@ func (p *parser) extractObject(isSubObject ...bool) (Object, error) {
 
 			lastRow = p.scanner.Line
 
+			if p.scanner.TokenText() == objectStartToken {
+				valueStart = p.scanner.Position.Offset
+			}
+
+			existingOrigins := p.swapOrigins(map[string]string{})
+
 			extractedObject, err := p.extractObject(true)
 			if err != nil {
 				return nil, err
 			}
 
+			extractedOrigins := p.swapOrigins(existingOrigins)
+
 			if existingValue, ok := object[key]; ok {
+				if err := p.checkConflict(existingValue, extractedObject, extractedOrigins); err != nil {
+					return nil, err
+				}
+
 				if existingValue.Type() == ObjectType {
-					mergeObjects(existingValue.(Object), extractedObject)
+					p.mergeObjects(existingValue.(Object), extractedObject)
 					extractedObject = existingValue.(Object)
 				}
 			}
 
 			object[key] = extractedObject
+			p.mergeOrigins(extractedOrigins, false)
+			p.recordOrigin()
 		}
 
 		switch text {
 		case equalsToken, colonToken:
 			p.advance()
 			lastRow = p.scanner.Line
+			valueStart = p.scanner.Position.Offset
+
+			existingOrigins := p.swapOrigins(map[string]string{})
 
 			value, err := p.extractValue()
 			if err != nil {
 				return nil, err
 			}
 
+			extractedOrigins := p.swapOrigins(existingOrigins)
+
 			if existingValue, ok := object[key]; ok {
+				if err := p.checkConflict(existingValue, value, extractedOrigins); err != nil {
+					return nil, err
+				}
+
 				if existingValue.Type() == ObjectType && value.Type() == ObjectType {
-					mergeObjects(existingValue.(Object), value.(Object))
+					p.mergeObjects(existingValue.(Object), value.(Object))
 					value = existingValue
+				} else if array, ok := p.mergeIncludedArrays(strings.Join(p.path, dotToken), existingValue, value); ok {
+					value = array
 				} else if (existingValue.Type() == SubstitutionType && value.Type() == SubstitutionType) ||
 					(existingValue.Type() == ObjectType && value.Type() == SubstitutionType) ||
 					(existingValue.Type() == SubstitutionType && value.Type() == ObjectType) {
@@ -329,10 +1190,13 @@ func (p *parser) extractObject(isSubObject ...bool) (Object, error) {
 			}
 
 			object[key] = value
+			p.mergeOrigins(extractedOrigins, false)
+			p.recordOrigin()
 		case "+":
 			if p.scanner.Peek() == '=' {
 				p.advance()
 				p.advance()
+				valueStart = p.scanner.Position.Offset
 
 				err := p.parsePlusEqualsValue(object, key)
 				if err != nil {
@@ -342,6 +1206,8 @@ func (p *parser) extractObject(isSubObject ...bool) (Object, error) {
 		}
 
 		if parenthesisBalanced && len(isSubObject) > 0 && isSubObject[0] {
+			p.recordValue(object[key])
+			p.recordRawText(valueStart)
 			return object, nil
 		}
 
@@ -356,10 +1222,17 @@ func (p *parser) extractObject(isSubObject ...bool) (Object, error) {
 			}
 		}
 
+		p.recordValue(object[key])
+		p.recordRawText(valueStart)
+
 		for p.scanner.TokenText() == commentToken {
 			p.consumeComment()
 		}
 
+		if err := p.checkClosingBracket(opener); err != nil {
+			return nil, err
+		}
+
 		if p.scanner.Line == lastRow &&
 			p.scanner.TokenText() != commaToken &&
 			p.scanner.TokenText() != objectEndToken &&
@@ -385,51 +1258,310 @@ func (p *parser) extractObject(isSubObject ...bool) (Object, error) {
 	}
 
 	if !parenthesisBalanced {
+		if err := p.checkClosingBracket(opener); err != nil {
+			return nil, err
+		}
+
 		return nil, invalidObjectError("parenthesis do not match", p.scanner.Line, p.scanner.Column)
 	}
 
 	return object, nil
 }
 
+// bracket is an opening bracket of an object or an array with its position in the source
+type bracket struct {
+	token  string
+	line   int
+	column int
+}
+
+func (b bracket) closer() string {
+	if b.token == arrayStartToken {
+		return arrayEndToken
+	}
+
+	return objectEndToken
+}
+
+func (p *parser) openBracket() *bracket {
+	return &bracket{token: p.scanner.TokenText(), line: p.scanner.Line, column: p.scanner.Column}
+}
+
+// checkClosingBracket returns an error if the current token closes a different kind of bracket than the given opener
+func (p *parser) checkClosingBracket(opener *bracket) error {
+	if opener == nil {
+		return nil
+	}
+
+	token := p.scanner.TokenText()
+	if (token == objectEndToken || token == arrayEndToken) && token != opener.closer() {
+		return mismatchedBracketsError(*opener, token, p.scanner.Line, p.scanner.Column)
+	}
+
+	return nil
+}
+
+// sourceName returns the name of the parsed file to report the origins of the values
+func (p *parser) sourceName() string {
+	if p.filepath == "" {
+		return "the parsed string"
+	}
+
+	return p.filepath
+}
+
+// swapOrigins replaces the recorded origins with the given ones and returns the replaced ones, it keeps the origins
+// of the value being extracted apart from the origins of the existing values until they are checked for the conflicts
+func (p *parser) swapOrigins(origins map[string]string) map[string]string {
+	if !p.options.strictMerge {
+		return nil
+	}
+
+	replaced := p.origins
+	p.origins = origins
+
+	return replaced
+}
+
+// mergeOrigins records the origins of the merged value, the existing ones are kept if the merge keeps the existing values
+func (p *parser) mergeOrigins(origins map[string]string, keepExisting bool) {
+	for path, origin := range origins {
+		if _, ok := p.origins[path]; !ok || !keepExisting {
+			p.origins[path] = origin
+		}
+	}
+}
+
+func (p *parser) recordOrigin() {
+	if p.options.strictMerge {
+		p.origins[strings.Join(p.path, dotToken)] = p.sourceName()
+	}
+}
+
+// checkConflict returns an error with the WithStrictMerge option if the new value cannot be merged with the existing one
+// as an object and a non-object value are set at the same path, the files that the two values come from are looked up
+// in the recorded origins and in the given origins of the new value respectively
+func (p *parser) checkConflict(existing Value, new Value, newOrigins map[string]string) error {
+	if !p.options.strictMerge {
+		return nil
+	}
+
+	conflict := findMergeConflict(p.path, existing, new)
+	if conflict == nil {
+		return nil
+	}
+
+	existingOrigin := origin(p.origins, conflict.path, p.sourceName())
+	newOrigin := origin(newOrigins, conflict.path, p.sourceName())
+
+	return conflictingTypesError(conflict, existingOrigin, newOrigin, p.scanner.Line, p.scanner.Column)
+}
+
+// origin returns the file that the value at the given path comes from, that is the origin recorded for the path
+// or for its nearest parent, or the given default if none is recorded
+func origin(origins map[string]string, path []string, defaultOrigin string) string {
+	for i := len(path); i >= 0; i-- {
+		if origin, ok := origins[strings.Join(path[:i], dotToken)]; ok {
+			return origin
+		}
+	}
+
+	return defaultOrigin
+}
+
 func mergeObjects(existing Object, new Object) {
+	merger{}.merge(existing, new)
+}
+
+// mergeObjects merges the new object into the existing one at the path being extracted, the arrays of the includes
+// are combined with the new arrays as selected by the WithIncludeArrayMerge option
+func (p *parser) mergeObjects(existing Object, new Object) {
+	merger{mergeArrays: p.mergeIncludedArrays}.mergeAt(strings.Join(p.path, dotToken), existing, new)
+}
+
+// recordIncludedArrays records the paths of the arrays in the included value to combine them with the arrays
+// that the including file sets at the same paths after the include
+func (p *parser) recordIncludedArrays(path string, value Value) {
+	if p.options.arrayMerge == ReplaceArrays {
+		return
+	}
+
+	switch v := value.(type) {
+	case Array:
+		p.includedArrays[path] = true
+	case Object:
+		for key, element := range v {
+			elementPath := key
+			if path != "" {
+				elementPath = path + dotToken + key
+			}
+
+			p.recordIncludedArrays(elementPath, element)
+		}
+	}
+}
+
+// mergeIncludedArrays combines the existing array at the given path with the new one as selected by the WithIncludeArrayMerge
+// option if the existing array comes from an include, reports false if the new value replaces the existing one as usual
+func (p *parser) mergeIncludedArrays(path string, existing, new Value) (Value, bool) {
+	if !p.includedArrays[path] {
+		return nil, false
+	}
+
+	return p.combineArrays(path, new, existing)
+}
+
+// combineArrays appends or prepends the included array to the array of the including file, reports false if any of
+// the values is not an array or the arrays are replaced. The combined array is replaced by the later definitions as usual
+func (p *parser) combineArrays(path string, including, included Value) (Value, bool) {
+	includingArray, ok := including.(Array)
+	if !ok || p.options.arrayMerge == ReplaceArrays {
+		return nil, false
+	}
+
+	includedArray, ok := included.(Array)
+	if !ok {
+		return nil, false
+	}
+
+	delete(p.includedArrays, path)
+
+	if p.options.arrayMerge == PrependArrays {
+		return append(append(Array{}, includedArray...), includingArray...), true
+	}
+
+	return append(append(Array{}, includingArray...), includedArray...), true
+}
+
+// merger merges the new object into the existing one recursively, for the same keys the new values override the existing ones
+type merger struct {
+	nullFallsThrough bool                                                 // keep the existing value if the new value is null
+	keepExisting     bool                                                 // keep the existing value regardless of the new value, only the missing keys are added
+	onOverride       func(path string, existing, new Value)               // called for each existing value replaced with a different one
+	mergeArrays      func(path string, existing, new Value) (Value, bool) // combines the existing and the new value instead of replacing it if it reports true
+}
+
+func (m merger) merge(existing Object, new Object) {
+	m.mergeAt("", existing, new)
+}
+
+func (m merger) mergeAt(path string, existing Object, new Object) {
 	for key, value := range new {
+		keyPath := key
+		if path != "" {
+			keyPath = path + dotToken + key
+		}
+
 		existingValue, ok := existing[key]
 		if ok && existingValue.Type() == ObjectType && value.Type() == ObjectType {
 			existingObj := existingValue.(Object)
-			mergeObjects(existingObj, value.(Object))
+			m.mergeAt(keyPath, existingObj, value.(Object))
 			value = existingObj
+		} else if ok && m.mergeArrays != nil {
+			if merged, combined := m.mergeArrays(keyPath, existingValue, value); combined {
+				existing[key] = merged
+				continue
+			}
+		}
+
+		if ok && (m.keepExisting || m.nullFallsThrough && value.Type() == NullType) {
+			continue
+		}
+
+		if ok && m.onOverride != nil && (existingValue.Type() != value.Type() || existingValue.String() != value.String()) {
+			m.onOverride(keyPath, existingValue, value)
 		}
 
 		existing[key] = value
 	}
 }
 
-func (p *parser) parsePlusEqualsValue(existingObject Object, key string) error {
-	existingValue, ok := existingObject[key]
-	if !ok {
-		value, err := p.extractValue()
-		if err != nil {
-			return err
-		}
+func (p *parser) parsePlusEqualsValue(existingObject Object, key string) error {
+	existingValue, ok := existingObject[key]
+	if !ok {
+		existingValue, ok = p.valueAtCurrentPath()
+	}
+
+	if !ok {
+		value, err := p.extractValue()
+		if err != nil {
+			return err
+		}
+
+		existingObject[key] = Array{value}
+	} else {
+		if !isAppendable(existingValue) {
+			return invalidValueError(fmt.Sprintf("value: %q of the key: %q is not an array", existingValue.String(), key), p.scanner.Line, p.scanner.Pos().Column)
+		}
+		value, err := p.extractValue()
+		if err != nil {
+			return err
+		}
+		existingObject[key] = appendValue(existingValue, value)
+	}
+
+	return nil
+}
+
+// valueAtCurrentPath finds the value at the path being extracted in the root object, e.g. the array that an earlier
+// definition of the enclosing object or an included file set, which is merged with the object being extracted later.
+// The values in the arrays are not looked up as the arrays are replaced instead of being merged
+func (p *parser) valueAtCurrentPath() (Value, bool) {
+	if p.root == nil || len(p.path) == 0 {
+		return nil, false
+	}
+
+	var current Value = p.root
+
+	for _, key := range p.path {
+		object, ok := current.(Object)
+		if !ok {
+			return nil, false
+		}
+
+		if current, ok = object[key]; !ok {
+			return nil, false
+		}
+	}
+
+	return current, true
+}
+
+// isAppendable reports whether the += operator can append to the given value, the substitutions
+// are appendable as they are checked to resolve to an array while resolving the substitutions
+func isAppendable(value Value) bool {
+	switch v := value.(type) {
+	case Array, *Substitution, *valueWithAlternative:
+		return true
+	case concatenation:
+		return v.containsArray()
+	default:
+		return false
+	}
+}
+
+// appendValue appends the given value as a new element (objects are not merged into the existing elements),
+// the substitutions are concatenated with an array of the value to be joined once they are resolved
+func appendValue(existing, value Value) Value {
+	switch v := existing.(type) {
+	case Array:
+		return append(v, value)
+	case concatenation:
+		last := len(v) - 1
+		appended := append(concatenation{}, v...)
+		appended[last] = append(v[last].(Array), value)
 
-		existingObject[key] = Array{value}
-	} else {
-		if existingValue.Type() != ArrayType {
-			return invalidValueError(fmt.Sprintf("value: %q of the key: %q is not an array", existingValue.String(), key), p.scanner.Line, p.scanner.Pos().Column)
-		}
-		value, err := p.extractValue()
-		if err != nil {
-			return err
-		}
-		existingObject[key] = append(existingValue.(Array), value)
+		return appended
+	default:
+		return concatenation{existing, Array{value}}
 	}
-
-	return nil
 }
 
 func (p *parser) validateIncludeValue() (*include, error) {
 	var required bool
 
+	kind := IncludeQuoted
+
 	token := p.scanner.TokenText()
 	if token == "required" {
 		required = true
@@ -445,6 +1577,11 @@ func (p *parser) validateIncludeValue() (*include, error) {
 	}
 
 	if token == "file" || token == "classpath" {
+		kind = IncludeFile
+		if token == "classpath" {
+			kind = IncludeClasspath
+		}
+
 		p.advance()
 
 		if p.scanner.TokenText() != "(" {
@@ -475,18 +1612,50 @@ func (p *parser) validateIncludeValue() (*include, error) {
 		return nil, invalidValueError("expected quoted string, optionally wrapped in 'file(...)' or 'classpath(...)'", p.scanner.Line, p.scanner.Column)
 	}
 
-	return &include{path: token[1 : tokenLength-1], required: required}, nil // remove double quotes
+	// the fragment is written inside the quoted resource name (e.g. include "db.conf#connection"),
+	// a "#" after the closing quote starts a comment
+	includePath, fragment, _ := strings.Cut(token[1:tokenLength-1], "#") // remove double quotes
+
+	return &include{path: includePath, required: required, kind: kind, fragment: fragment}, nil
 }
 
 func (p *parser) parseIncludedResource() (includeObject Object, err error) {
+	line, column := p.scanner.Line, p.scanner.Column
+
 	includeToken, err := p.validateIncludeValue()
 	if err != nil {
 		return nil, err
 	}
 
-	parsedFileParentDir := path.Dir(p.filepath)
-	includePath := path.Join(parsedFileParentDir, includeToken.path)
-	file, err := os.Open(includePath)
+	includeToken.path, err = p.expandIncludePath(includeToken.path)
+	if err != nil {
+		return nil, err
+	}
+
+	if predicate := p.options.includePredicate; predicate != nil && !predicate(includeToken.token()) {
+		return Object{}, nil
+	}
+
+	if p.options.dryRunIncludes {
+		p.metadata.includes = append(p.metadata.includes, includeToken.token())
+		return Object{}, nil
+	}
+
+	includePath := includeToken.path
+	if !path.IsAbs(includePath) {
+		includePath = path.Join(p.baseDir, includePath)
+	}
+
+	var resource io.ReadCloser
+	if resolver := p.options.includeResolver; resolver != nil {
+		resolved := includeToken.token()
+		resolved.Path = includePath // relative to the including resource, the nested parser gets the directory of it as the base
+
+		resource, err = resolver(resolved)
+	} else {
+		resource, err = os.Open(includePath)
+	}
+
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) && !includeToken.required {
 			return Object{}, nil
@@ -495,10 +1664,23 @@ func (p *parser) parseIncludedResource() (includeObject Object, err error) {
 		return nil, fmt.Errorf("could not parse resource: %w", err)
 	}
 
-	includeParser := newFileParser(file)
+	var limited *limitedReader
+
+	var reader io.Reader = resource
+	if maxSize := p.options.maxIncludeSize; maxSize > 0 {
+		limited = &limitedReader{reader: resource, remaining: maxSize}
+		reader = limited
+	}
+
+	includeParser := newParserWithOptions(reader, includePath, path.Dir(includePath), p.options)
+	includeParser.metadata = p.metadata
+	includeParser.metadata.included++
+	includeParser.path = p.path
+	includeParser.root = p.root
+	includeParser.depth = p.depth - 1 // the included object is merged into the object being extracted
 
 	defer func() {
-		if closingErr := file.Close(); closingErr != nil {
+		if closingErr := resource.Close(); closingErr != nil {
 			err = closingErr
 		}
 	}()
@@ -509,7 +1691,156 @@ func (p *parser) parseIncludedResource() (includeObject Object, err error) {
 		return nil, invalidValueError("included file cannot contain an array as the root value", p.scanner.Line, p.scanner.Column)
 	}
 
-	return includeParser.extractObject()
+	includedObject, err := includeParser.extractObject()
+	if limited != nil && limited.exceeded {
+		return nil, fmt.Errorf("could not parse resource: %s exceeds the maximum include size of %d bytes", includeToken.path, p.options.maxIncludeSize)
+	}
+
+	if includeParser.err != nil {
+		return nil, inFile(includeParser.err, includePath)
+	}
+
+	if err != nil {
+		return nil, inFile(err, includePath)
+	}
+
+	selected, err := includeToken.selectFragment(includedObject, line, column)
+	if err != nil {
+		return nil, err
+	}
+
+	p.includedOrigins = includeParser.origins
+	if includeToken.fragment != "" { // the recorded paths are relative to the included file, not to the fragment
+		p.includedOrigins = map[string]string{strings.Join(p.path, dotToken): includePath}
+	}
+
+	return selected, nil
+}
+
+// expandIncludePath expands the ${VAR} references in the include path with the environment variables while parsing,
+// unlike the substitutions that are resolved after the whole tree is built. The unset variables expand to empty string
+// unless the WithStrictIncludeVariables option is given
+func (p *parser) expandIncludePath(includePath string) (string, error) {
+	var builder strings.Builder
+
+	for start := strings.Index(includePath, "${"); start >= 0; start = strings.Index(includePath, "${") {
+		end := strings.Index(includePath[start:], objectEndToken)
+		if end < 0 {
+			break
+		}
+
+		name := includePath[start+2 : start+end]
+
+		value, ok := os.LookupEnv(name)
+		if !ok && p.options.strictIncludeEnv {
+			return "", invalidValueError(fmt.Sprintf("environment variable %q in the include path is not set", name), p.scanner.Line, p.scanner.Column)
+		}
+
+		builder.WriteString(includePath[:start])
+		builder.WriteString(value)
+		includePath = includePath[start+end+1:]
+	}
+
+	builder.WriteString(includePath)
+
+	return builder.String(), nil
+}
+
+// selectFragment returns the subtree at the fragment path of the included object (or the object itself without
+// a fragment), a missing fragment is an error for the required includes and an empty object otherwise.
+// The errors are reported at the given position of the include value
+func (i *include) selectFragment(included Object, line, column int) (Object, error) {
+	if i.fragment == "" {
+		return included, nil
+	}
+
+	switch selected := find(included, i.fragment).(type) {
+	case Object:
+		return selected, nil
+	case nil:
+		if i.required {
+			return nil, invalidIncludeFragmentError(fmt.Sprintf("%s does not contain the fragment: %s", i.path, i.fragment), line, column)
+		}
+
+		return Object{}, nil
+	default:
+		return nil, invalidIncludeFragmentError(fmt.Sprintf("the fragment: %s of %s is not an object", i.fragment, i.path), line, column)
+	}
+}
+
+// controlCharacterReader reads from the underlying reader until the first control character other than the tab,
+// the line feed and the carriage return, and records the error for it at its position. The control characters are not
+// allowed anywhere in the input, they must be escaped in the quoted strings
+type controlCharacterReader struct {
+	reader io.Reader
+	parser *parser
+	line   int
+	column int
+	failed bool
+}
+
+func (c *controlCharacterReader) Read(b []byte) (int, error) {
+	if c.failed {
+		return 0, io.EOF
+	}
+
+	n, err := c.reader.Read(b)
+
+	for i, char := range b[:n] {
+		switch {
+		case char == '\n':
+			c.line++
+			c.column = 0
+
+			continue
+		case char&0xC0 != 0x80: // not a continuation byte of a multi-byte character
+			c.column++
+		}
+
+		if char < ' ' && char != '\t' && char != '\r' || char == 0x7F {
+			if c.parser.err == nil {
+				c.parser.err = controlCharacterError(rune(char), c.line, c.column)
+			}
+
+			c.failed = true
+
+			return i, io.EOF
+		}
+	}
+
+	return n, err
+}
+
+// limitedReader reads from the underlying reader until the limit is exceeded, unlike io.LimitedReader
+// it records whether the limit is exceeded to tell a truncated resource from a complete one
+type limitedReader struct {
+	reader    io.Reader
+	remaining int64
+	exceeded  bool
+}
+
+func (l *limitedReader) Read(b []byte) (int, error) {
+	if l.exceeded {
+		return 0, io.EOF
+	}
+
+	if l.remaining == 0 { // the limit is reached, the resource exceeds it unless it ends here
+		var next [1]byte
+		if n, _ := io.ReadFull(l.reader, next[:]); n > 0 {
+			l.exceeded = true
+		}
+
+		return 0, io.EOF
+	}
+
+	if int64(len(b)) > l.remaining {
+		b = b[:l.remaining]
+	}
+
+	n, err := l.reader.Read(b)
+	l.remaining -= int64(n)
+
+	return n, err
 }
 
 func (p *parser) checkAndConcatenate(object Object, key string) (bool, error) {
@@ -533,18 +1864,46 @@ func (p *parser) checkAndConcatenate(object Object, key string) (bool, error) {
 	return false, nil
 }
 
+// enterNested increments the nesting depth, returns an error if it exceeds the limit of the WithMaxDepth option
+func (p *parser) enterNested() error {
+	p.depth++
+
+	if maxDepth := p.options.maxDepth; maxDepth > 0 && p.depth > maxDepth {
+		return maxDepthError(maxDepth, p.scanner.Line, p.scanner.Column)
+	}
+
+	return nil
+}
+
+func (p *parser) leaveNested() {
+	p.depth--
+}
+
 func (p *parser) extractArray() (Array, error) {
 	if firstToken := p.scanner.TokenText(); firstToken != arrayStartToken {
 		return nil, invalidArrayError(fmt.Sprintf("%q is not an array start token", firstToken), p.scanner.Line, p.scanner.Column)
 	}
 
+	opener := p.openBracket()
+
+	defer p.leaveNested()
+
+	if err := p.enterNested(); err != nil {
+		return nil, err
+	}
+
 	p.advance()
+	p.skipComments()
 
 	token := p.scanner.TokenText()
 	if token == commaToken {
 		return nil, leadingCommaError(p.scanner.Line, p.scanner.Column)
 	}
 
+	if err := p.checkClosingBracket(opener); err != nil {
+		return nil, err
+	}
+
 	var array Array
 
 	if token == arrayEndToken { // empty array
@@ -554,24 +1913,48 @@ func (p *parser) extractArray() (Array, error) {
 
 	parenthesisBalanced := false
 	lastRow := 0
+	lastOffset := -1
+
+	basePath := p.path
+	defer func() { p.path = basePath }()
 
 	for tok := p.scanner.Peek(); tok != scanner.EOF; tok = p.scanner.Peek() {
+		if !p.madeProgress(&lastOffset) {
+			return nil, invalidArrayError("unexpected token "+p.scanner.TokenText(), p.scanner.Line, p.scanner.Column)
+		}
+
+		if err := p.checkClosingBracket(opener); err != nil {
+			return nil, err
+		}
+
 		lastRow = p.scanner.Line
+		p.path = append(basePath[:len(basePath):len(basePath)], strconv.Itoa(len(array)))
+
+		valueStart := p.scanner.Position.Offset
 
 		value, err := p.extractValue()
 		if err != nil {
 			return nil, err
 		}
 
+		p.recordValue(value)
+		p.recordRawText(valueStart)
+
 		array = append(array, value)
+		p.skipComments()
 		token = p.scanner.TokenText()
 
+		if err := p.checkClosingBracket(opener); err != nil {
+			return nil, err
+		}
+
 		if p.scanner.Line == lastRow && token != commaToken && token != arrayEndToken {
 			return nil, missingCommaError(p.scanner.Line, p.scanner.Column)
 		}
 
 		if p.scanner.TokenText() == commaToken {
 			p.advance() // skip comma
+			p.skipComments()
 
 			token = p.scanner.TokenText()
 
@@ -590,6 +1973,10 @@ func (p *parser) extractArray() (Array, error) {
 	}
 
 	if !parenthesisBalanced {
+		if err := p.checkClosingBracket(opener); err != nil {
+			return nil, err
+		}
+
 		return nil, invalidArrayError("parenthesis do not match", p.scanner.Line, p.scanner.Column)
 	}
 
@@ -597,6 +1984,8 @@ func (p *parser) extractArray() (Array, error) {
 }
 
 func (p *parser) extractValue() (Value, error) {
+	p.lastValueQuoted = false
+
 	token := p.scanner.TokenText()
 	if token == commentToken {
 		p.consumeComment()
@@ -607,46 +1996,75 @@ func (p *parser) extractValue() (Value, error) {
 	case scanner.Int:
 		value, err := strconv.Atoi(token)
 		if err != nil {
-			return nil, err
+			return p.extractLargeInteger(token, err)
 		}
 
+		line := p.scanner.Position.Line
+
 		durationUnit := p.extractDurationUnit()
 		if durationUnit != 0 {
 			p.advance()
 			return Duration(time.Duration(value) * durationUnit), nil
 		}
 
+		if withUnit, ok := p.extractNumberWithUnit(token, line); ok {
+			return withUnit, nil
+		}
+
+		if hasLeadingZero(token) { // not a valid number literal, e.g. a postal code
+			return String(token), nil
+		}
+
 		return Int(value), nil
 	case scanner.Float:
 		value, err := strconv.ParseFloat(token, 64)
-		if err != nil {
+		if errors.Is(err, strconv.ErrRange) && math.IsInf(value, 0) {
+			return nil, invalidValueError(fmt.Sprintf("the number: %s is out of range", token), p.scanner.Line, p.scanner.Column)
+		} else if err != nil && !errors.Is(err, strconv.ErrRange) { // the values too small to represent are rounded to zero
 			return nil, err
 		}
 
+		line := p.scanner.Position.Line
+
 		durationUnit := p.extractDurationUnit()
 		if durationUnit != 0 {
 			p.advance()
-			return Duration(time.Duration(value) * durationUnit), nil
+			return Duration(scaleDuration(value, durationUnit)), nil
+		}
+
+		if withUnit, ok := p.extractNumberWithUnit(token, line); ok {
+			return withUnit, nil
+		}
+
+		if hasLeadingZero(token) {
+			return String(token), nil
 		}
 
 		return Float64(value), nil
 	case scanner.String:
 		if isMultiLineString(token, p.scanner.Peek()) {
-			return p.extractMultiLineString()
+			value, err := p.extractMultiLineString()
+			p.lastValueQuoted = err == nil
+
+			return value, err
 		}
 
 		p.advance()
+		p.lastValueQuoted = true
 
-		return String(strings.Trim(token, `"`)), nil
+		return String(unquote(token)), nil
 	case scanner.Ident:
 		switch {
+		case token == "-" && unicode.IsDigit(p.scanner.Peek()):
+			p.advance()
+			return p.extractNegativeNumber()
 		case token == string(null):
 			p.advance()
 			return null, nil
-		case isBooleanString(token):
+		case p.isBooleanString(token):
 			p.advance()
-			return newBooleanFromString(token), nil
-		case isUnquotedString(token):
+			return p.newBoolean(token), nil
+		case isUnquotedString(token): // the other identifiers, including "include" which is a keyword only in the objects
 			p.advance()
 			return String(token), nil
 		}
@@ -667,33 +2085,82 @@ func (p *parser) extractValue() (Value, error) {
 	return nil, invalidValueError(fmt.Sprintf("unknown value: %q", token), p.scanner.Line, p.scanner.Column)
 }
 
+func (p *parser) extractNegativeNumber() (Value, error) {
+	value, err := p.extractValue()
+	if err != nil {
+		return nil, err
+	}
+
+	switch v := value.(type) {
+	case Int:
+		return -v, nil
+	case Float64:
+		return -v, nil
+	case Duration:
+		return -v, nil
+	case String: // a number with a unit
+		return "-" + v, nil
+	default:
+		return nil, invalidValueError(fmt.Sprintf("unknown value: %q", "-"+v.String()), p.scanner.Line, p.scanner.Column)
+	}
+}
+
+// extractLargeInteger keeps the integer that overflows int as a Float64 or a String according to the WithLargeIntegers
+// option, returns the parsing error otherwise
+func (p *parser) extractLargeInteger(token string, err error) (Value, error) {
+	if !errors.Is(err, strconv.ErrRange) {
+		return nil, err
+	}
+
+	switch p.options.largeIntegers {
+	case LargeIntegersAsFloat:
+		value, _ := strconv.ParseFloat(token, 64)
+		p.advance()
+
+		return Float64(value), nil
+	case LargeIntegersAsString:
+		p.advance()
+
+		return String(token), nil
+	default:
+		return nil, err
+	}
+}
+
+// extractDurationUnit advances past the number and returns the duration unit that follows it, or zero if there is none
 func (p *parser) extractDurationUnit() time.Duration {
-	nextCharacter := p.scanner.Peek()
+	line := p.scanner.Position.Line
 	p.advance()
 
-	if nextCharacter != '\n' && p.scanner.Line == p.scanner.Pos().Line {
-		switch p.scanner.TokenText() {
-		case "ns", "nano", "nanos", "nanosecond", "nanoseconds":
-			return time.Nanosecond
-		case "us", "micro", "micros", "microsecond", "microseconds":
-			return time.Microsecond
-		case "ms", "milli", "millis", "millisecond", "milliseconds":
-			return time.Millisecond
-		case "s", "second", "seconds":
-			return time.Second
-		case "m", "minute", "minutes":
-			return time.Minute
-		case "h", "hour", "hours":
-			return time.Hour
-		case "d", "day", "days":
-			return time.Hour * 24
-		}
+	if p.scanner.numberUnit(p.currentRune, line, p.lastConsumedWhitespaces) == durationUnit {
+		return durationUnits[p.scanner.TokenText()]
 	}
 
 	return time.Duration(0)
 }
 
-func (p *parser) extractSubstitution() (*Substitution, error) {
+// extractNumberWithUnit joins the number with the size unit that follows it on the same line (e.g. 512MB or
+// 512 megabytes) keeping the whitespaces between them, the value is kept as a string to be parsed by the getters.
+// The durations are extracted before, the other identifiers are not joined and fail as a missing comma
+func (p *parser) extractNumberWithUnit(number string, line int) (String, bool) {
+	if p.scanner.numberUnit(p.currentRune, line, p.lastConsumedWhitespaces) != sizeUnit {
+		return "", false
+	}
+
+	value := String(number + p.lastConsumedWhitespaces + p.scanner.TokenText())
+	p.advance()
+
+	return value, true
+}
+
+func (p *parser) extractSubstitution() (substitution *Substitution, err error) {
+	start := position{file: p.filepath, line: p.scanner.Line, column: p.scanner.Column}
+	defer func() {
+		if substitution != nil {
+			p.metadata.positions[substitution] = start
+		}
+	}()
+
 	p.advance() // skip "$"
 	p.advance() // skip "{"
 
@@ -704,6 +2171,8 @@ func (p *parser) extractSubstitution() (*Substitution, error) {
 		p.advance()
 	}
 
+	p.skipComments()
+
 	token := p.scanner.TokenText()
 	if token == objectEndToken {
 		return nil, invalidSubstitutionError("path expression cannot be empty", p.scanner.Line, p.scanner.Column)
@@ -713,19 +2182,40 @@ func (p *parser) extractSubstitution() (*Substitution, error) {
 		return nil, leadingPeriodError(p.scanner.Line, p.scanner.Column)
 	}
 
-	var pathBuilder strings.Builder
+	var pathBuilder, literal strings.Builder
+
+	var parts []Value // the literal parts and the nested substitutions of the path, if it contains any nested substitution
 
 	parenthesisBalanced := false
 
 	var previousToken string
 
-	for tok := p.scanner.Peek(); tok != scanner.EOF; p.scanner.Peek() {
-		if token == commentToken {
-			return nil, invalidSubstitutionError("comments are not allowed inside substitutions", p.scanner.Line, p.scanner.Column)
+	for tok := p.scanner.Peek(); tok != scanner.EOF && p.currentRune != scanner.EOF; tok = p.scanner.Peek() {
+		if token == "$" && tok == '{' {
+			if !p.options.nestedSubstitutions {
+				return nil, invalidSubstitutionError("nested substitutions are not allowed in the path expression, "+
+					"use the WithNestedSubstitutions option to enable them", p.scanner.Line, p.scanner.Column)
+			}
+
+			nested, err := p.extractSubstitution()
+			if err != nil {
+				return nil, err
+			}
+
+			if literal.Len() > 0 {
+				parts = append(parts, String(literal.String()))
+				literal.Reset()
+			}
+
+			parts = append(parts, nested)
+			pathBuilder.WriteString(nested.String())
+		} else {
+			pathBuilder.WriteString(token)
+			literal.WriteString(token)
+			p.advance()
 		}
 
-		pathBuilder.WriteString(token)
-		p.advance()
+		p.skipComments()
 		token = p.scanner.TokenText()
 
 		if previousToken == dotToken && token == dotToken {
@@ -744,7 +2234,7 @@ func (p *parser) extractSubstitution() (*Substitution, error) {
 			break
 		}
 
-		if forbiddenCharacters[token] {
+		if forbiddenCharacters[token] && (token != "$" || p.scanner.Peek() != '{') { // the nested substitutions are checked above
 			return nil, invalidKeyError(token, p.scanner.Line, p.scanner.Column)
 		}
 
@@ -755,42 +2245,89 @@ func (p *parser) extractSubstitution() (*Substitution, error) {
 		return nil, invalidSubstitutionError("missing closing parenthesis", p.scanner.Line, p.scanner.Column)
 	}
 
-	return &Substitution{path: pathBuilder.String(), optional: optional}, nil
+	if parts != nil && literal.Len() > 0 {
+		parts = append(parts, String(literal.String()))
+	}
+
+	return &Substitution{path: pathBuilder.String(), optional: optional, parts: parts}, nil
 }
 
-func (p *parser) consumeComment() {
-	for token := p.scanner.Peek(); token != '\n' && token != scanner.EOF && !strings.HasSuffix(p.scanner.TokenText(), "\n"); token = p.scanner.Peek() {
-		p.advance()
+// recordValue records the metadata of the last extracted value at the current path
+func (p *parser) recordValue(value Value) {
+	currentPath := strings.Join(p.path, dotToken)
+
+	if _, ok := value.(String); ok && p.lastValueQuoted {
+		p.metadata.quoted[currentPath] = true
+	} else {
+		delete(p.metadata.quoted, currentPath)
 	}
-	p.advance()
 }
 
-func (p *parser) extractMultiLineString() (String, error) {
-	p.scanner.Next()
+// recordRawText records the source text from the given offset to the end of the last extracted value at the current path
+func (p *parser) recordRawText(start int) {
+	if p.source == nil || start < 0 || start > p.lastTokenEnd || p.lastTokenEnd > p.source.Len() {
+		return
+	}
 
-	adjacentQuoteCount := 0
+	p.metadata.raw[strings.Join(p.path, dotToken)] = string(p.source.Bytes()[start:p.lastTokenEnd])
+	p.spans[joinPath(p.path)] = span{start: start, end: p.lastTokenEnd}
+}
 
-	var multiLineBuilder strings.Builder
+// sourceSpans returns the whole source with the offsets of the values in it to edit them in place,
+// returns nil if the raw text of the values is not recorded
+func (p *parser) sourceSpans() *sourceSpans {
+	if p.source == nil {
+		return nil
+	}
 
-	for next := p.scanner.Next(); next != scanner.EOF; next = p.scanner.Next() {
-		multiLineBuilder.WriteRune(next)
+	switch {
+	case p.stream && p.documentEnd > 0:
+		return &sourceSpans{text: p.source.String()[:p.documentEnd], spans: p.spans} // the rest belongs to the next documents
+	case p.options.ignoreTrailing:
+		_, _ = io.Copy(io.Discard, p.input) // the content after the root value is not consumed with the WithLenientTrailingContent option
+	}
 
-		if next == '"' {
-			adjacentQuoteCount++
-		} else {
-			adjacentQuoteCount = 0
-		}
+	return &sourceSpans{text: p.source.String(), spans: p.spans}
+}
 
-		if adjacentQuoteCount >= 3 && p.scanner.Peek() != '"' {
-			break
-		}
+// madeProgress reports whether the scanner moved past the given offset and updates it,
+// used to guarantee that the parsing loops never spin on the same token
+func (p *parser) madeProgress(lastOffset *int) bool {
+	offset := p.scanner.Pos().Offset
+	if offset == *lastOffset {
+		return false
+	}
+
+	*lastOffset = offset
+
+	return true
+}
+
+// skipComments consumes the comments in the middle of an expression (e.g. between the array elements)
+func (p *parser) skipComments() {
+	for p.scanner.TokenText() == commentToken {
+		p.consumeComment()
+	}
+}
+
+func (p *parser) consumeComment() {
+	if p.options.commentStyles&HashComments == 0 {
+		p.reportForbiddenComment(commentToken)
 	}
 
-	if adjacentQuoteCount >= 3 {
-		return String(multiLineBuilder.String()[:multiLineBuilder.Len()-3]), nil
+	p.scanner.restOfLine()
+	p.advance()
+}
+
+func (p *parser) extractMultiLineString() (String, error) {
+	content, closed := p.scanner.restOfMultiLineString()
+	if !closed {
+		return "", unclosedMultiLineStringError()
 	}
 
-	return "", unclosedMultiLineStringError()
+	p.lastTokenEnd = p.scanner.Pos().Offset // the closing quotes are consumed without advancing
+
+	return String(content), nil
 }
 
 func (p *parser) isTokenConcatenable(currentText string, peeked rune) bool {
@@ -799,14 +2336,47 @@ func (p *parser) isTokenConcatenable(currentText string, peeked rune) bool {
 		(p.currentRune == scanner.String && !isMultiLineString(currentText, peeked))
 }
 
+// unquote removes the double quotes of the given quoted string token and processes the JSON escape sequences in it,
+// the token is returned as it is if it is not quoted
+func unquote(token string) string {
+	if len(token) < 2 || !strings.HasPrefix(token, `"`) || !strings.HasSuffix(token, `"`) {
+		return token
+	}
+
+	var unquoted string
+	if err := json.Unmarshal([]byte(token), &unquoted); err != nil {
+		return token[1 : len(token)-1] // not a valid JSON string, keep the escape sequences as they are
+	}
+
+	return unquoted
+}
+
 func isBooleanString(token string) bool {
-	return token == "true" || token == "yes" || token == "on" || token == "false" || token == "no" || token == "off"
+	_, ok := lookupBoolean(token)
+	return ok
+}
+
+// isBooleanString reports whether the token is one of the default or the parse-local boolean spellings, or one of the
+// registered spellings if the WithBooleanSpellings option is not given
+func (p *parser) isBooleanString(token string) bool {
+	_, ok := p.options.booleans.lookup(token)
+	return ok
+}
+
+func (p *parser) newBoolean(token string) Boolean {
+	value, _ := p.options.booleans.lookup(token)
+	return Boolean(value)
 }
 
 func isSubstitution(token string, peekedToken rune) bool {
 	return token == "$" && peekedToken == '{'
 }
 
+// hasLeadingZero reports whether the number token starts with a zero followed by another digit (e.g. 01234, 00)
+func hasLeadingZero(token string) bool {
+	return len(token) > 1 && token[0] == '0' && unicode.IsDigit(rune(token[1]))
+}
+
 func isSeparator(token string, peekedToken rune) bool {
 	return token == equalsToken || token == colonToken || (token == "+" && peekedToken == '=')
 }
@@ -828,4 +2398,28 @@ func isMultiLineString(token string, peekedToken rune) bool {
 type include struct {
 	path     string
 	required bool
+	kind     IncludeKind
+	fragment string
+}
+
+func (i *include) token() IncludeToken {
+	return IncludeToken{Path: i.path, Required: i.required, Kind: i.kind, Fragment: i.fragment}
+}
+
+// IncludeKind is the form an include directive is written in
+type IncludeKind int
+
+// IncludeKind constants
+const (
+	IncludeQuoted    IncludeKind = iota // include "path"
+	IncludeFile                         // include file("path")
+	IncludeClasspath                    // include classpath("path")
+)
+
+// IncludeToken describes an include directive in the configuration
+type IncludeToken struct {
+	Path     string
+	Required bool
+	Kind     IncludeKind
+	Fragment string // path of the subtree to include, written after a "#" in the resource name (e.g. include "db.conf#connection")
 }
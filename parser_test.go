@@ -3,9 +3,14 @@ package hocon
 import (
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"testing/iotest"
 	"time"
 )
 
@@ -13,7 +18,7 @@ func TestParseString(t *testing.T) {
 	t.Run("parse the string and return a pointer to the Config", func(t *testing.T) {
 		got, err := ParseString("{a:1}")
 		assertNoError(t, err)
-		assertDeepEqual(t, got, &Config{Object{"a": Int(1)}})
+		assertDeepEqual(t, got, parsedConfig(Object{"a": Int(1)}))
 	})
 
 	t.Run("return the error if any error occurs in the parse() method", func(t *testing.T) {
@@ -21,6 +26,326 @@ func TestParseString(t *testing.T) {
 		assertError(t, err, leadingPeriodError(1, 2))
 		assertNil(t, got)
 	})
+
+	t.Run("keep the scanner errors as the warnings of the config", func(t *testing.T) {
+		got, err := ParseString("a: 'ab'")
+		assertNoError(t, err)
+		assertDeepEqual(t, got.Warnings(), []string{scannerError("invalid char literal", 1, 4).Error()})
+	})
+
+	t.Run("return the scanner error if the scanner is strict", func(t *testing.T) {
+		got, err := ParseString("a: 'ab'", WithStrictScanner())
+		assertError(t, err, scannerError("invalid char literal", 1, 4))
+		assertNil(t, got)
+	})
+
+	t.Run("do not report the digits 8 and 9 of the leading zero numbers as invalid octal digits", func(t *testing.T) {
+		got, err := ParseString("zip = 08540\ncode = 0129")
+		assertNoError(t, err)
+		assertDeepEqual(t, got.root, Object{"zip": String("08540"), "code": String("0129")})
+		assertDeepEqual(t, got.Warnings(), []string(nil))
+	})
+
+	t.Run("parse the leading zero numbers with the digits 8 and 9 if the scanner is strict", func(t *testing.T) {
+		got, err := ParseString("zip = 08540\ncode = 0129", WithStrictScanner())
+		assertNoError(t, err)
+		assertDeepEqual(t, got.root, Object{"zip": String("08540"), "code": String("0129")})
+	})
+
+	t.Run("accept both comment styles by default", func(t *testing.T) {
+		got, err := ParseString("a:1 # hash\nb:2 // slash")
+		assertNoError(t, err)
+		assertDeepEqual(t, got.root, Object{"a": Int(1), "b": Int(2)})
+	})
+
+	t.Run("return forbiddenCommentError if the slash comments are not allowed", func(t *testing.T) {
+		got, err := ParseString("a:1 # hash\nb:2 // slash", WithCommentStyles(HashComments))
+		assertError(t, err, forbiddenCommentError("//", 2, 5))
+		assertNil(t, got)
+	})
+
+	t.Run("return forbiddenCommentError if the hash comments are not allowed", func(t *testing.T) {
+		got, err := ParseString("a:1 // slash\nb:2 # hash", WithCommentStyles(SlashComments))
+		assertError(t, err, forbiddenCommentError("#", 2, 5))
+		assertNil(t, got)
+	})
+
+	t.Run("accept the allowed comment style", func(t *testing.T) {
+		got, err := ParseString("# hash\na:1 # hash", WithCommentStyles(HashComments))
+		assertNoError(t, err)
+		assertDeepEqual(t, got.root, Object{"a": Int(1)})
+	})
+}
+
+func TestHeader(t *testing.T) {
+	t.Run("collect the comment lines before the first key", func(t *testing.T) {
+		got, err := ParseString("# generated by tool\n\n// at 2024-01-01\n/* block */\na: 1 # not a header\n# neither\nb: 2")
+		assertNoError(t, err)
+		assertDeepEqual(t, got.Header(), []string{"# generated by tool", "// at 2024-01-01", "/* block */"})
+		assertDeepEqual(t, got.root, Object{"a": Int(1), "b": Int(2)})
+	})
+
+	t.Run("collect the comment lines before the root array", func(t *testing.T) {
+		got, err := ParseString("#header\n[1]")
+		assertNoError(t, err)
+		assertDeepEqual(t, got.Header(), []string{"#header"})
+		assertDeepEqual(t, got.root, Array{Int(1)})
+	})
+
+	t.Run("return nil if there is no header", func(t *testing.T) {
+		got, err := ParseString("a: 1 # comment")
+		assertNoError(t, err)
+		assertNil(t, got.Header())
+	})
+
+	t.Run("emit the header before the rendered config", func(t *testing.T) {
+		got, err := ParseString("# generated\n// by tool\n{a: 1}")
+		assertNoError(t, err)
+		assertEquals(t, got.String(), "# generated\n// by tool\n{a:1}")
+	})
+
+	t.Run("return forbiddenCommentError for a forbidden comment style in the header", func(t *testing.T) {
+		got, err := ParseString("// header\na: 1", WithCommentStyles(HashComments))
+		assertError(t, err, forbiddenCommentError("//", 1, 1))
+		assertNil(t, got)
+
+		got, err = ParseString("# header\na: 1", WithCommentStyles(SlashComments))
+		assertError(t, err, forbiddenCommentError("#", 1, 1))
+		assertNil(t, got)
+	})
+}
+
+func TestTrailingContent(t *testing.T) {
+	for _, input := range []string{"{a:1}\n", "{a:1} # hash", "{a:1}\n// slash\n# hash\n", "{a:1}\t\n\n"} {
+		t.Run(fmt.Sprintf("accept the whitespaces and comments after the root object: %q", input), func(t *testing.T) {
+			got, err := ParseString(input)
+			assertNoError(t, err)
+			assertDeepEqual(t, got.root, Object{"a": Int(1)})
+		})
+	}
+
+	t.Run("accept the whitespaces and comments after the root array", func(t *testing.T) {
+		got, err := ParseString("[1] # hash\n// slash\n")
+		assertNoError(t, err)
+		assertDeepEqual(t, got.root, Array{Int(1)})
+	})
+
+	t.Run("return an error if there is a token after the root object", func(t *testing.T) {
+		got, err := ParseString("{a:1}\n# hash\n{b:2}")
+		assertError(t, err, invalidObjectError("invalid token {", 3, 1))
+		assertNil(t, got)
+	})
+
+	t.Run("return an error if there is a token after the root array", func(t *testing.T) {
+		got, err := ParseString("[1] x")
+		assertError(t, err, invalidArrayError("invalid token x", 1, 5))
+		assertNil(t, got)
+	})
+
+	t.Run("ignore the trailing content with the lenient option", func(t *testing.T) {
+		got, err := ParseString("{a:1}\n{b:2}", WithLenientTrailingContent())
+		assertNoError(t, err)
+		assertDeepEqual(t, got.root, Object{"a": Int(1)})
+
+		got, err = ParseString("[1] [2]", WithLenientTrailingContent())
+		assertNoError(t, err)
+		assertDeepEqual(t, got.root, Array{Int(1)})
+	})
+}
+
+func TestStrictMerge(t *testing.T) {
+	include := `include "testdata/strict_base.conf"` + "\n"
+
+	t.Run("return an error naming the files if an included value conflicts with an object", func(t *testing.T) {
+		got, err := ParseString(include+"timeout { unit = s }", WithStrictMerge())
+		conflict := &mergeConflict{path: []string{"timeout"}, existing: Int(30), new: Object{}}
+		assertError(t, err, conflictingTypesError(conflict, "testdata/strict_base.conf", "the parsed string", 2, 21))
+		assertNil(t, got)
+
+		got, err = ParseString(include+"server = 1", WithStrictMerge())
+		conflict = &mergeConflict{path: []string{"server"}, existing: Object{}, new: Int(1)}
+		assertError(t, err, conflictingTypesError(conflict, "testdata/strict_base.conf", "the parsed string", 2, 11))
+		assertNil(t, got)
+	})
+
+	t.Run("return an error if the included value conflicts with an existing one", func(t *testing.T) {
+		got, err := ParseString("timeout { unit = s }\n"+include, WithStrictMerge())
+		conflict := &mergeConflict{path: []string{"timeout"}, existing: Object{}, new: Int(30)}
+		assertError(t, err, conflictingTypesError(conflict, "the parsed string", "testdata/strict_base.conf", 2, 9))
+		assertNil(t, got)
+	})
+
+	t.Run("return an error for the conflicts in the nested objects", func(t *testing.T) {
+		got, err := ParseString("a { include \"testdata/db.conf\" }\na.pool.size.x = 1", WithStrictMerge())
+		conflict := &mergeConflict{path: []string{"a", "pool", "size"}, existing: Int(10), new: Object{}}
+		assertError(t, err, conflictingTypesError(conflict, "testdata/db.conf", "the parsed string", 2, 18))
+		assertNil(t, got)
+	})
+
+	t.Run("merge the values of the same kind and the nulls", func(t *testing.T) {
+		got, err := ParseString(include+"timeout = 60\nserver { host = localhost }\nserver = null\nserver { port = 80 }", WithStrictMerge())
+		assertNoError(t, err)
+		assertDeepEqual(t, got.root, Object{"timeout": Int(60), "server": Object{"port": Int(80)}})
+	})
+
+	t.Run("return an error for the conflicts in the strict mode", func(t *testing.T) {
+		_, err := ParseString(include+"timeout { unit = s }", WithStrictMode())
+		conflict := &mergeConflict{path: []string{"timeout"}, existing: Int(30), new: Object{}}
+		assertError(t, err, conflictingTypesError(conflict, "testdata/strict_base.conf", "the parsed string", 2, 21))
+	})
+
+	t.Run("replace the conflicting values without the strict merge", func(t *testing.T) {
+		got, err := ParseString(include + "timeout { unit = s }")
+		assertNoError(t, err)
+		assertDeepEqual(t, got.root, Object{"timeout": Object{"unit": String("s")}, "server": Object{"port": Int(8080)}})
+	})
+}
+
+func TestParseReader(t *testing.T) {
+	t.Run("parse the input read from the reader with the options", func(t *testing.T) {
+		got, err := ParseReader(strings.NewReader(`{"a": [1, 2]}`), WithSyntax(JSONSyntax))
+		assertNoError(t, err)
+		assertDeepEqual(t, got.root, Object{"a": Array{Int(1), Int(2)}})
+
+		got, err = ParseReader(strings.NewReader("a: 1 // comment"), WithSyntax(JSONSyntax))
+		assertError(t, err, invalidJSONError("invalid character 'a' looking for beginning of value", 1, 1))
+		assertNil(t, got)
+	})
+
+	t.Run("return an error if the input cannot be read", func(t *testing.T) {
+		got, err := ParseReader(iotest.ErrReader(errors.New("broken")))
+		assertError(t, err, errors.New("could not read the input: broken"))
+		assertNil(t, got)
+	})
+}
+
+func TestWithEnvFallback(t *testing.T) {
+	t.Setenv("ENV_FALLBACK_TEST", "env")
+
+	t.Run("resolve the substitutions against the environment variables by default", func(t *testing.T) {
+		got, err := ParseString("a: ${ENV_FALLBACK_TEST}", WithEnvFallback(true))
+		assertNoError(t, err)
+		assertDeepEqual(t, got.root, Object{"a": String("env")})
+	})
+
+	t.Run("do not resolve the substitutions against the environment variables if disabled", func(t *testing.T) {
+		got, err := ParseString("a: ${ENV_FALLBACK_TEST}", WithEnvFallback(false))
+		assertError(t, err, errors.New("could not resolve substitution: ${ENV_FALLBACK_TEST} to a value"))
+		assertNil(t, got)
+
+		got, err = ParseString("a: ${?ENV_FALLBACK_TEST}, b: 1", WithEnvFallback(false))
+		assertNoError(t, err)
+		assertEquals(t, got.GetInt("b"), 1)
+		assertEquals(t, got.GetString("a"), "")
+	})
+}
+
+func TestWithMaxDepth(t *testing.T) {
+	t.Run("accept the input nested up to the maximum depth", func(t *testing.T) {
+		got, err := ParseString("a { b: [1] }, c.d: 2", WithMaxDepth(3))
+		assertNoError(t, err)
+		assertDeepEqual(t, got.root, Object{"a": Object{"b": Array{Int(1)}}, "c": Object{"d": Int(2)}})
+	})
+
+	t.Run("return an error if the input is nested deeper than the maximum depth", func(t *testing.T) {
+		got, err := ParseString("a { b: [[1]] }", WithMaxDepth(3))
+		assertError(t, err, maxDepthError(3, 1, 9))
+		assertNil(t, got)
+
+		got, err = ParseString("a.b.c: 1", WithMaxDepth(2))
+		assertError(t, err, maxDepthError(2, 1, 5))
+		assertNil(t, got)
+
+		got, err = ParseString(strings.Repeat("[", 100000), WithMaxDepth(10))
+		assertError(t, err, maxDepthError(10, 1, 11))
+		assertNil(t, got)
+	})
+
+	t.Run("count the depth of the included objects from the including object", func(t *testing.T) {
+		got, err := ParseString(`a { include "testdata/db.conf" }`, WithMaxDepth(2))
+		assertError(t, err, inFile(maxDepthError(2, 1, 12), "testdata/db.conf"))
+		assertNil(t, got)
+	})
+}
+
+func TestControlCharacters(t *testing.T) {
+	t.Run("return an error at the position of the control character", func(t *testing.T) {
+		for _, tc := range []struct {
+			input    string
+			expected error
+		}{
+			{"a: 1\nb: x\x00y", controlCharacterError(0, 2, 5)},
+			{"a: \"é\x01\"", controlCharacterError(1, 1, 6)},
+			{"a: [1,\x1b2]", controlCharacterError(0x1b, 1, 7)},
+			{"a: \"\"\"x\x7f\"\"\"", controlCharacterError(0x7f, 1, 8)},
+		} {
+			got, err := ParseString(tc.input)
+			assertError(t, err, tc.expected)
+			assertNil(t, got)
+		}
+	})
+
+	t.Run("accept the whitespaces and the escaped control characters", func(t *testing.T) {
+		got, err := ParseString("a:\t1\r\nb: \"x\\u0000y\\n\"")
+		assertNoError(t, err)
+		assertDeepEqual(t, got.root, Object{"a": Int(1), "b": String("x\x00y\n")})
+	})
+
+	t.Run("return an error for the control characters in the included files", func(t *testing.T) {
+		resolver := func(IncludeToken) (io.ReadCloser, error) { return io.NopCloser(strings.NewReader("a: \x02")), nil }
+		got, err := ParseString(`include "virtual.conf"`, WithIncludeResolver(resolver))
+		assertError(t, err, inFile(controlCharacterError(2, 1, 4), "virtual.conf"))
+		assertNil(t, got)
+	})
+}
+
+func TestParseStringOrDefault(t *testing.T) {
+	defaults, err := ParseString("server { host: localhost, port: 8080 }, debug: false")
+	assertNoError(t, err)
+
+	t.Run("apply the defaults as the fallback of the parsed config", func(t *testing.T) {
+		got, err := ParseStringOrDefault("server.port: 9090, debug: true", defaults)
+		assertNoError(t, err)
+		assertDeepEqual(t, got.root, Object{"server": Object{"host": String("localhost"), "port": Int(9090)}, "debug": Boolean(true)})
+		assertEquals(t, defaults.GetInt("server.port"), 8080)
+	})
+
+	t.Run("return a copy of the defaults if the input is blank", func(t *testing.T) {
+		got, err := ParseStringOrDefault(" \n", defaults)
+		assertNoError(t, err)
+		assertDeepEqual(t, got.root, defaults.root)
+
+		got.root.(Object)["server"].(Object)["port"] = Int(1)
+		assertEquals(t, defaults.GetInt("server.port"), 8080)
+	})
+
+	t.Run("return the parsed config if the defaults are nil", func(t *testing.T) {
+		got, err := ParseStringOrDefault("a: 1", nil)
+		assertNoError(t, err)
+		assertDeepEqual(t, got.root, Object{"a": Int(1)})
+	})
+
+	t.Run("return the parse error", func(t *testing.T) {
+		got, err := ParseStringOrDefault("{.a:1}", defaults)
+		assertError(t, err, leadingPeriodError(1, 2))
+		assertNil(t, got)
+	})
+}
+
+func TestParseStringWithBase(t *testing.T) {
+	t.Run("resolve the relative include paths against the given base directory", func(t *testing.T) {
+		got, err := ParseStringWithBase(`b:2, include "a.conf"`, "testdata")
+		assertNoError(t, err)
+		assertDeepEqual(t, got, parsedConfig(Object{"a": Int(1), "b": Int(2)}))
+	})
+
+	t.Run("resolve the absolute include paths as they are", func(t *testing.T) {
+		wd, err := os.Getwd()
+		assertNoError(t, err)
+		got, err := ParseStringWithBase(fmt.Sprintf(`include "%s/testdata/a.conf"`, wd), "nonExistDir")
+		assertNoError(t, err)
+		assertDeepEqual(t, got, parsedConfig(Object{"a": Int(1)}))
+	})
 }
 
 func TestParseResource(t *testing.T) {
@@ -34,7 +359,138 @@ func TestParseResource(t *testing.T) {
 	t.Run("parse and return a pointer to the config if there is no error", func(t *testing.T) {
 		got, err := ParseResource("testdata/array.conf")
 		assertNoError(t, err)
-		assertDeepEqual(t, got, &Config{Array{Int(1), Int(2), Int(3)}})
+		assertDeepEqual(t, got, parsedConfig(Array{Int(1), Int(2), Int(3)}))
+	})
+
+	t.Run("parse the resource with the .json extension as strict JSON", func(t *testing.T) {
+		got, err := ParseResource("testdata/strict.json")
+		assertNoError(t, err)
+		assertDeepEqual(t, got.root, Object{"a": Int(1), "b": Array{String("c")}})
+	})
+
+	t.Run("return invalidJSONError if the resource with the .json extension contains HOCON syntax", func(t *testing.T) {
+		got, err := ParseResource("testdata/lenient.json")
+		expectedError := inFile(invalidJSONError("invalid character 'a' looking for beginning of object key string", 2, 3), "testdata/lenient.json")
+		assertError(t, err, expectedError)
+		assertNil(t, got)
+	})
+
+	t.Run("report the path of the resource in the parse errors like ParseFile", func(t *testing.T) {
+		resourcePath := filepath.Join(t.TempDir(), "x.conf")
+		assertNoError(t, os.WriteFile(resourcePath, []byte("a: {b"), 0o600))
+
+		got, resourceErr := ParseResource(resourcePath)
+		assertError(t, resourceErr, inFile(invalidObjectError("parenthesis do not match", 1, 5), resourcePath))
+		assertNil(t, got)
+
+		f, err := os.Open(resourcePath)
+		assertNoError(t, err)
+
+		defer f.Close()
+
+		_, err = ParseFile(f)
+		assertError(t, err, resourceErr)
+	})
+
+	t.Run("parse the resource with the .json extension as HOCON if the syntax is overridden", func(t *testing.T) {
+		got, err := ParseResource("testdata/lenient.json", WithSyntax(HOCONSyntax))
+		assertNoError(t, err)
+		assertDeepEqual(t, got.root, Object{"a": Int(1)})
+	})
+
+	t.Run("return invalidJSONError if the string is parsed as strict JSON", func(t *testing.T) {
+		got, err := ParseString(`{"a": 1} # comment`, WithSyntax(JSONSyntax))
+		expectedError := invalidJSONError("invalid character '#' after top-level value", 1, 10)
+		assertError(t, err, expectedError)
+		assertNil(t, got)
+	})
+}
+
+func TestParseFile(t *testing.T) {
+	t.Run("parse the open file resolving the includes against its directory", func(t *testing.T) {
+		f, err := os.Open("testdata/x.conf")
+		assertNoError(t, err)
+
+		defer f.Close()
+
+		got, err := ParseFile(f)
+		assertNoError(t, err)
+		assertDeepEqual(t, got.root, Object{"a": Int(1), "y": String("foo"), "x": Int(7)})
+
+		_, err = f.Stat()
+		assertNoError(t, err) // the file is left open
+	})
+
+	t.Run("select the syntax by the name of the file", func(t *testing.T) {
+		f, err := os.Open("testdata/lenient.json")
+		assertNoError(t, err)
+
+		defer f.Close()
+
+		got, err := ParseFile(f)
+		assertError(t, err, inFile(invalidJSONError("invalid character 'a' looking for beginning of object key string", 2, 3), "testdata/lenient.json"))
+		assertNil(t, got)
+	})
+
+	t.Run("report the name of the file in the parse errors", func(t *testing.T) {
+		f, err := os.CreateTemp(t.TempDir(), "*.conf")
+		assertNoError(t, err)
+
+		defer f.Close()
+
+		_, err = f.WriteString("a: 1\nb: {")
+		assertNoError(t, err)
+		_, err = f.Seek(0, io.SeekStart)
+		assertNoError(t, err)
+
+		got, err := ParseFile(f)
+		assertError(t, err, inFile(invalidObjectError("parenthesis do not match", 2, 5), f.Name()))
+		assertNil(t, got)
+	})
+}
+
+func TestParseStream(t *testing.T) {
+	t.Run("parse the documents of the stream resolving their substitutions independently", func(t *testing.T) {
+		input := "{a: 1}\n{a: 2, b: ${a}} {c: 3}\n# comment\n[1, 2] // trailing comment\n"
+		got, err := ParseStream(strings.NewReader(input))
+		assertNoError(t, err)
+		assertEquals(t, len(got), 4)
+		assertDeepEqual(t, got[0].root, Value(Object{"a": Int(1)}))
+		assertDeepEqual(t, got[1].root, Value(Object{"a": Int(2), "b": Int(2)}))
+		assertDeepEqual(t, got[2].root, Value(Object{"c": Int(3)}))
+		assertDeepEqual(t, got[3].root, Value(Array{Int(1), Int(2)}))
+	})
+
+	t.Run("parse the last document without braces and return no documents for a blank input", func(t *testing.T) {
+		got, err := ParseStream(strings.NewReader("{a: 1}\nb: 2\nc: 3"))
+		assertNoError(t, err)
+		assertEquals(t, len(got), 2)
+		assertDeepEqual(t, got[1].root, Value(Object{"b": Int(2), "c": Int(3)}))
+
+		got, err = ParseStream(strings.NewReader(" \n "))
+		assertNoError(t, err)
+		assertEquals(t, len(got), 0)
+	})
+
+	t.Run("report the index of the malformed document and the position in the whole input", func(t *testing.T) {
+		got, err := ParseStream(strings.NewReader("{a: 1}\n{b: 2}  {c: }"))
+		assertError(t, err, fmt.Errorf("could not parse the document at index: 2, %w", invalidValueError(`unknown value: "}"`, 2, 13)))
+		assertNil(t, got)
+
+		_, err = ParseStream(strings.NewReader("{a: 1}\n}"))
+		assertError(t, err, fmt.Errorf("could not parse the document at index: 1, %w", invalidObjectError("invalid token }", 2, 1)))
+
+		_, err = ParseStream(strings.NewReader("{a: ${missing}}"))
+		assertError(t, err, errors.New("could not parse the document at index: 0, could not resolve substitution: ${missing} to a value"))
+	})
+
+	t.Run("report the position in the whole input after the whitespaces between the documents", func(t *testing.T) {
+		_, err := ParseStream(strings.NewReader("{a: 1}\n\n  {c: }"))
+		assertError(t, err, fmt.Errorf("could not parse the document at index: 1, %w", invalidValueError(`unknown value: "}"`, 3, 7)))
+
+		_, err = ParseStream(strings.NewReader("{\"a\": 1}\n  {\"b\": x}"), WithSyntax(JSONSyntax))
+		assertError(t, err, fmt.Errorf("could not parse the document at index: 1, %w",
+			invalidJSONError("invalid character 'x' looking for beginning of value", 2, 9)))
 	})
 }
 
@@ -51,7 +507,7 @@ func TestParse(t *testing.T) {
 		parser := newParser(strings.NewReader("[5]"))
 		got, err := parser.parse()
 		assertNoError(t, err)
-		assertDeepEqual(t, got, &Config{Array{Int(5)}})
+		assertDeepEqual(t, got, parsedConfig(Array{Int(5)}))
 	})
 
 	t.Run("return the same error if any error occurs in the extractObject method", func(t *testing.T) {
@@ -78,11 +534,19 @@ func TestParse(t *testing.T) {
 		assertNil(t, got)
 	})
 
+	t.Run("recover from the panics and return them as ParseError", func(t *testing.T) {
+		predicate := func(IncludeToken) bool { panic("unexpected include") }
+		parser := newParser(strings.NewReader(`include "a.conf"`), WithIncludePredicate(predicate))
+		got, err := parser.parse()
+		assertError(t, err, internalError("unexpected include", 1, 9))
+		assertNil(t, got)
+	})
+
 	t.Run("parse as object if the input does not start with '['", func(t *testing.T) {
 		parser := newParser(strings.NewReader("{a:42}"))
 		got, err := parser.parse()
 		assertNoError(t, err)
-		assertDeepEqual(t, got, &Config{Object{"a": Int(42)}})
+		assertDeepEqual(t, got, parsedConfig(Object{"a": Int(42)}))
 	})
 
 	// ###############################################################
@@ -91,52 +555,145 @@ func TestParse(t *testing.T) {
 		parser := newParser(strings.NewReader(`{a:"b"}`))
 		got, err := parser.parse()
 		assertNoError(t, err)
-		assertDeepEqual(t, got, &Config{Object{"a": String("b")}})
+		assertDeepEqual(t, got, parsedConfig(Object{"a": String("b")}, "a"))
 	})
 
 	t.Run("parse simple array", func(t *testing.T) {
 		parser := newParser(strings.NewReader(`["a", "b"]`))
 		got, err := parser.parse()
 		assertNoError(t, err)
-		assertDeepEqual(t, got, &Config{Array{String("a"), String("b")}})
+		assertDeepEqual(t, got, parsedConfig(Array{String("a"), String("b")}, "0", "1"))
 	})
 
 	t.Run("parse nested object", func(t *testing.T) {
 		parser := newParser(strings.NewReader(`{a: {c: "d"}}`))
 		got, err := parser.parse()
 		assertNoError(t, err)
-		assertDeepEqual(t, got, &Config{Object{"a": Object{"c": String("d")}}})
+		assertDeepEqual(t, got, parsedConfig(Object{"a": Object{"c": String("d")}}, "a.c"))
 	})
 
 	t.Run("parse with the omitted root braces", func(t *testing.T) {
 		parser := newParser(strings.NewReader("a=1"))
 		got, err := parser.parse()
 		assertNoError(t, err)
-		assertDeepEqual(t, got, &Config{Object{"a": Int(1)}})
+		assertDeepEqual(t, got, parsedConfig(Object{"a": Int(1)}))
 	})
 
 	t.Run("parse the path key", func(t *testing.T) {
 		parser := newParser(strings.NewReader(`{a.b:"c"}`))
 		got, err := parser.parse()
 		assertNoError(t, err)
-		assertDeepEqual(t, got, &Config{Object{"a": Object{"b": String("c")}}})
+		assertDeepEqual(t, got, parsedConfig(Object{"a": Object{"b": String("c")}}, "a.b"))
 	})
 
 	t.Run("parse the path key that contains a hyphen", func(t *testing.T) {
 		parser := newParser(strings.NewReader(`a.b-1: "c"`))
 		got, err := parser.parse()
 		assertNoError(t, err)
-		assertDeepEqual(t, got, &Config{Object{"a": Object{"b-1": String("c")}}})
+		assertDeepEqual(t, got, parsedConfig(Object{"a": Object{"b-1": String("c")}}, "a.b-1"))
 	})
 
 	t.Run("parse the nested object with a key containing a hyphen", func(t *testing.T) {
 		parser := newParser(strings.NewReader(`{a: {b-1: "c"}}`))
 		got, err := parser.parse()
 		assertNoError(t, err)
-		assertDeepEqual(t, got, &Config{Object{"a": Object{"b-1": String("c")}}})
+		assertDeepEqual(t, got, parsedConfig(Object{"a": Object{"b-1": String("c")}}, "a.b-1"))
 	})
 }
 
+func TestParseJSON(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		input    string
+		expected Value
+	}{
+		{"empty object", `{}`, Object{}},
+		{"empty array", `[]`, Array(nil)},
+		{"scalars", `{"s": "a", "i": 10, "f": 0.5, "t": true, "n": null}`, Object{"s": String("a"), "i": Int(10), "f": Float64(0.5), "t": Boolean(true), "n": null}},
+		{"negative numbers", `{"i": -1, "f": -1.5e3, "e": 1e10}`, Object{"i": Int(-1), "f": Float64(-1500), "e": Float64(1e10)}},
+		{"escaped characters", `{"a": "x\"y", "b": "A\n\/", "c": "\u00e9"}`, Object{"a": String(`x"y`), "b": String("A\n/"), "c": String("é")}},
+		{"empty string and empty key", `{"": ""}`, Object{"": String("")}},
+		{"keys with forbidden characters and periods", `{"a b": {"c.d": 1, "$": 2, ".": 3}}`, Object{"a b": Object{"c.d": Int(1), "$": Int(2), ".": Int(3)}}},
+		{"comment characters in strings", `{"a": "#b", "c": "//d"}`, Object{"a": String("#b"), "c": String("//d")}},
+		{"nested arrays", `{"a": [1, [2, [3]]], "b": [[]]}`, Object{"a": Array{Int(1), Array{Int(2), Array{Int(3)}}}, "b": Array{Array(nil)}}},
+		{"array of objects", `[{"a": 1}, {}, [true, false]]`, Array{Object{"a": Int(1)}, Object{}, Array{Boolean(true), Boolean(false)}}},
+		{"multi-line document", "{\n  \"a\": 1,\n  \"b\": [\n    1,\n    2\n  ]\n}\n", Object{"a": Int(1), "b": Array{Int(1), Int(2)}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseString(tc.input)
+			assertNoError(t, err)
+			assertDeepEqual(t, got.root, tc.expected)
+		})
+	}
+}
+
+func TestErrorPositions(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"after a multi-line string", "a: \"\"\"x\ny\nz\"\"\"\nb: {.c: 1}", leadingPeriodError(4, 5).Error()},
+		{"on the last line of a multi-line string", "a: \"\"\"x\n\n\ny\"\"\" {.b: 1}", leadingPeriodError(4, 7).Error()},
+		{"in an array after a multi-line string", "a: [\"\"\"x\ny\"\"\", 1 2]", missingCommaError(2, 9).Error()},
+		{"after an include", "x: 1\ninclude \"testdata/a.conf\"\ny: {.z: 1}", leadingPeriodError(3, 5).Error()},
+		{"after a multi-line string and an include", "a: \"\"\"x\ny\"\"\"\ninclude \"testdata/a.conf\"\nb: {.c: 1}", leadingPeriodError(4, 5).Error()},
+		{
+			"in an included file after a multi-line string",
+			"x: 1\n\n\ninclude \"testdata/invalid_multiline.conf\"",
+			"leading period '.' at: testdata/invalid_multiline.conf:5:5, (use quoted \"\" empty string if you want an empty element)",
+		},
+		{
+			"in a nested included file",
+			"a: \"\"\"x\ny\"\"\"\ninclude \"testdata/nested_invalid.conf\"",
+			"leading period '.' at: testdata/invalid_multiline.conf:5:5, (use quoted \"\" empty string if you want an empty element)",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseString(tc.input)
+			assertError(t, err, errors.New(tc.expected))
+			assertNil(t, got)
+		})
+	}
+}
+
+func TestMismatchedBrackets(t *testing.T) {
+	array := func(line, column int) bracket { return bracket{token: arrayStartToken, line: line, column: column} }
+	object := func(line, column int) bracket { return bracket{token: objectStartToken, line: line, column: column} }
+
+	var testCases = []struct {
+		name     string
+		input    string
+		expected error
+	}{
+		{"array closed with a brace", "a = [1, 2}", mismatchedBracketsError(array(1, 5), objectEndToken, 1, 10)},
+		{"array closed with a brace after a comma", "a = [1, }", mismatchedBracketsError(array(1, 5), objectEndToken, 1, 9)},
+		{"empty array closed with a brace", "a = [}", mismatchedBracketsError(array(1, 5), objectEndToken, 1, 6)},
+		{"root array closed with a brace", "[1, 2}", mismatchedBracketsError(array(1, 1), objectEndToken, 1, 6)},
+		{"object closed with a bracket", "a = {b = 1]", mismatchedBracketsError(object(1, 5), arrayEndToken, 1, 11)},
+		{"object closed with a bracket after a comma", "a = {b = 1, ]}", mismatchedBracketsError(object(1, 5), arrayEndToken, 1, 13)},
+		{"empty object closed with a bracket", "a = {]}", mismatchedBracketsError(object(1, 5), arrayEndToken, 1, 6)},
+		{"root object closed with a bracket", "{a = 1]", mismatchedBracketsError(object(1, 1), arrayEndToken, 1, 7)},
+		{"object in an array closed with a bracket", "a = [{b = 1]", mismatchedBracketsError(object(1, 6), arrayEndToken, 1, 12)},
+		{"array in an object closed with a brace", "a = {b = [1}", mismatchedBracketsError(array(1, 10), objectEndToken, 1, 12)},
+		{"array in an array closed with a brace", "a = [[1}]", mismatchedBracketsError(array(1, 6), objectEndToken, 1, 8)},
+		{"object in an object closed with a bracket", "a = {b = {c = 1]}", mismatchedBracketsError(object(1, 10), arrayEndToken, 1, 16)},
+		{"closer on another line", "a = {\n  b = 1\n]", mismatchedBracketsError(object(1, 5), arrayEndToken, 3, 1)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseString(tc.input)
+			assertError(t, err, tc.expected)
+			assertNil(t, got)
+		})
+	}
+}
+
 func TestExtractObject(t *testing.T) {
 	t.Run("extract empty object", func(t *testing.T) {
 		parser := newParser(strings.NewReader("{}"))
@@ -202,6 +759,142 @@ func TestExtractObject(t *testing.T) {
 		assertDeepEqual(t, got, expected)
 	})
 
+	t.Run("seed the root object with an include at the top of the input and merge the later keys over it", func(t *testing.T) {
+		got, err := ParseString("# base\ninclude \"testdata/plugins.conf\"\nname = web\nserver { port = 80 }")
+		assertNoError(t, err)
+		assertDeepEqual(t, got.GetRoot(), Object{
+			"plugins": Array{String("core")},
+			"server":  Object{"filters": Array{String("auth")}, "port": Int(80)},
+			"name":    String("web"),
+		})
+	})
+
+	t.Run("parse the includes following each other", func(t *testing.T) {
+		for _, input := range []string{
+			"include \"testdata/a.conf\"\ninclude \"testdata/plugins.conf\"\nb: 2",
+			"include \"testdata/a.conf\", include \"testdata/plugins.conf\", b: 2",
+			"{include \"testdata/a.conf\"\n// comment\ninclude \"testdata/plugins.conf\"\nb: 2}",
+		} {
+			got, err := ParseString(input)
+			assertNoError(t, err)
+			assertDeepEqual(t, got.GetRoot(), Object{
+				"a":       Int(1),
+				"b":       Int(2),
+				"plugins": Array{String("core")},
+				"server":  Object{"filters": Array{String("auth")}},
+				"name":    String("app"),
+			})
+		}
+	})
+
+	t.Run("return an error if the commas after an include are adjacent", func(t *testing.T) {
+		_, err := ParseString(`include "testdata/a.conf",, b: 2`)
+		assertError(t, err, adjacentCommasError(1, 27))
+	})
+
+	t.Run("override the existing key with the included one by default", func(t *testing.T) {
+		parser := newParser(strings.NewReader(`a:5, include "testdata/a.conf"`))
+		parser.advance()
+		got, err := parser.extractObject()
+		assertNoError(t, err)
+		assertDeepEqual(t, got, Object{"a": Int(1)})
+	})
+
+	t.Run("keep the existing key if the includes are additive", func(t *testing.T) {
+		parser := newParser(strings.NewReader(`a:5, b:2, include "testdata/a.conf"`), WithAdditiveIncludes())
+		parser.advance()
+		got, err := parser.extractObject()
+		assertNoError(t, err)
+		assertDeepEqual(t, got, Object{"a": Int(5), "b": Int(2)})
+	})
+
+	t.Run("add the missing keys from the additive include", func(t *testing.T) {
+		parser := newParser(strings.NewReader(`b:2, include "testdata/a.conf"`), WithAdditiveIncludes())
+		parser.advance()
+		got, err := parser.extractObject()
+		assertNoError(t, err)
+		assertDeepEqual(t, got, Object{"a": Int(1), "b": Int(2)})
+	})
+
+	t.Run("combine the arrays of the include with the arrays set before and after it as selected by the merge policy", func(t *testing.T) {
+		input := "plugins = [app]\ninclude \"testdata/plugins.conf\"\nserver { filters = [cors] }\nname = web"
+		expected := map[ArrayMergePolicy]Object{
+			ReplaceArrays: {"plugins": Array{String("core")}, "server": Object{"filters": Array{String("cors")}}, "name": String("web")},
+			AppendArrays:  {"plugins": Array{String("app"), String("core")}, "server": Object{"filters": Array{String("cors"), String("auth")}}, "name": String("web")},
+			PrependArrays: {"plugins": Array{String("core"), String("app")}, "server": Object{"filters": Array{String("auth"), String("cors")}}, "name": String("web")},
+		}
+
+		for policy, object := range expected {
+			parser := newParser(strings.NewReader(input), WithIncludeArrayMerge(policy))
+			parser.advance()
+			got, err := parser.extractObject()
+			assertNoError(t, err)
+			assertDeepEqual(t, got, object)
+		}
+	})
+
+	t.Run("replace the combined array with the later definitions", func(t *testing.T) {
+		parser := newParser(strings.NewReader("include \"testdata/plugins.conf\"\nplugins = [app]\nplugins = [other]"), WithIncludeArrayMerge(AppendArrays))
+		parser.advance()
+		got, err := parser.extractObject()
+		assertNoError(t, err)
+		assertDeepEqual(t, got["plugins"], Array{String("other")})
+	})
+
+	t.Run("extract the quoted include keyword as a literal key", func(t *testing.T) {
+		parser := newParser(strings.NewReader(`"include" = 1, a { "include": "b" }`))
+		parser.advance()
+		expected := Object{"include": Int(1), "a": Object{"include": String("b")}}
+		got, err := parser.extractObject()
+		assertNoError(t, err)
+		assertDeepEqual(t, got, expected)
+	})
+
+	t.Run("extract the numbers with the units as strings", func(t *testing.T) {
+		config, err := ParseString("a: 512MB, b: 512 megabytes, c: 30 seconds, d: -1 KiB, e: 2b")
+		assertNoError(t, err)
+		assertDeepEqual(t, config.root, Object{"a": String("512MB"), "b": String("512 megabytes"), "c": Duration(30 * time.Second),
+			"d": String("-1 KiB"), "e": String("2b")})
+		assertEquals(t, config.GetByteSize("b"), int64(512e6))
+	})
+
+	t.Run("return an error if the number is followed by an identifier that is not a size unit", func(t *testing.T) {
+		for input, expectedError := range map[string]error{
+			"a: 512MB\ne: 1 f: 2":  missingCommaError(2, 6),
+			"a: 1 b, c: 2":         missingCommaError(1, 6),
+			"a: 1 foo, c: 2":       missingCommaError(1, 6),
+			"a: 1.5 parsecs, c: 2": missingCommaError(1, 8),
+		} {
+			parser := newParser(strings.NewReader(input))
+			parser.advance()
+			got, err := parser.extractObject()
+			assertError(t, err, expectedError)
+			assertNil(t, got)
+		}
+
+		got, err := ParseString("a: 1 b")
+		assertError(t, err, invalidObjectError("invalid token b", 1, 6))
+		assertNil(t, got)
+	})
+
+	t.Run("extract the keyword keys", func(t *testing.T) {
+		parser := newParser(strings.NewReader(`true = 1, null: 2`))
+		parser.advance()
+		expected := Object{"true": Int(1), "null": Int(2)}
+		got, err := parser.extractObject()
+		assertNoError(t, err)
+		assertDeepEqual(t, got, expected)
+	})
+
+	t.Run("extract the unquoted key that starts with a digit", func(t *testing.T) {
+		parser := newParser(strings.NewReader(`3d: 1, 10: 2`))
+		parser.advance()
+		expected := Object{"3d": Int(1), "10": Int(2)}
+		got, err := parser.extractObject()
+		assertNoError(t, err)
+		assertDeepEqual(t, got, expected)
+	})
+
 	t.Run("parse correctly if the last line is a comment", func(t *testing.T) {
 		config := `{
 			a: 1
@@ -217,7 +910,7 @@ func TestExtractObject(t *testing.T) {
 
 	for forbiddenChar := range forbiddenCharacters {
 		t.Run(fmt.Sprintf("return error if the key contains the forbidden character: %q", forbiddenChar), func(t *testing.T) {
-			if forbiddenChar != "`" && forbiddenChar != `"` && forbiddenChar != "}" && forbiddenChar != "#" {
+			if forbiddenChar != "`" && forbiddenChar != `"` && forbiddenChar != "}" && forbiddenChar != "]" && forbiddenChar != "#" {
 				parser := newParser(strings.NewReader(fmt.Sprintf("{%s:1}", forbiddenChar)))
 				parser.advance()
 				expectedError := invalidKeyError(forbiddenChar, 1, 2)
@@ -576,14 +1269,14 @@ func TestMergeObjects(t *testing.T) {
 
 func TestResolveSubstitutions(t *testing.T) {
 	t.Run("resolve valid substitution at the root level", func(t *testing.T) {
-		object := Object{"a": Int(5), "b": &Substitution{"a", false}}
+		object := Object{"a": Int(5), "b": &Substitution{path: "a", optional: false}}
 		err := resolveSubstitutions(object)
 		assertNoError(t, err)
 	})
 
 	t.Run("resolve to the environment variable if substitution path does not exist and an environment variable is set with the substitution path", func(t *testing.T) {
 		testEnv := "TEST_ENV"
-		substitution := &Substitution{testEnv, false}
+		substitution := &Substitution{path: testEnv, optional: false}
 		object := Object{"a": Int(5), "b": substitution}
 		err := os.Setenv(testEnv, "test")
 		assertNoError(t, err)
@@ -637,7 +1330,7 @@ func TestResolveSubstitutions(t *testing.T) {
 	})
 
 	t.Run("return an error for non-existing substitution path", func(t *testing.T) {
-		substitution := &Substitution{"c", false}
+		substitution := &Substitution{path: "c", optional: false}
 		object := Object{"a": Int(5), "b": substitution}
 		err := resolveSubstitutions(object)
 		expectedError := errors.New("could not resolve substitution: " + substitution.String() + " to a value")
@@ -645,27 +1338,96 @@ func TestResolveSubstitutions(t *testing.T) {
 	})
 
 	t.Run("ignore the optional substitution if it's path does not exist", func(t *testing.T) {
-		object := Object{"a": Int(5), "b": &Substitution{"c", true}}
+		object := Object{"a": Int(5), "b": &Substitution{path: "c", optional: true}}
 		err := resolveSubstitutions(object)
 		assertNoError(t, err)
+		assertDeepEqual(t, object, Object{"a": Int(5)})
+	})
+
+	t.Run("leave the fields and the array elements of the unresolved optional substitutions undefined", func(t *testing.T) {
+		config, err := ParseString("x: ${?MISSING_OPTIONAL}, a { y: ${?MISSING_OPTIONAL} }, l: [1, ${?MISSING_OPTIONAL}, [${?MISSING_OPTIONAL}]]")
+		assertNoError(t, err)
+		assertEquals(t, config.HasPath("x"), false)
+		assertEquals(t, config.HasPath("a.y"), false)
+		assertDeepEqual(t, config.root, Object{"a": Object{}, "l": Array{Int(1), Array{}}})
+
+		config, err = ParseString("[${?MISSING_OPTIONAL}, 1]")
+		assertNoError(t, err)
+		assertDeepEqual(t, config.root, Array{Int(1)})
+	})
+
+	t.Run("resolve the unresolved optional substitutions of the string concatenations to empty strings", func(t *testing.T) {
+		config, err := ParseString("x: ${?MISSING_OPTIONAL} foo, y: a${?MISSING_OPTIONAL}b")
+		assertNoError(t, err)
+		assertEquals(t, config.GetString("x"), " foo")
+		assertEquals(t, config.GetString("y"), "ab")
+		assertEquals(t, config.String(), "{x: foo, y:ab}")
 	})
 
 	t.Run("resolve valid substitution at the non-root level", func(t *testing.T) {
-		subObject := Object{"c": &Substitution{"a", false}}
+		subObject := Object{"c": &Substitution{path: "a", optional: false}}
 		object := Object{"a": Int(5), "b": subObject}
 		err := resolveSubstitutions(object, subObject)
 		assertNoError(t, err)
 	})
 
+	t.Run("resolve the chained substitutions to the final value", func(t *testing.T) {
+		object := Object{"a": Int(1), "b": &Substitution{path: "c", optional: false}, "c": &Substitution{path: "a", optional: false}}
+		err := resolveSubstitutions(object)
+		assertNoError(t, err)
+		assertDeepEqual(t, object, Object{"a": Int(1), "b": Int(1), "c": Int(1)})
+	})
+
+	t.Run("resolve the self-referential alternative to the previous value", func(t *testing.T) {
+		object := Object{"a": &valueWithAlternative{value: Int(1), alternative: &Substitution{path: "a", optional: false}}}
+		err := resolveSubstitutions(object)
+		assertNoError(t, err)
+		assertDeepEqual(t, object, Object{"a": Int(1)})
+	})
+
+	t.Run("resolve the substitution with the quoted path segments", func(t *testing.T) {
+		object := Object{
+			"foo.bar": Object{"baz": Int(1)},
+			"a":       &Substitution{path: `"foo.bar".baz`, optional: false},
+			"b":       &Substitution{path: `foo."bar"`, optional: true},
+		}
+		err := resolveSubstitutions(object)
+		assertNoError(t, err)
+		assertDeepEqual(t, object["a"], Int(1))
+		assertDeepEqual(t, object["b"], nil)
+	})
+
+	t.Run("return an error if the substitution refers to itself", func(t *testing.T) {
+		object := Object{"a": &Substitution{path: "a", optional: false}}
+		err := resolveSubstitutions(object)
+		expectedErr := fmt.Errorf("%w: a -> a", ErrSubstitutionCycle)
+		assertError(t, err, expectedErr)
+	})
+
+	t.Run("return an error if the substitutions refer to each other in a cycle", func(t *testing.T) {
+		object := Object{"a": &Substitution{path: "b", optional: false}, "b": &Substitution{path: "a", optional: false}}
+		err := resolveSubstitutions(object)
+		if !errors.Is(err, ErrSubstitutionCycle) {
+			t.Fatalf("expected error: %q, got: %v", ErrSubstitutionCycle, err)
+		}
+	})
+
+	t.Run("return an error if the substitution refers to its parent object", func(t *testing.T) {
+		object := Object{"a": Object{"b": &Substitution{path: "a", optional: false}}}
+		err := resolveSubstitutions(object)
+		expectedErr := fmt.Errorf("%w: a -> a.b -> a", ErrSubstitutionCycle)
+		assertError(t, err, expectedErr)
+	})
+
 	t.Run("return invalid concatenation error if the concatenation contains an object and a different type", func(t *testing.T) {
-		substitution := &Substitution{"a", false}
+		substitution := &Substitution{path: "a", optional: false}
 		object := Object{"a": Int(5), "b": concatenation{Object{"aa": Int(1)}, substitution}}
 		err := resolveSubstitutions(object)
 		assertError(t, err, invalidConcatenationError())
 	})
 
 	t.Run("resolve the substitution in concatenation and merge the objects if the concatenation's every element is object", func(t *testing.T) {
-		substitution := &Substitution{"a", false}
+		substitution := &Substitution{path: "a", optional: false}
 		object := Object{"bb": Int(1)}
 		root := Object{"a": Object{"aa": Int(5)}, "b": concatenation{object, substitution}}
 		expected := Object{"aa": Int(5), "bb": Int(1)}
@@ -676,14 +1438,14 @@ func TestResolveSubstitutions(t *testing.T) {
 	})
 
 	t.Run("resolve valid substitution inside an array", func(t *testing.T) {
-		subArray := Array{&Substitution{"a", false}}
+		subArray := Array{&Substitution{path: "a", optional: false}}
 		object := Object{"a": Int(5), "b": subArray}
 		err := resolveSubstitutions(object, subArray)
 		assertNoError(t, err)
 	})
 
 	t.Run("return error for non-existing substitution path inside an array", func(t *testing.T) {
-		substitution := &Substitution{"c", false}
+		substitution := &Substitution{path: "c", optional: false}
 		subArray := Array{substitution}
 		object := Object{"a": Int(5), "b": subArray}
 		err := resolveSubstitutions(object, subArray)
@@ -692,21 +1454,21 @@ func TestResolveSubstitutions(t *testing.T) {
 	})
 
 	t.Run("ignore the optional substitution inside an array if it's path does not exist", func(t *testing.T) {
-		subArray := Array{&Substitution{"a", true}}
+		subArray := Array{&Substitution{path: "a", optional: true}}
 		object := Object{"a": Int(5), "b": subArray}
 		err := resolveSubstitutions(object, subArray)
 		assertNoError(t, err)
 	})
 
 	t.Run("resolve valid substitution inside a concatenation", func(t *testing.T) {
-		concatenation := concatenation{&Substitution{"a", false}}
+		concatenation := concatenation{&Substitution{path: "a", optional: false}}
 		object := Object{"a": Int(5), "b": concatenation}
 		err := resolveSubstitutions(object, concatenation)
 		assertNoError(t, err)
 	})
 
 	t.Run("return error for non-existing substitution path inside an concatenation", func(t *testing.T) {
-		substitution := &Substitution{"c", false}
+		substitution := &Substitution{path: "c", optional: false}
 		concatenation := concatenation{substitution}
 		object := Object{"a": Int(5), "b": concatenation}
 		err := resolveSubstitutions(object, concatenation)
@@ -715,7 +1477,7 @@ func TestResolveSubstitutions(t *testing.T) {
 	})
 
 	t.Run("ignore the optional substitution inside an concatenation if it's path does not exist", func(t *testing.T) {
-		concatenation := concatenation{&Substitution{"a", true}}
+		concatenation := concatenation{&Substitution{path: "a", optional: true}}
 		object := Object{"a": Int(5), "b": concatenation}
 		err := resolveSubstitutions(object, concatenation)
 		assertNoError(t, err)
@@ -759,28 +1521,289 @@ func TestResolveSubstitutions(t *testing.T) {
 		}}
 		got, err := parser.extractObject()
 		assertNoError(t, err)
-		assertDeepEqual(t, got, expected)
+		assertDeepEqual(t, got, expected)
+	})
+
+	t.Run("extract valueWithAlternative value with boolean type", func(t *testing.T) {
+		parser := newParser(strings.NewReader("a: true, a:${?b}"))
+		expected := Object{"a": &valueWithAlternative{
+			value:       Boolean(true),
+			alternative: &Substitution{path: "b", optional: true},
+		}}
+		got, err := parser.extractObject()
+		assertNoError(t, err)
+		assertDeepEqual(t, got, expected)
+	})
+
+	t.Run("extract valueWithAlternative value and overwrite alternatives", func(t *testing.T) {
+		parser := newParser(strings.NewReader("a: static, a:${?b}"))
+		expected := Object{
+			"a": &valueWithAlternative{value: String("static"), alternative: &Substitution{path: "b", optional: true}},
+		}
+		got, err := parser.extractObject()
+		assertNoError(t, err)
+		assertDeepEqual(t, got, expected)
+	})
+}
+
+func TestLazyResolution(t *testing.T) {
+	t.Run("resolve the substitutions on access and cache the resolved values", func(t *testing.T) {
+		config, err := ParseString("a: ${b}, b: 1, c: { d: ${a} }, e: ${c}", WithLazyResolution())
+		assertNoError(t, err)
+
+		object := config.root.(Object)
+		assertEquals(t, object["a"].Type(), SubstitutionType)
+
+		assertEquals(t, config.GetInt("c.d"), 1)
+		assertEquals(t, object["c"].(Object)["d"], Value(Int(1)))
+		assertEquals(t, object["a"].Type(), SubstitutionType)
+		assertEquals(t, object["e"].Type(), SubstitutionType)
+
+		assertEquals(t, config.GetInt("a"), 1)
+		assertEquals(t, object["a"], Value(Int(1)))
+		assertDeepEqual(t, config.ResolutionReport(), ResolutionReport{Resolved: []string{"a", "b"}, SubstitutionSources: []string{"a", "b"}})
+	})
+
+	t.Run("look up the values concurrently while they are resolved", func(t *testing.T) {
+		config, err := ParseString("a: ${b}, b: ${c}, c: 1, d: { e: ${a}, f: [${b}, ${?missing}] }", WithLazyResolution())
+		assertNoError(t, err)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func(path string) {
+				defer wg.Done()
+				_ = config.Get(path)
+				_ = config.ResolutionReport()
+			}([]string{"a", "b", "d.e", "d.f", "d"}[i%5])
+		}
+
+		wg.Wait()
+		assertDeepEqual(t, config.GetRoot(), Value(Object{"a": Int(1), "b": Int(1), "c": Int(1), "d": Object{"e": Int(1), "f": Array{Int(1)}}}))
+	})
+
+	t.Run("resolve the substitutions on the path to the value", func(t *testing.T) {
+		config, err := ParseString("a: ${b}\nb: { c: [${d}, 2] }\nd: 1\ne: ${b}\ne: { f: 3 }", WithLazyResolution())
+		assertNoError(t, err)
+		assertEquals(t, config.GetInt("a.c.0"), 1)
+		assertEquals(t, config.GetInt("b.c.-2"), 1)
+		assertEquals(t, config.GetInt("e.f"), 3)
+		assertEquals(t, config.GetInt("e.c.1"), 2)
+	})
+
+	t.Run("do not fail the parsing for the substitutions that are not accessed", func(t *testing.T) {
+		config, err := ParseString("a: ${missing}, b: 1", WithLazyResolution())
+		assertNoError(t, err)
+		assertEquals(t, config.GetInt("b"), 1)
+		assertPanic(t, func() { config.GetInt("a") }, "could not resolve substitution: ${missing} to a value")
+	})
+
+	t.Run("detect the cycles on access", func(t *testing.T) {
+		config, err := ParseString("a: ${b}, b: ${a}", WithLazyResolution())
+		assertNoError(t, err)
+		assertPanic(t, func() { config.Get("a") }, "substitution cycle: a -> b -> a")
+	})
+
+	t.Run("resolve the whole tree if the root is accessed", func(t *testing.T) {
+		config, err := ParseString("a: ${b}, b: 1, c: [${b}]", WithLazyResolution())
+		assertNoError(t, err)
+		assertDeepEqual(t, config.GetRoot(), Value(Object{"a": Int(1), "b": Int(1), "c": Array{Int(1)}}))
+		assertEquals(t, config.String(), "{a:1, b:1, c:[1]}")
+	})
+
+	t.Run("resolve the remaining substitutions with the Resolve method and report the error", func(t *testing.T) {
+		config, err := ParseString("a: ${b}, b: 1, c: [${b}]", WithLazyResolution())
+		assertNoError(t, err)
+		assertNoError(t, config.Resolve())
+		assertDeepEqual(t, config.root, Value(Object{"a": Int(1), "b": Int(1), "c": Array{Int(1)}}))
+
+		config, err = ParseString("a: ${missing}, b: 1", WithLazyResolution())
+		assertNoError(t, err)
+		assertError(t, config.Resolve(), errors.New("could not resolve substitution: ${missing} to a value"))
+
+		config, err = ParseString("a: ${b}, b: ${a}", WithLazyResolution())
+		assertNoError(t, err)
+		if err := config.Resolve(); !errors.Is(err, ErrSubstitutionCycle) {
+			t.Fatalf("expected a substitution cycle error, got: %v", err)
+		}
+	})
+
+	t.Run("return nil from the Resolve method if the config is not parsed lazily", func(t *testing.T) {
+		config, err := ParseString("a: ${b}, b: 1")
+		assertNoError(t, err)
+		assertNoError(t, config.Resolve())
+	})
+}
+
+func TestUnquotedValueWhitespace(t *testing.T) {
+	var testCases = []struct {
+		description string
+		input       string
+		expected    string
+	}{
+		{"trim the leading and the trailing whitespaces", "a =   a  b   ", "a  b"},
+		{"keep the internal whitespace runs as written", "a: x   y  z, b: 1", "x   y  z"},
+		{"keep the tabs between the values", "a = hello \t  world  \n", "hello \t  world"},
+		{"keep the whitespaces around the quoted strings", "a: x  \"q\"  y  // comment", "x  q  y"},
+		{"keep the whitespaces between the non-string values", "a = true   false", "true   false"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			got, err := ParseString(tc.input)
+			assertNoError(t, err)
+			assertEquals(t, got.GetString("a"), tc.expected)
+		})
+	}
+
+	t.Run("render the concatenation with its whitespaces", func(t *testing.T) {
+		got, err := ParseString("a = x \t y")
+		assertNoError(t, err)
+		assertEquals(t, got.String(), "{a:x \t y}")
+	})
+}
+
+func TestAllMissingSubstitutions(t *testing.T) {
+	t.Run("report all the missing required substitutions with their positions", func(t *testing.T) {
+		input := "host: ${DB_HOST}\nport: ${?DB_PORT}\nurl: ${host}\nuser: ${DB_USER}\ninclude \"testdata/missing.conf\""
+		_, err := ParseString(input, WithAllMissingSubstitutions(), WithEnvFallback(false))
+
+		var missingErr *MissingSubstitutionsError
+		if !errors.As(err, &missingErr) {
+			t.Fatalf("expected a MissingSubstitutionsError, got: %v", err)
+		}
+
+		assertDeepEqual(t, missingErr.Missing, []MissingSubstitution{
+			{Path: "DB_HOST", Line: 1, Column: 7},
+			{Path: "DB_USER", Line: 4, Column: 7},
+			{Path: "DB_PASSWORD", File: "testdata/missing.conf", Line: 2, Column: 13},
+		})
+		assertError(t, err, errors.New("could not resolve the substitutions to a value: ${DB_HOST} at: 1:7, ${DB_USER} at: 4:7, "+
+			"${DB_PASSWORD} at: testdata/missing.conf:2:13"))
+	})
+
+	t.Run("parse the config if all the required substitutions are resolved", func(t *testing.T) {
+		got, err := ParseString("a: 1, b: ${a}, c: ${?missing}", WithAllMissingSubstitutions())
+		assertNoError(t, err)
+		assertEquals(t, got.GetInt("b"), 1)
+	})
+
+	t.Run("fail on the first missing substitution without the option", func(t *testing.T) {
+		_, err := ParseString("a: ${MISSING}, b: 1", WithEnvFallback(false))
+		assertError(t, err, errors.New("could not resolve substitution: ${MISSING} to a value"))
+	})
+}
+
+func TestNestedSubstitutions(t *testing.T) {
+	t.Run("resolve the nested substitutions first to form the path", func(t *testing.T) {
+		got, err := ParseString(`profile: prod, profiles { prod.url: p, dev.url: d }, url: ${profiles.${profile}.url}, key: ${${name}}, name: profile`,
+			WithNestedSubstitutions())
+		assertNoError(t, err)
+		assertEquals(t, got.GetString("url"), "p")
+		assertEquals(t, got.GetString("key"), "prod")
+	})
+
+	t.Run("leave the field undefined if an optional nested substitution is not resolved", func(t *testing.T) {
+		got, err := ParseString("a: ${?b.${?missing}}, b.c: 1", WithNestedSubstitutions())
+		assertNoError(t, err)
+		assertEquals(t, got.HasPath("a"), false)
+
+		_, err = ParseString("a: ${b.${?missing}}, b.c: 1", WithNestedSubstitutions())
+		assertError(t, err, errors.New("could not resolve substitution: ${b.${?missing}} to a value"))
+	})
+
+	t.Run("return an error if the nested substitution does not resolve to a string", func(t *testing.T) {
+		_, err := ParseString("a: ${b.${c}}, c: [1]", WithNestedSubstitutions())
+		assertError(t, err, errors.New("could not resolve substitution: ${b.${c}}, the nested substitution: ${c} does not resolve to a string"))
+	})
+
+	t.Run("detect the cycles through the nested substitutions", func(t *testing.T) {
+		_, err := ParseString("a: ${x.${b}}, b: ${a}", WithNestedSubstitutions())
+		if !errors.Is(err, ErrSubstitutionCycle) {
+			t.Fatalf("expected a substitution cycle error, got: %v", err)
+		}
+	})
+}
+
+func TestSubstitutionResolver(t *testing.T) {
+	secrets := map[string]Value{"DB_PASSWORD": String("secret"), "HOCON_TEST_SOURCE": String("from resolver"), "db.url": &Substitution{path: "host"}}
+	resolver := func(path string) (Value, bool) {
+		value, ok := secrets[path]
+		return value, ok
+	}
+
+	t.Run("resolve the substitutions that are not found in the config with the resolver", func(t *testing.T) {
+		got, err := ParseString("host: localhost, password: ${DB_PASSWORD}, url: ${db.url}", WithSubstitutionResolver(resolver))
+		assertNoError(t, err)
+		assertDeepEqual(t, got.root, Object{"host": String("localhost"), "password": String("secret"), "url": String("localhost")})
+		assertDeepEqual(t, got.ResolutionReport().FromResolver, []string{"DB_PASSWORD", "db.url"})
+	})
+
+	t.Run("prefer the values of the config over the resolver", func(t *testing.T) {
+		got, err := ParseString("DB_PASSWORD: local, password: ${DB_PASSWORD}", WithSubstitutionResolver(resolver))
+		assertNoError(t, err)
+		assertEquals(t, got.GetString("password"), "local")
+	})
+
+	t.Run("consult the environment variables before the resolver by default", func(t *testing.T) {
+		t.Setenv("HOCON_TEST_SOURCE", "from env")
+
+		got, err := ParseString("a: ${HOCON_TEST_SOURCE}", WithSubstitutionResolver(resolver))
+		assertNoError(t, err)
+		assertEquals(t, got.GetString("a"), "from env")
+
+		got, err = ParseString("a: ${HOCON_TEST_SOURCE}", WithSubstitutionResolver(resolver), WithResolverBeforeEnv())
+		assertNoError(t, err)
+		assertEquals(t, got.GetString("a"), "from resolver")
+	})
+
+	t.Run("return an error if the substitution is not found in the resolver either", func(t *testing.T) {
+		got, err := ParseString("a: ${missing}", WithSubstitutionResolver(resolver))
+		assertError(t, err, errors.New("could not resolve substitution: ${missing} to a value"))
+		assertNil(t, got)
+	})
+
+	t.Run("ignore a nil resolver", func(t *testing.T) {
+		got, err := ParseString("a: ${?missing}", WithSubstitutionResolver(nil))
+		assertNoError(t, err)
+		assertDeepEqual(t, got.ResolutionReport().UnresolvedOptional, []string{"missing"})
+	})
+}
+
+func TestArrayRootSubstitutions(t *testing.T) {
+	t.Run("resolve the substitutions of an array root against the array itself", func(t *testing.T) {
+		got, err := ParseString("[1, ${0}, {a: 2}, ${2.a}]")
+		assertNoError(t, err)
+		assertDeepEqual(t, got.root, Array{Int(1), Int(1), Object{"a": Int(2)}, Int(2)})
+		assertDeepEqual(t, got.ResolutionReport().Resolved, []string{"0", "2.a"})
+	})
+
+	t.Run("resolve the substitutions of an array root with the environment variables", func(t *testing.T) {
+		t.Setenv("HOCON_ARRAY_ROOT", "env")
+		got, err := ParseString("[${HOCON_ARRAY_ROOT}]")
+		assertNoError(t, err)
+		assertDeepEqual(t, got.root, Array{String("env")})
 	})
 
-	t.Run("extract valueWithAlternative value with boolean type", func(t *testing.T) {
-		parser := newParser(strings.NewReader("a: true, a:${?b}"))
-		expected := Object{"a": &valueWithAlternative{
-			value:       Boolean(true),
-			alternative: &Substitution{path: "b", optional: true},
-		}}
-		got, err := parser.extractObject()
+	t.Run("return an error if a substitution of an array root cannot be resolved", func(t *testing.T) {
+		got, err := ParseString("[${x}]")
+		assertError(t, err, errors.New("could not resolve substitution: ${x} to a value"))
+		assertNil(t, got)
+	})
+
+	t.Run("resolve the substitutions of an array root lazily", func(t *testing.T) {
+		got, err := ParseString("[1, ${0}, ${x}]", WithLazyResolution())
 		assertNoError(t, err)
-		assertDeepEqual(t, got, expected)
+		assertEquals(t, got.GetInt("1"), 1)
+		assertPanic(t, func() { got.Get("2") }, "could not resolve substitution: ${x} to a value")
 	})
 
-	t.Run("extract valueWithAlternative value and overwrite alternatives", func(t *testing.T) {
-		parser := newParser(strings.NewReader("a: static, a:${?b}"))
-		expected := Object{
-			"a": &valueWithAlternative{value: String("static"), alternative: &Substitution{path: "b", optional: true}},
-		}
-		got, err := parser.extractObject()
+	t.Run("omit the unresolved optional substitutions of an array root lazily", func(t *testing.T) {
+		got, err := ParseString("[1, ${?x}, 3, ${?y}]", WithLazyResolution())
 		assertNoError(t, err)
-		assertDeepEqual(t, got, expected)
+		assertEquals(t, got.GetInt("1"), 3)
+		assertNil(t, got.Get("2"))
+		assertDeepEqual(t, got.GetRoot(), Value(Array{Int(1), Int(3)}))
 	})
 }
 
@@ -830,6 +1853,77 @@ func TestParsePlusEqualsValue(t *testing.T) {
 		assertNoError(t, err)
 		assertDeepEqual(t, existingItems, expected)
 	})
+
+	t.Run("append the object as a new element without merging it into the existing objects", func(t *testing.T) {
+		got, err := ParseString("a: [{b: 1}], a += {b: 2}")
+		assertNoError(t, err)
+		assertDeepEqual(t, got.Get("a"), Array{Object{"b": Int(1)}, Object{"b": Int(2)}})
+	})
+
+	t.Run("concatenate the substitution with an array of the value if the existingItems map contains a substitution with the given key", func(t *testing.T) {
+		parser := newParser(strings.NewReader("a: ${b}, a += 42"))
+		advanceScanner(t, parser, "42")
+		substitution := &Substitution{path: "b"}
+		existingItems := Object{"a": substitution}
+		expected := Object{"a": concatenation{substitution, Array{Int(42)}}}
+		err := parser.parsePlusEqualsValue(existingItems, "a")
+		assertNoError(t, err)
+		assertDeepEqual(t, existingItems, expected)
+	})
+
+	t.Run("append to the arrays of an included file and of the earlier definitions of the enclosing objects", func(t *testing.T) {
+		got, err := ParseString(`include "testdata/plugins.conf"
+			plugins += extra
+			server { filters += cors }
+			server.filters += gzip
+			wrapper { include "testdata/plugins.conf" }
+			wrapper { plugins += nested }`)
+		assertNoError(t, err)
+		assertDeepEqual(t, got.Get("plugins"), Array{String("core"), String("extra")})
+		assertDeepEqual(t, got.Get("server.filters"), Array{String("auth"), String("cors"), String("gzip")})
+		assertDeepEqual(t, got.Get("wrapper.plugins"), Array{String("core"), String("nested")})
+	})
+
+	t.Run("return an error if the included value to append to is not an array", func(t *testing.T) {
+		got, err := ParseString("include \"testdata/plugins.conf\"\nname += other")
+		assertError(t, err, invalidValueError(fmt.Sprintf("value: %q of the key: %q is not an array", "app", "name"), 2, 14))
+		assertNil(t, got)
+	})
+
+	t.Run("do not append to the arrays of an earlier definition of an enclosing array", func(t *testing.T) {
+		got, err := ParseString("a: [{b: [1]}], a: [{b += 2}]")
+		assertNoError(t, err)
+		assertDeepEqual(t, got.Get("a"), Array{Object{"b": Array{Int(2)}}})
+	})
+
+	t.Run("append to the substitution that resolves to an array", func(t *testing.T) {
+		got, err := ParseString("b: [1, 2], a: ${b}, a += 3, a += 4")
+		assertNoError(t, err)
+		assertDeepEqual(t, got.Get("a"), Array{Int(1), Int(2), Int(3), Int(4)})
+		assertDeepEqual(t, got.Get("b"), Array{Int(1), Int(2)})
+	})
+
+	t.Run("append to the unresolved optional substitution as if the key does not exist", func(t *testing.T) {
+		got, err := ParseString("a: ${?nonExisting}, a += 1")
+		assertNoError(t, err)
+		assertDeepEqual(t, got.Get("a"), Array{Int(1)})
+	})
+
+	t.Run("return an error at the position of the substitution that does not resolve to an array", func(t *testing.T) {
+		got, err := ParseString("b: 1, a: ${b}, a += 3")
+		assertError(t, err, invalidValueError(fmt.Sprintf("value: %q of the key: %q is not an array", "1", "a"), 1, 10))
+		assertNil(t, got)
+
+		got, err = ParseString("b: 1\nc {\n  a: ${b}\n  a += 3\n}")
+		assertError(t, err, invalidValueError(fmt.Sprintf("value: %q of the key: %q is not an array", "1", "a"), 3, 6))
+		assertNil(t, got)
+	})
+
+	t.Run("merge the object concatenation of a nested field in place instead of at the root", func(t *testing.T) {
+		got, err := ParseString("x: {p: 1}\na { b: ${x}\n b: {q: 2} }")
+		assertNoError(t, err)
+		assertDeepEqual(t, got.root, Object{"x": Object{"p": Int(1)}, "a": Object{"b": Object{"p": Int(1), "q": Int(2)}}})
+	})
 }
 
 func TestValidateIncludeValue(t *testing.T) {
@@ -909,7 +2003,7 @@ func TestValidateIncludeValue(t *testing.T) {
 		parser := newParser(strings.NewReader(`include file("abc.conf")`))
 		advanceScanner(t, parser, "file")
 		got, err := parser.validateIncludeValue()
-		expected := &include{path: "abc.conf", required: false}
+		expected := &include{path: "abc.conf", required: false, kind: IncludeFile}
 		assertNoError(t, err)
 		assertDeepEqual(t, got, expected)
 	})
@@ -917,7 +2011,7 @@ func TestValidateIncludeValue(t *testing.T) {
 	t.Run("return the include token containing the path in classpath(...) with quotes removed and required as 'false'", func(t *testing.T) {
 		parser := newParser(strings.NewReader(`include classpath("abc.conf")`))
 		advanceScanner(t, parser, "classpath")
-		expected := &include{path: "abc.conf", required: false}
+		expected := &include{path: "abc.conf", required: false, kind: IncludeClasspath}
 		got, err := parser.validateIncludeValue()
 		assertNoError(t, err)
 		assertDeepEqual(t, got, expected)
@@ -945,7 +2039,7 @@ func TestValidateIncludeValue(t *testing.T) {
 		parser := newParser(strings.NewReader(`include required(file("abc.conf"))`))
 		advanceScanner(t, parser, "required")
 		got, err := parser.validateIncludeValue()
-		expected := &include{path: "abc.conf", required: true}
+		expected := &include{path: "abc.conf", required: true, kind: IncludeFile}
 		assertNoError(t, err)
 		assertDeepEqual(t, got, expected)
 	})
@@ -953,7 +2047,7 @@ func TestValidateIncludeValue(t *testing.T) {
 	t.Run("return the include token containing the path in required(classpath(...)) with quotes removed and required as 'true'", func(t *testing.T) {
 		parser := newParser(strings.NewReader(`include required(classpath("abc.conf"))`))
 		advanceScanner(t, parser, "required")
-		expected := &include{path: "abc.conf", required: true}
+		expected := &include{path: "abc.conf", required: true, kind: IncludeClasspath}
 		got, err := parser.validateIncludeValue()
 		assertNoError(t, err)
 		assertDeepEqual(t, got, expected)
@@ -987,6 +2081,161 @@ func TestParseIncludedResource(t *testing.T) {
 		assertNil(t, object)
 	})
 
+	t.Run("open the included resource with the include resolver", func(t *testing.T) {
+		resources := map[string]string{"virtual.conf": "a: 1", "nested.conf": "include \"virtual.conf\"\nb: 2"}
+		resolver := func(token IncludeToken) (io.ReadCloser, error) {
+			content, ok := resources[token.Path]
+			if !ok {
+				return nil, fmt.Errorf("could not find %s: %w", token.Path, os.ErrNotExist)
+			}
+
+			return io.NopCloser(strings.NewReader(content)), nil
+		}
+
+		parser := newParser(strings.NewReader(`include "nested.conf"`), WithIncludeResolver(resolver))
+		advanceScanner(t, parser, `"nested.conf"`)
+		got, err := parser.parseIncludedResource()
+		assertNoError(t, err)
+		assertDeepEqual(t, got, Object{"a": Int(1), "b": Int(2)})
+
+		parser = newParser(strings.NewReader(`include "nonExisting.conf"`), WithIncludeResolver(resolver))
+		advanceScanner(t, parser, `"nonExisting.conf"`)
+		got, err = parser.parseIncludedResource()
+		assertNoError(t, err)
+		assertDeepEqual(t, got, Object{})
+
+		parser = newParser(strings.NewReader(`include required("nonExisting.conf")`), WithIncludeResolver(resolver))
+		advanceScanner(t, parser, "required")
+		got, err = parser.parseIncludedResource()
+		assertError(t, err, fmt.Errorf("could not parse resource: could not find nonExisting.conf: %w", os.ErrNotExist))
+		assertNil(t, got)
+	})
+
+	t.Run("resolve the nested includes against the directory of the including resource with the include resolver", func(t *testing.T) {
+		resources := map[string]string{
+			"conf/app.conf":     "include \"db/db.conf\"\napp: 1",
+			"conf/db/db.conf":   "include required(\"pool.conf\")\ndb: 2",
+			"conf/db/pool.conf": "pool: 3",
+			"db/db.conf":        "db: 4", // the paths as they are written are not used for the nested includes
+			"pool.conf":         "pool: 5",
+		}
+
+		var requested []string
+
+		resolver := func(token IncludeToken) (io.ReadCloser, error) {
+			requested = append(requested, token.Path)
+
+			content, ok := resources[token.Path]
+			if !ok {
+				return nil, fmt.Errorf("could not find %s: %w", token.Path, os.ErrNotExist)
+			}
+
+			return io.NopCloser(strings.NewReader(content)), nil
+		}
+
+		got, err := ParseString(`include "conf/app.conf"`, WithIncludeResolver(resolver))
+		assertNoError(t, err)
+		assertDeepEqual(t, got.root, Object{"app": Int(1), "db": Int(2), "pool": Int(3)})
+		assertDeepEqual(t, requested, []string{"conf/app.conf", "conf/db/db.conf", "conf/db/pool.conf"})
+	})
+
+	t.Run("return an error if the included resource exceeds the maximum include size", func(t *testing.T) {
+		parser := newParser(strings.NewReader(`include "testdata/a.conf"`), WithMaxIncludeSize(2))
+		advanceScanner(t, parser, `"testdata/a.conf"`)
+		got, err := parser.parseIncludedResource()
+		assertError(t, err, errors.New("could not parse resource: testdata/a.conf exceeds the maximum include size of 2 bytes"))
+		assertNil(t, got)
+	})
+
+	t.Run("read the resource up to the maximum include size", func(t *testing.T) {
+		for input, exceeded := range map[string]bool{"abc": false, "abcd": true} {
+			limited := &limitedReader{reader: iotest.OneByteReader(strings.NewReader(input)), remaining: 3}
+			got, err := io.ReadAll(limited)
+			assertNoError(t, err)
+			assertEquals(t, string(got), "abc")
+			assertEquals(t, limited.exceeded, exceeded)
+		}
+	})
+
+	t.Run("parse the included resource within the maximum include size", func(t *testing.T) {
+		parser := newParser(strings.NewReader(`include "testdata/a.conf"`), WithMaxIncludeSize(3))
+		advanceScanner(t, parser, `"testdata/a.conf"`)
+		got, err := parser.parseIncludedResource()
+		assertNoError(t, err)
+		assertDeepEqual(t, got, Object{"a": Int(1)})
+	})
+
+	t.Run("expand the environment variables in the include path", func(t *testing.T) {
+		t.Setenv("HOCON_INCLUDE_NAME", "a")
+		parser := newParser(strings.NewReader(`include file("testdata/${HOCON_INCLUDE_NAME}.conf")`))
+		advanceScanner(t, parser, "file")
+		got, err := parser.parseIncludedResource()
+		assertNoError(t, err)
+		assertDeepEqual(t, got, Object{"a": Int(1)})
+	})
+
+	t.Run("expand the unset environment variables in the include path to empty string", func(t *testing.T) {
+		parser := newParser(strings.NewReader(`include required("testdata/a${HOCON_UNSET_INCLUDE_NAME}.conf")`))
+		advanceScanner(t, parser, "required")
+		got, err := parser.parseIncludedResource()
+		assertNoError(t, err)
+		assertDeepEqual(t, got, Object{"a": Int(1)})
+	})
+
+	t.Run("return an error for the unset environment variables in the include path if they are strict", func(t *testing.T) {
+		parser := newParser(strings.NewReader(`include "testdata/${HOCON_UNSET_INCLUDE_NAME}.conf"`), WithStrictIncludeVariables())
+		advanceScanner(t, parser, `"testdata/${HOCON_UNSET_INCLUDE_NAME}.conf"`)
+		expectedError := invalidValueError(`environment variable "HOCON_UNSET_INCLUDE_NAME" in the include path is not set`, 1, 9)
+		got, err := parser.parseIncludedResource()
+		assertError(t, err, expectedError)
+		assertNil(t, got)
+	})
+
+	t.Run("merge only the subtree of the include fragment", func(t *testing.T) {
+		got, err := ParseString("include \"testdata/db.conf#connection\"\nport: 1\ninclude file(\"testdata/db.conf#pool\") # comment\n")
+		assertNoError(t, err)
+		assertDeepEqual(t, got.root, Object{"host": String("localhost"), "port": Int(1), "size": Int(10)})
+	})
+
+	t.Run("pass the include fragment to the include predicate", func(t *testing.T) {
+		var fragment string
+		predicate := func(token IncludeToken) bool {
+			fragment = token.Fragment
+			return true
+		}
+
+		_, err := ParseString(`include required(file("testdata/db.conf#connection"))`, WithIncludePredicate(predicate))
+		assertNoError(t, err)
+		assertEquals(t, fragment, "connection")
+	})
+
+	t.Run("treat a hash after the include value as a comment", func(t *testing.T) {
+		for _, input := range []string{"include \"testdata/a.conf\"#note\nb: 2", "include \"testdata/a.conf\"# note\nb: 2",
+			"include file(\"testdata/a.conf\")#note\nb: 2"} {
+			got, err := ParseString(input)
+			assertNoError(t, err)
+			assertDeepEqual(t, got.root, Object{"a": Int(1), "b": Int(2)})
+		}
+	})
+
+	t.Run("ignore a missing include fragment of an optional include", func(t *testing.T) {
+		got, err := ParseString("include \"testdata/db.conf#missing\"\nb: 2")
+		assertNoError(t, err)
+		assertDeepEqual(t, got.root, Object{"b": Int(2)})
+	})
+
+	t.Run("return an error for a missing include fragment of a required include", func(t *testing.T) {
+		got, err := ParseString(`b: 1, include required("testdata/db.conf#missing")`)
+		assertError(t, err, invalidIncludeFragmentError("testdata/db.conf does not contain the fragment: missing", 1, 15))
+		assertNil(t, got)
+	})
+
+	t.Run("return an error if the include fragment is not an object", func(t *testing.T) {
+		got, err := ParseString(`include "testdata/db.conf#name"`)
+		assertError(t, err, invalidIncludeFragmentError("the fragment: name of testdata/db.conf is not an object", 1, 9))
+		assertNil(t, got)
+	})
+
 	t.Run("return an error if the included file contains an array as the value", func(t *testing.T) {
 		parser := newParser(strings.NewReader(`include "testdata/array.conf"`))
 		advanceScanner(t, parser, `"testdata/array.conf"`)
@@ -996,6 +2245,44 @@ func TestParseIncludedResource(t *testing.T) {
 		assertNil(t, object)
 	})
 
+	t.Run("skip the include if the include predicate returns false", func(t *testing.T) {
+		var received IncludeToken
+		predicate := func(token IncludeToken) bool { received = token; return false }
+		parser := newParser(strings.NewReader(`include required(file("testdata/a.conf"))`), WithIncludePredicate(predicate))
+		advanceScanner(t, parser, "required")
+		got, err := parser.parseIncludedResource()
+		assertNoError(t, err)
+		assertDeepEqual(t, got, Object{})
+		assertEquals(t, received, IncludeToken{Path: "testdata/a.conf", Required: true, Kind: IncludeFile})
+	})
+
+	t.Run("record the includes without loading them in the dry run mode", func(t *testing.T) {
+		got, err := ParseString(`include required(file("missing/app.conf"))
+			a { include classpath("lib.conf") }
+			include "testdata/db.conf#connection"
+			b: 1`, WithDryRunIncludes())
+		assertNoError(t, err)
+		assertDeepEqual(t, got.root, Object{"a": Object{}, "b": Int(1)})
+		assertDeepEqual(t, got.Includes(), []IncludeToken{
+			{Path: "missing/app.conf", Required: true, Kind: IncludeFile},
+			{Path: "lib.conf", Kind: IncludeClasspath},
+			{Path: "testdata/db.conf", Kind: IncludeQuoted, Fragment: "connection"},
+		})
+	})
+
+	t.Run("validate the include syntax in the dry run mode", func(t *testing.T) {
+		got, err := ParseString("include required(abc.conf", WithDryRunIncludes())
+		assertError(t, err, invalidValueError("missing closing parenthesis", 1, 21))
+		assertNil(t, got)
+	})
+
+	t.Run("process the include if the include predicate returns true", func(t *testing.T) {
+		predicate := func(token IncludeToken) bool { return token.Path == "testdata/a.conf" }
+		got, err := ParseString("include \"testdata/a.conf\"\nb: 2", WithIncludePredicate(predicate))
+		assertNoError(t, err)
+		assertDeepEqual(t, got, parsedConfig(Object{"a": Int(1), "b": Int(2)}))
+	})
+
 	t.Run("parse the included resource and return the parsed object if there is no error", func(t *testing.T) {
 		parser := newParser(strings.NewReader(`include "testdata/x.conf"`))
 		advanceScanner(t, parser, `"testdata/x.conf"`)
@@ -1015,6 +2302,23 @@ func TestExtractArray(t *testing.T) {
 		assertNil(t, got)
 	})
 
+	t.Run("skip the comments between the array elements", func(t *testing.T) {
+		parser := newParser(strings.NewReader("[ # first\n 1 # one\n, 2, # two\n 3 # last\n]"))
+		parser.advance()
+		got, err := parser.extractArray()
+		assertNoError(t, err)
+		assertDeepEqual(t, got, Array{Int(1), Int(2), Int(3)})
+	})
+
+	t.Run("extract the bare keywords as typed values except include, and the quoted keywords as strings", func(t *testing.T) {
+		parser := newParser(strings.NewReader(`[true, false, null, include, "true", "null", "include", other]`))
+		parser.advance()
+		got, err := parser.extractArray()
+		assertNoError(t, err)
+		assertDeepEqual(t, got, Array{Boolean(true), Boolean(false), null, String("include"),
+			String("true"), String("null"), String("include"), String("other")})
+	})
+
 	t.Run("return leadingCommaError if the array starts with a comma", func(t *testing.T) {
 		parser := newParser(strings.NewReader("[,1]"))
 		parser.advance()
@@ -1032,6 +2336,37 @@ func TestExtractArray(t *testing.T) {
 		assertEquals(t, len(got), 0)
 	})
 
+	t.Run("extract the arrays of arrays", func(t *testing.T) {
+		parser := newParser(strings.NewReader("[[1,2],[3,4]]"))
+		parser.advance()
+		got, err := parser.extractArray()
+		assertNoError(t, err)
+		assertDeepEqual(t, got, Array{Array{Int(1), Int(2)}, Array{Int(3), Int(4)}})
+	})
+
+	t.Run("extract the empty inner arrays", func(t *testing.T) {
+		parser := newParser(strings.NewReader("[[], []]"))
+		parser.advance()
+		got, err := parser.extractArray()
+		assertNoError(t, err)
+		assertDeepEqual(t, got, Array{Array(nil), Array(nil)})
+	})
+
+	t.Run("extract the inner arrays mixed with the other values", func(t *testing.T) {
+		parser := newParser(strings.NewReader("[[1],2,[[3]]]"))
+		parser.advance()
+		got, err := parser.extractArray()
+		assertNoError(t, err)
+		assertDeepEqual(t, got, Array{Array{Int(1)}, Int(2), Array{Array{Int(3)}}})
+	})
+
+	t.Run("continue the object after the nested arrays", func(t *testing.T) {
+		config, err := ParseString("matrix = [[1,2],[3,4]], b: 1")
+		assertNoError(t, err)
+		assertDeepEqual(t, config.Get("matrix.0.1"), Int(2))
+		assertDeepEqual(t, config.Get("b"), Int(1))
+	})
+
 	t.Run("return the error if any error occurs in extractValue method", func(t *testing.T) {
 		parser := newParser(strings.NewReader("[&a]"))
 		parser.advance()
@@ -1106,6 +2441,77 @@ func TestExtractValue(t *testing.T) {
 		assertEquals(t, got, Int(1))
 	})
 
+	t.Run("extract the identifiers other than null and the booleans as strings", func(t *testing.T) {
+		for _, identifier := range []string{"info", "localhost", "default", "snake_case", "kebab-case"} {
+			parser := newParser(strings.NewReader(identifier))
+			parser.advance()
+			got, err := parser.extractValue()
+			assertNoError(t, err)
+			assertEquals(t, got, Value(String(identifier)))
+		}
+	})
+
+	t.Run("return the overflow error for the integers that do not fit in int by default", func(t *testing.T) {
+		parser := newParser(strings.NewReader("99999999999999999999"))
+		parser.advance()
+		got, err := parser.extractValue()
+		assertError(t, err, &strconv.NumError{Func: "Atoi", Num: "99999999999999999999", Err: strconv.ErrRange})
+		assertNil(t, got)
+	})
+
+	var largeIntegerTestCases = []struct {
+		mode     LargeIntegerMode
+		input    string
+		expected Value
+	}{
+		{LargeIntegersAsFloat, "99999999999999999999", Float64(1e20)},
+		{LargeIntegersAsFloat, "-99999999999999999999", Float64(-1e20)},
+		{LargeIntegersAsString, "99999999999999999999", String("99999999999999999999")},
+		{LargeIntegersAsString, "-99999999999999999999", String("-99999999999999999999")},
+		{LargeIntegersAsString, "42", Int(42)},
+	}
+
+	for _, tc := range largeIntegerTestCases {
+		t.Run(fmt.Sprintf("extract the large integer: %s with the mode: %d", tc.input, tc.mode), func(t *testing.T) {
+			parser := newParser(strings.NewReader(tc.input), WithLargeIntegers(tc.mode))
+			parser.advance()
+			got, err := parser.extractValue()
+			assertNoError(t, err)
+			assertEquals(t, got, tc.expected)
+		})
+	}
+
+	t.Run("read the large integers with the getters", func(t *testing.T) {
+		config, err := ParseString("id: 18446744073709551616", WithLargeIntegers(LargeIntegersAsString))
+		assertNoError(t, err)
+		assertEquals(t, config.GetString("id"), "18446744073709551616")
+
+		config, err = ParseString("id: 18446744073709551616", WithLargeIntegers(LargeIntegersAsFloat))
+		assertNoError(t, err)
+		assertEquals(t, config.GetFloat64("id"), 18446744073709551616.0)
+	})
+
+	var leadingZeroTestCases = []struct {
+		input    string
+		expected Value
+	}{
+		{"0123", String("0123")},
+		{"0", Int(0)},
+		{"00", String("00")},
+		{"01.5", String("01.5")},
+		{"0.5", Float64(0.5)},
+	}
+
+	for _, tc := range leadingZeroTestCases {
+		t.Run(fmt.Sprintf("extract the number: %s keeping the leading zeros", tc.input), func(t *testing.T) {
+			parser := newParser(strings.NewReader(tc.input))
+			parser.advance()
+			got, err := parser.extractValue()
+			assertNoError(t, err)
+			assertEquals(t, got, tc.expected)
+		})
+	}
+
 	t.Run("extract int duration", func(t *testing.T) {
 		parser := newParser(strings.NewReader("a:1 second"))
 		advanceScanner(t, parser, "1")
@@ -1127,8 +2533,7 @@ func TestExtractValue(t *testing.T) {
 		advanceScanner(t, parser, "1.5")
 		got, err := parser.extractValue()
 		assertNoError(t, err)
-		expected := 1.5
-		assertEquals(t, got, Duration(time.Duration(expected)*time.Second))
+		assertEquals(t, got, Duration(1500*time.Millisecond))
 	})
 
 	t.Run("extract float value", func(t *testing.T) {
@@ -1139,6 +2544,22 @@ func TestExtractValue(t *testing.T) {
 		assertEquals(t, got, Float64(1.5))
 	})
 
+	t.Run("return an error if the float value overflows float64", func(t *testing.T) {
+		parser := newParser(strings.NewReader("a:1e400"))
+		advanceScanner(t, parser, "1e400")
+		got, err := parser.extractValue()
+		assertError(t, err, invalidValueError("the number: 1e400 is out of range", 1, 3))
+		assertNil(t, got)
+	})
+
+	t.Run("keep the float values that do not fit in float32 as float64", func(t *testing.T) {
+		parser := newParser(strings.NewReader("a:3.5e40"))
+		advanceScanner(t, parser, "3.5e40")
+		got, err := parser.extractValue()
+		assertNoError(t, err)
+		assertEquals(t, got, Float64(3.5e40))
+	})
+
 	t.Run("extract multi-line string", func(t *testing.T) {
 		config := `a: """
 			this is a
@@ -1220,7 +2641,7 @@ func TestExtractValue(t *testing.T) {
 	t.Run("extract substitution value", func(t *testing.T) {
 		parser := newParser(strings.NewReader("a:${b}"))
 		advanceScanner(t, parser, "$")
-		expected := &Substitution{"b", false}
+		expected := &Substitution{path: "b", optional: false}
 		got, err := parser.extractValue()
 		assertNoError(t, err)
 		assertDeepEqual(t, got, expected)
@@ -1299,6 +2720,34 @@ func TestExtractSubstitution(t *testing.T) {
 		assertNil(t, substitution)
 	})
 
+	t.Run("keep the quoted path segments of the substitution", func(t *testing.T) {
+		parser := newParser(strings.NewReader(`a:${"foo.bar".baz}`))
+		advanceScanner(t, parser, "$")
+		substitution, err := parser.extractSubstitution()
+		assertNoError(t, err)
+		assertDeepEqual(t, substitution, &Substitution{path: `"foo.bar".baz`, optional: false})
+	})
+
+	t.Run("return invalidSubstitutionError for a nested substitution without the WithNestedSubstitutions option", func(t *testing.T) {
+		parser := newParser(strings.NewReader("a:${b.${c}}"))
+		advanceScanner(t, parser, "$")
+		expectedError := invalidSubstitutionError("nested substitutions are not allowed in the path expression, "+
+			"use the WithNestedSubstitutions option to enable them", 1, 7)
+		substitution, err := parser.extractSubstitution()
+		assertError(t, err, expectedError)
+		assertNil(t, substitution)
+	})
+
+	t.Run("extract the nested substitutions of the path with the WithNestedSubstitutions option", func(t *testing.T) {
+		parser := newParser(strings.NewReader("a:${?b.${c}.${?d}e}"), WithNestedSubstitutions())
+		advanceScanner(t, parser, "$")
+		substitution, err := parser.extractSubstitution()
+		assertNoError(t, err)
+		assertDeepEqual(t, substitution, &Substitution{path: "b.${c}.${?d}e", optional: true, parts: []Value{
+			String("b."), &Substitution{path: "c"}, String("."), &Substitution{path: "d", optional: true}, String("e"),
+		}})
+	})
+
 	t.Run("return leadingPeriodError if the path expression starts with a period '.' ", func(t *testing.T) {
 		parser := newParser(strings.NewReader("a:${.a}"))
 		advanceScanner(t, parser, "$")
@@ -1308,10 +2757,18 @@ func TestExtractSubstitution(t *testing.T) {
 		assertNil(t, substitution)
 	})
 
-	t.Run("...", func(t *testing.T) {
+	t.Run("skip the comments inside the substitution", func(t *testing.T) {
+		parser := newParser(strings.NewReader("a:${ # comment\n b.c # note\n }"))
+		advanceScanner(t, parser, "$")
+		substitution, err := parser.extractSubstitution()
+		assertNoError(t, err)
+		assertDeepEqual(t, substitution, &Substitution{path: "b.c", optional: false})
+	})
+
+	t.Run("return missing closing parenthesis error if the comment comments out the closing parenthesis", func(t *testing.T) {
 		parser := newParser(strings.NewReader("a:${#comment}"))
 		advanceScanner(t, parser, "$")
-		expectedError := invalidSubstitutionError("comments are not allowed inside substitutions", 1, 5)
+		expectedError := invalidSubstitutionError("missing closing parenthesis", 1, 14)
 		substitution, err := parser.extractSubstitution()
 		assertError(t, err, expectedError)
 		assertNil(t, substitution)
@@ -1353,6 +2810,14 @@ func TestExtractSubstitution(t *testing.T) {
 		assertDeepEqual(t, substitution, expected)
 	})
 
+	t.Run("return invalidSubstitutionError if the input ends before the closing parenthesis", func(t *testing.T) {
+		parser := newParser(strings.NewReader("${a b"))
+		parser.advance()
+		got, err := parser.extractSubstitution()
+		assertError(t, err, invalidSubstitutionError("missing closing parenthesis", 1, 5))
+		assertNil(t, got)
+	})
+
 	t.Run("parse and return a pointer to the optional substitution", func(t *testing.T) {
 		parser := newParser(strings.NewReader("a:${?b.c}"))
 		advanceScanner(t, parser, "$")
@@ -1523,3 +2988,35 @@ func TestCheckAndConcatenate(t *testing.T) {
 		assertEquals(t, object.String(), expected.String())
 	})
 }
+
+func FuzzParseString(f *testing.F) {
+	for _, seed := range []string{"{a:1}", "[1, 2]", "a.b.c: ${?x}", `a: """x"""`, "a: [{b: 1}, 2s]", "a += 1, b: ${a}", "include \"x.conf\""} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		done := make(chan struct{})
+
+		go func() {
+			defer close(done)
+			_, _ = ParseString(input) // must not panic
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("parsing did not terminate for the input: %q", input)
+		}
+	})
+}
+
+// parsedConfig creates the Config that the parser returns for the given root without substitutions,
+// with the given paths recorded as quoted strings
+func parsedConfig(root Value, quotedPaths ...string) *Config {
+	quoted := map[string]bool{}
+	for _, path := range quotedPaths {
+		quoted[path] = true
+	}
+
+	return &Config{root: root, quoted: quoted, raw: map[string]string{}}
+}
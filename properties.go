@@ -0,0 +1,318 @@
+package hocon
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// ToProperties method flattens the Config into the lines of the Java .properties format, one key=value line
+// for each leaf value in sorted order of the keys. The keys of the nested values are joined with '.' and the elements
+// of the arrays are written with their indices (e.g. list.0=a), the null values and the empty objects and arrays
+// are omitted. The keys and the values are escaped as the java.util.Properties.store method does
+func (c *Config) ToProperties() []byte {
+	var builder strings.Builder
+
+	var write func(key string, value Value)
+	write = func(key string, value Value) {
+		childKey := func(child string) string {
+			if key == "" {
+				return child
+			}
+
+			return key + dotToken + child
+		}
+
+		switch v := value.(type) {
+		case Object:
+			for _, k := range v.sortedKeys() {
+				write(childKey(k), v[k])
+			}
+		case Array:
+			for i, element := range v {
+				write(childKey(strconv.Itoa(i)), element)
+			}
+		case nil, Null:
+		default:
+			builder.WriteString(escapeProperty(key, true))
+			builder.WriteString(equalsToken)
+			builder.WriteString(escapeProperty(propertyValue(v), false))
+			builder.WriteString("\n")
+		}
+	}
+
+	write("", c.GetRoot())
+
+	return []byte(builder.String())
+}
+
+func propertyValue(value Value) string {
+	switch v := value.(type) {
+	case String:
+		return string(v)
+	case Float64:
+		return strconv.FormatFloat(float64(v), 'f', -1, 64)
+	case Float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case Duration:
+		return formatDuration(time.Duration(v))
+	default:
+		return v.String()
+	}
+}
+
+// escapeProperty escapes the key or the value of a .properties line, the spaces are escaped everywhere in the keys
+// and only at the start of the values, the non-ASCII characters are written as \uXXXX escapes
+func escapeProperty(s string, isKey bool) string {
+	var builder strings.Builder
+
+	for i, r := range s {
+		switch {
+		case r == ' ':
+			if isKey || i == 0 {
+				builder.WriteString(`\ `)
+			} else {
+				builder.WriteRune(r)
+			}
+		case r == '\\':
+			builder.WriteString(`\\`)
+		case r == '\t':
+			builder.WriteString(`\t`)
+		case r == '\n':
+			builder.WriteString(`\n`)
+		case r == '\r':
+			builder.WriteString(`\r`)
+		case r == '\f':
+			builder.WriteString(`\f`)
+		case r == '=' || r == ':' || r == '#' || r == '!':
+			builder.WriteRune('\\')
+			builder.WriteRune(r)
+		case r < 0x20 || r > 0x7e:
+			for _, unit := range utf16.Encode([]rune{r}) {
+				builder.WriteString(fmt.Sprintf(`\u%04X`, unit))
+			}
+		default:
+			builder.WriteRune(r)
+		}
+	}
+
+	return builder.String()
+}
+
+// ParseProperties function reads the lines of the Java .properties format from the given reader and creates the Config,
+// the dotted keys are split into the nested objects and the values are kept as strings. The comments, the line
+// continuations and the escape sequences are handled as the java.util.Properties.load method does. If a key is set
+// both to a value and to an object (e.g. a=1 and a.b=2), the object wins. The objects whose keys are the indices
+// 0..n-1 (e.g. list.0 and list.1) are converted to arrays if indexedArrays is true
+func ParseProperties(r io.Reader, indexedArrays ...bool) (*Config, error) {
+	root := Object{}
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimLeft(scanner.Text(), " \t\f")
+
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+
+		startLine := lineNumber
+
+		for hasContinuation(line) && scanner.Scan() {
+			lineNumber++
+			line = line[:len(line)-1] + strings.TrimLeft(scanner.Text(), " \t\f")
+		}
+
+		key, value, err := splitProperty(line)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse the properties at line: %d, %w", startLine, err)
+		}
+
+		setProperty(root, strings.Split(key, dotToken), String(value))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("could not read the properties: %w", err)
+	}
+
+	if len(indexedArrays) > 0 && indexedArrays[0] {
+		return convertIndexedObjects(root).(Object).ToConfig(), nil
+	}
+
+	return root.ToConfig(), nil
+}
+
+// hasContinuation reports whether the line ends with an odd number of backslashes, so it continues on the next line
+func hasContinuation(line string) bool {
+	backslashes := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		backslashes++
+	}
+
+	return backslashes%2 == 1
+}
+
+// splitProperty splits the logical line into the unescaped key and value, the key ends at the first unescaped '=', ':'
+// or whitespace and the value starts after the separator and the whitespaces around it
+func splitProperty(line string) (string, string, error) {
+	keyEnd := len(line)
+	escaped := false
+
+	for i, r := range line {
+		if escaped {
+			escaped = false
+			continue
+		}
+
+		if r == '\\' {
+			escaped = true
+		} else if r == '=' || r == ':' || r == ' ' || r == '\t' || r == '\f' {
+			keyEnd = i
+			break
+		}
+	}
+
+	rest := strings.TrimLeft(line[keyEnd:], " \t\f")
+	if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+		rest = strings.TrimLeft(rest[1:], " \t\f")
+	}
+
+	key, err := unescapeProperty(line[:keyEnd])
+	if err != nil {
+		return "", "", err
+	}
+
+	value, err := unescapeProperty(rest)
+	if err != nil {
+		return "", "", err
+	}
+
+	return key, value, nil
+}
+
+func unescapeProperty(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+
+	var builder strings.Builder
+
+	var units []uint16 // the UTF-16 units of the \uXXXX escapes, decoded together to join the surrogate pairs
+
+	flushUnits := func() {
+		builder.WriteString(string(utf16.Decode(units)))
+		units = units[:0]
+	}
+
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			flushUnits()
+			builder.WriteByte(s[i])
+
+			continue
+		}
+
+		i++
+
+		if s[i] == 'u' {
+			if len(s)-i-1 < 4 {
+				return "", fmt.Errorf("malformed \\uxxxx escape: %q", s[i-1:])
+			}
+
+			unit, err := strconv.ParseUint(s[i+1:i+5], 16, 16)
+			if err != nil {
+				return "", fmt.Errorf("malformed \\uxxxx escape: %q", s[i-1:i+5])
+			}
+
+			units = append(units, uint16(unit))
+			i += 4
+
+			continue
+		}
+
+		flushUnits()
+
+		switch s[i] {
+		case 't':
+			builder.WriteByte('\t')
+		case 'n':
+			builder.WriteByte('\n')
+		case 'r':
+			builder.WriteByte('\r')
+		case 'f':
+			builder.WriteByte('\f')
+		default:
+			builder.WriteByte(s[i])
+		}
+	}
+
+	flushUnits()
+
+	return builder.String(), nil
+}
+
+// setProperty sets the value at the given keys creating the missing objects, the objects are kept
+// if they are set to a value and the values are replaced if they are set to an object
+func setProperty(object Object, keys []string, value Value) {
+	for _, key := range keys[:len(keys)-1] {
+		child, ok := object[key].(Object)
+		if !ok {
+			child = Object{}
+			object[key] = child
+		}
+
+		object = child
+	}
+
+	last := keys[len(keys)-1]
+	if _, ok := object[last].(Object); !ok {
+		object[last] = value
+	}
+}
+
+// convertIndexedObjects converts the objects whose keys are exactly the indices 0..n-1 to arrays recursively
+func convertIndexedObjects(value Value) Value {
+	object, ok := value.(Object)
+	if !ok {
+		return value
+	}
+
+	for key, child := range object {
+		object[key] = convertIndexedObjects(child)
+	}
+
+	if len(object) == 0 {
+		return object
+	}
+
+	indices := make([]int, 0, len(object))
+
+	for key := range object {
+		index, err := strconv.Atoi(key)
+		if err != nil || strconv.Itoa(index) != key {
+			return object
+		}
+
+		indices = append(indices, index)
+	}
+
+	sort.Ints(indices)
+
+	array := make(Array, len(indices))
+
+	for i, index := range indices {
+		if index != i {
+			return object
+		}
+
+		array[i] = object[strconv.Itoa(index)]
+	}
+
+	return array
+}
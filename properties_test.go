@@ -0,0 +1,101 @@
+package hocon
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestToProperties(t *testing.T) {
+	t.Run("flatten the config into the sorted key=value lines", func(t *testing.T) {
+		config, err := ParseString(`{
+			b { c: 1, d: 1.5, e: true, f: 1500ms }
+			a: text
+			list: [x, {y: 2}]
+			empty {}
+			none: null
+		}`)
+		assertNoError(t, err)
+
+		expected := "a=text\nb.c=1\nb.d=1.5\nb.e=true\nb.f=1500ms\nlist.0=x\nlist.1.y=2\n"
+		assertEquals(t, string(config.ToProperties()), expected)
+	})
+
+	t.Run("escape the special characters of the keys and the values", func(t *testing.T) {
+		config := &Config{root: Object{
+			"a b=c:d": String("  x = y # z"),
+			"path":    String(`C:\dir`),
+			"multi":   String("line1\nline2\ttab"),
+			"unicode": String("é😀"),
+		}}
+
+		expected := "a\\ b\\=c\\:d=\\  x \\= y \\# z\n" +
+			"multi=line1\\nline2\\ttab\n" +
+			"path=C\\:\\\\dir\n" +
+			"unicode=\\u00E9\\uD83D\\uDE00\n"
+		assertEquals(t, string(config.ToProperties()), expected)
+	})
+
+	t.Run("write the elements of the root array with their indices", func(t *testing.T) {
+		assertEquals(t, string((&Config{root: Array{Int(1), String("a")}}).ToProperties()), "0=1\n1=a\n")
+	})
+}
+
+func TestParseProperties(t *testing.T) {
+	t.Run("split the dotted keys into the nested objects", func(t *testing.T) {
+		input := "# comment\n! comment\n\n  a.b = 1\na.c:x y \nd e\nempty\n"
+		got, err := ParseProperties(strings.NewReader(input))
+		assertNoError(t, err)
+		assertDeepEqual(t, got.root, Object{"a": Object{"b": String("1"), "c": String("x y ")}, "d": String("e"), "empty": String("")})
+	})
+
+	t.Run("handle the line continuations and the escape sequences", func(t *testing.T) {
+		input := "multi = first, \\\n    second\nkey\\ with\\=sep = \\  tab\\tend\\\\\nunicode = \\u00E9\\uD83D\\uDE00"
+		got, err := ParseProperties(strings.NewReader(input))
+		assertNoError(t, err)
+		assertDeepEqual(t, got.root, Object{
+			"multi":        String("first, second"),
+			"key with=sep": String("  tab\tend\\"),
+			"unicode":      String("é😀"),
+		})
+	})
+
+	t.Run("keep the object if a key is set both to a value and to an object", func(t *testing.T) {
+		got, err := ParseProperties(strings.NewReader("a=1\na.b=2\nc.d=3\nc=4"))
+		assertNoError(t, err)
+		assertDeepEqual(t, got.root, Object{"a": Object{"b": String("2")}, "c": Object{"d": String("3")}})
+	})
+
+	t.Run("convert the indexed keys to arrays if requested", func(t *testing.T) {
+		input := "list.1=b\nlist.0=a\nsparse.0=a\nsparse.2=c\nnested.0.x=1\nleading.01=a"
+
+		got, err := ParseProperties(strings.NewReader(input), true)
+		assertNoError(t, err)
+		assertDeepEqual(t, got.root, Object{
+			"list":    Array{String("a"), String("b")},
+			"sparse":  Object{"0": String("a"), "2": String("c")},
+			"nested":  Array{Object{"x": String("1")}},
+			"leading": Object{"01": String("a")},
+		})
+
+		got, err = ParseProperties(strings.NewReader("list.0=a"))
+		assertNoError(t, err)
+		assertDeepEqual(t, got.root, Object{"list": Object{"0": String("a")}})
+	})
+
+	t.Run("read back the exported properties", func(t *testing.T) {
+		config := &Config{root: Object{"a b": Object{"c": String("  x = y # z\né")}, "list": Array{String("1")}}}
+		got, err := ParseProperties(strings.NewReader(string(config.ToProperties())), true)
+		assertNoError(t, err)
+		assertDeepEqual(t, got.root, config.root)
+	})
+
+	t.Run("return an error for a malformed unicode escape", func(t *testing.T) {
+		got, err := ParseProperties(strings.NewReader("a=1\nb=\\u00G1"))
+		assertError(t, err, errors.New(`could not parse the properties at line: 2, malformed \uxxxx escape: "\\u00G1"`))
+		assertNil(t, got)
+
+		_, err = ParseProperties(strings.NewReader("b=\\u00"))
+		assertError(t, err, errors.New(`could not parse the properties at line: 1, malformed \uxxxx escape: "\\u00"`))
+	})
+}
@@ -0,0 +1,346 @@
+package hocon
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// renderer writes the string representations of the values incrementally to the underlying writer,
+// it keeps the number of the written bytes and stops writing after the first error.
+// In the reparseable mode the keys and the strings are quoted where needed, so the output can be parsed back
+type renderer struct {
+	w           io.Writer
+	n           int64
+	err         error
+	reparseable bool
+}
+
+func (r *renderer) write(s string) {
+	if r.err != nil {
+		return
+	}
+
+	n, err := io.WriteString(r.w, s)
+	r.n += int64(n)
+	r.err = err
+}
+
+func (r *renderer) render(value Value) {
+	switch v := value.(type) {
+	case Object:
+		r.write(objectStartToken)
+
+		for i, key := range v.sortedKeys() {
+			if i > 0 {
+				r.write(", ")
+			}
+
+			r.writeKey(key)
+			r.write(colonToken)
+			r.render(v[key])
+		}
+
+		r.write(objectEndToken)
+	case Array:
+		r.write(arrayStartToken)
+
+		for i, element := range v {
+			if i > 0 {
+				r.write(commaToken)
+			}
+
+			r.render(element)
+		}
+
+		r.write(arrayEndToken)
+	case concatenation:
+		if r.reparseable && v.containsSubstitution() { // the adjacent strings are joined and quoted between the substitutions
+			var builder strings.Builder
+
+			for _, element := range v {
+				if s, ok := element.(String); ok {
+					builder.WriteString(string(s))
+					continue
+				}
+
+				if builder.Len() > 0 {
+					r.writeQuoted(builder.String())
+					builder.Reset()
+				}
+
+				r.render(element)
+			}
+
+			if builder.Len() > 0 {
+				r.writeQuoted(builder.String())
+			}
+
+			return
+		}
+
+		if r.reparseable && !v.containsObject() && !v.containsArray() {
+			var builder strings.Builder
+
+			for _, element := range v {
+				if s, ok := element.(String); ok {
+					builder.WriteString(string(s))
+				} else {
+					builder.WriteString(renderToString(element))
+				}
+			}
+
+			r.writeQuoted(builder.String())
+
+			return
+		}
+
+		for _, element := range v {
+			r.render(element)
+		}
+	case String:
+		if r.reparseable {
+			r.writeQuoted(string(v))
+			return
+		}
+
+		r.write(v.String())
+	case Duration:
+		if r.reparseable {
+			r.write(formatDuration(time.Duration(v)))
+			return
+		}
+
+		r.write(v.String())
+	default:
+		r.write(v.String())
+	}
+}
+
+func (r *renderer) writeHeader(header []string) {
+	for _, line := range header {
+		r.write(line)
+		r.write("\n")
+	}
+}
+
+func (r *renderer) writeKey(key string) {
+	if !r.reparseable {
+		r.write(key)
+		return
+	}
+
+	if key == includeToken {
+		r.writeQuoted(key)
+		return
+	}
+
+	r.write(quoteKeyIfNeeded(key))
+}
+
+func (r *renderer) writeQuoted(s string) {
+	quoted, _ := json.Marshal(s)
+	r.write(string(quoted))
+}
+
+// formatDuration formats the duration with the largest of the milliseconds and nanoseconds units that keeps it exact,
+// unlike time.Duration.String the result (e.g. 1500ms) is a valid HOCON duration
+func formatDuration(d time.Duration) string {
+	if d%time.Millisecond == 0 {
+		return strconv.FormatInt(int64(d/time.Millisecond), 10) + "ms"
+	}
+
+	return strconv.FormatInt(int64(d), 10) + "ns"
+}
+
+// RenderOptions controls the style of the multi-line rendering of the RenderWithOptions method,
+// the zero value renders with two spaces indentation and ':' separators
+type RenderOptions struct {
+	IndentWidth int  // number of spaces (or tabs with UseTabs) per nesting level, 2 if not positive
+	UseTabs     bool // indent with tabs instead of spaces
+	UseEquals   bool // separate the keys from the values with '=' instead of ':'
+	PathKeys    bool // render the chains of single-key objects as path expressions, e.g. a.b.c: 1 instead of a { b { c: 1 } }
+}
+
+func (o RenderOptions) indent(depth int) string {
+	width := o.IndentWidth
+	if width <= 0 {
+		width = 2
+	}
+
+	if o.UseTabs {
+		return strings.Repeat("\t", width*depth)
+	}
+
+	return strings.Repeat(" ", width*depth)
+}
+
+func (o RenderOptions) separator() string {
+	if o.UseEquals {
+		return " = "
+	}
+
+	return colonToken + " "
+}
+
+// renderStyled renders the value on multiple lines in the given style, the keys and the strings are quoted where needed
+// like in the reparseable mode. The arrays of scalars are rendered on a single line
+func (r *renderer) renderStyled(value Value, style RenderOptions, depth int) {
+	switch v := value.(type) {
+	case Object:
+		if len(v) == 0 {
+			r.write(objectStartToken + objectEndToken)
+			return
+		}
+
+		r.write(objectStartToken + "\n")
+
+		for _, key := range v.sortedKeys() {
+			r.write(style.indent(depth + 1))
+			r.renderStyledField(key, v[key], style, depth+1)
+			r.write("\n")
+		}
+
+		r.write(style.indent(depth) + objectEndToken)
+	case Array:
+		if !v.containsContainer() {
+			r.write(arrayStartToken)
+
+			for i, element := range v {
+				if i > 0 {
+					r.write(commaToken + " ")
+				}
+
+				r.render(element)
+			}
+
+			r.write(arrayEndToken)
+
+			return
+		}
+
+		r.write(arrayStartToken + "\n")
+
+		for i, element := range v {
+			r.write(style.indent(depth + 1))
+			r.renderStyled(element, style, depth+1)
+
+			if i < len(v)-1 {
+				r.write(commaToken)
+			}
+
+			r.write("\n")
+		}
+
+		r.write(style.indent(depth) + arrayEndToken)
+	default:
+		r.render(v)
+	}
+}
+
+func (r *renderer) renderStyledField(key string, value Value, style RenderOptions, depth int) {
+	r.writeKey(key)
+
+	if style.PathKeys {
+		for object, ok := value.(Object); ok && len(object) == 1; object, ok = value.(Object) {
+			for childKey, child := range object {
+				r.write(dotToken)
+				r.writeKey(childKey)
+				value = child
+			}
+		}
+	}
+
+	if _, ok := value.(Object); ok {
+		r.write(" ")
+	} else {
+		r.write(style.separator())
+	}
+
+	r.renderStyled(value, style, depth)
+}
+
+func (a Array) containsContainer() bool {
+	for _, element := range a {
+		switch element.(type) {
+		case Object, Array:
+			return true
+		}
+	}
+
+	return false
+}
+
+// RenderWithOptions method renders the Config as multi-line HOCON in the style given by the options, e.g. to format
+// the configuration files in a house style. Like the AsReader method, the keys and the strings are quoted where needed,
+// so the rendered text can be parsed back into an equivalent Config. The braces of the root object are omitted
+func (c *Config) RenderWithOptions(opts RenderOptions) string {
+	var builder strings.Builder
+
+	r := &renderer{w: &builder, reparseable: true}
+	r.writeHeader(c.header)
+
+	root, ok := c.GetRoot().(Object)
+	if !ok {
+		r.renderStyled(c.GetRoot(), opts, 0)
+		return builder.String()
+	}
+
+	for _, key := range root.sortedKeys() { // the braces of the root object are omitted
+		r.renderStyledField(key, root[key], opts, 0)
+		r.write("\n")
+	}
+
+	return builder.String()
+}
+
+func renderToString(value Value) string {
+	var builder strings.Builder
+
+	r := &renderer{w: &builder}
+	r.render(value)
+
+	return builder.String()
+}
+
+func (o Object) sortedKeys() []string {
+	keys := make([]string, 0, len(o))
+	for key := range o {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// WriteTo method writes the string representation of the Config (same as the String method) to the given writer
+// without building the whole string in memory, returns the number of the written bytes and the first write error if any
+func (c *Config) WriteTo(w io.Writer) (int64, error) {
+	r := &renderer{w: w}
+	r.writeHeader(c.header)
+	r.render(c.GetRoot())
+
+	return r.n, r.err
+}
+
+// AsReader method returns a reader that renders the Config as HOCON lazily while it is read. Unlike the String method,
+// the keys and the strings are quoted where needed, so the rendered text can be parsed back into an equivalent Config.
+// The rendering runs in a goroutine until the reader is read to the end or closed, close the reader if it is not read
+// to the end to stop the rendering
+func (c *Config) AsReader() io.ReadCloser {
+	reader, writer := io.Pipe()
+	root := c.GetRoot()
+
+	go func() {
+		r := &renderer{w: writer, reparseable: true}
+		r.writeHeader(c.header)
+		r.render(root)
+		_ = writer.CloseWithError(r.err)
+	}()
+
+	return reader
+}
@@ -0,0 +1,143 @@
+package hocon
+
+import (
+	"errors"
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+type failingWriter struct {
+	limit int
+}
+
+func (f *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > f.limit {
+		n := f.limit
+		f.limit = 0
+
+		return n, errors.New("write limit exceeded")
+	}
+
+	f.limit -= len(p)
+
+	return len(p), nil
+}
+
+func TestWriteTo(t *testing.T) {
+	config := &Config{root: Object{"b": Array{Int(1), String("x:y")}, "a": Object{"c": concatenation{String("d"), String(" "), String("e")}}}}
+
+	t.Run("write the same representation as the String method", func(t *testing.T) {
+		var builder strings.Builder
+		n, err := config.WriteTo(&builder)
+		assertNoError(t, err)
+		assertEquals(t, builder.String(), config.String())
+		assertEquals(t, builder.String(), `{a:{c:d e}, b:[1,"x:y"]}`)
+		assertEquals(t, n, int64(builder.Len()))
+	})
+
+	t.Run("return the written byte count and the first write error", func(t *testing.T) {
+		n, err := config.WriteTo(&failingWriter{limit: 5})
+		assertError(t, err, errors.New("write limit exceeded"))
+		assertEquals(t, n, int64(5))
+	})
+}
+
+func TestAsReader(t *testing.T) {
+	t.Run("render the config that can be parsed back into an equivalent config", func(t *testing.T) {
+		root := Object{
+			"a.b":     String("c"),
+			"include": Boolean(true),
+			"":        null,
+			"key":     Object{"s": String("x:y, # z"), "empty": String(""), "number": String("1"), "multi": String("a\nb")},
+			"list":    Array{Int(-1), Float64(1.5), Array{}, Object{"d": Duration(1500 * time.Millisecond)}},
+			"concat":  concatenation{String("d"), String(" "), Int(1)},
+			"nanos":   Duration(time.Nanosecond),
+		}
+
+		content, err := io.ReadAll((&Config{root: root}).AsReader())
+		assertNoError(t, err)
+
+		got, err := ParseString(string(content))
+		assertNoError(t, err)
+
+		root["concat"] = String("d 1")
+		root["list"].(Array)[2] = Array(nil)
+		assertDeepEqual(t, got.root, root)
+	})
+
+	t.Run("render the root array", func(t *testing.T) {
+		content, err := io.ReadAll((&Config{root: Array{String("a"), Int(1)}}).AsReader())
+		assertNoError(t, err)
+		assertEquals(t, string(content), `["a",1]`)
+	})
+
+	t.Run("stop rendering if the reader is closed", func(t *testing.T) {
+		reader := (&Config{root: Object{"a": Int(1)}}).AsReader()
+		assertNoError(t, reader.Close())
+
+		_, err := reader.Read(make([]byte, 1))
+		assertError(t, err, io.ErrClosedPipe)
+	})
+
+	t.Run("stop the rendering goroutine if the reader is closed before it is read to the end", func(t *testing.T) {
+		array := make(Array, 10000)
+		for i := range array {
+			array[i] = Int(i)
+		}
+
+		reader := (&Config{root: Object{"a": array}}).AsReader()
+		_, err := reader.Read(make([]byte, 8))
+		assertNoError(t, err)
+
+		if !isRendering() {
+			t.Fatalf("expected the rendering goroutine to wait for the reader")
+		}
+
+		assertNoError(t, reader.Close())
+
+		for deadline := time.Now().Add(5 * time.Second); isRendering(); time.Sleep(time.Millisecond) {
+			if time.Now().After(deadline) {
+				t.Fatalf("the rendering goroutine did not exit after the reader is closed")
+			}
+		}
+	})
+}
+
+// isRendering reports whether a goroutine started by the AsReader method is running
+func isRendering() bool {
+	stacks := make([]byte, 1<<20)
+	return strings.Contains(string(stacks[:runtime.Stack(stacks, true)]), "(*Config).AsReader.func")
+}
+
+func TestRenderWithOptions(t *testing.T) {
+	config, err := ParseString(`a.b.c = 1, x { y = "s:t", z = [1, {q: 2}] }, e {}, l = [1, 2]`)
+	assertNoError(t, err)
+
+	t.Run("render with the default style", func(t *testing.T) {
+		got := config.RenderWithOptions(RenderOptions{})
+		expected := "a {\n  b {\n    c: 1\n  }\n}\ne {}\nl: [1, 2]\nx {\n  y: \"s:t\"\n  z: [\n    1,\n    {\n      q: 2\n    }\n  ]\n}\n"
+		assertEquals(t, got, expected)
+	})
+
+	t.Run("render with tabs, equals separators and path keys", func(t *testing.T) {
+		got := config.RenderWithOptions(RenderOptions{IndentWidth: 1, UseTabs: true, UseEquals: true, PathKeys: true})
+		expected := "a.b.c = 1\ne {}\nl = [1, 2]\nx {\n\ty = \"s:t\"\n\tz = [\n\t\t1,\n\t\t{\n\t\t\tq = 2\n\t\t}\n\t]\n}\n"
+		assertEquals(t, got, expected)
+	})
+
+	t.Run("render the text that can be parsed back into an equivalent config", func(t *testing.T) {
+		for _, opts := range []RenderOptions{{}, {IndentWidth: 4, UseEquals: true, PathKeys: true}} {
+			got, err := ParseString(config.RenderWithOptions(opts))
+			assertNoError(t, err)
+			assertDeepEqual(t, got.root, config.root)
+		}
+	})
+
+	t.Run("render the root array", func(t *testing.T) {
+		got := (&Config{root: Array{Object{"a": Int(1)}}}).RenderWithOptions(RenderOptions{IndentWidth: 3})
+		assertEquals(t, got, "[\n   {\n      a: 1\n   }\n]")
+	})
+}
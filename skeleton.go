@@ -0,0 +1,92 @@
+package hocon
+
+import "strings"
+
+// Skeleton method renders the structure of the Config as HOCON with a type placeholder in place of each value,
+// e.g. host = "<string>" and port = <int>, to share the shape of a configuration without leaking its values.
+// The values at the given paths (and in their subtrees) are rendered as they are, e.g. to show the defaults.
+// The arrays are rendered with the placeholder of their first element
+func (c *Config) Skeleton(shownPaths ...string) string {
+	s := &skeleton{shown: map[string]bool{}}
+	for _, path := range shownPaths {
+		s.shown[normalizePath(path)] = true
+	}
+
+	if object, ok := c.GetRoot().(Object); ok {
+		s.writeFields("", object, 0)
+	} else {
+		s.writeValue("", c.GetRoot(), 0)
+		s.builder.WriteString("\n")
+	}
+
+	return s.builder.String()
+}
+
+type skeleton struct {
+	builder strings.Builder
+	shown   map[string]bool
+}
+
+func (s *skeleton) writeFields(path string, object Object, depth int) {
+	for _, key := range object.sortedKeys() {
+		keyPath := quoteKeyIfNeeded(key)
+		if path != "" {
+			keyPath = path + dotToken + keyPath
+		}
+
+		s.builder.WriteString(strings.Repeat("  ", depth))
+		s.builder.WriteString(quoteKeyIfNeeded(key))
+
+		if _, ok := object[key].(Object); ok && !s.shown[keyPath] {
+			s.builder.WriteString(" ")
+		} else {
+			s.builder.WriteString(" = ")
+		}
+
+		s.writeValue(keyPath, object[key], depth)
+		s.builder.WriteString("\n")
+	}
+}
+
+func (s *skeleton) writeValue(path string, value Value, depth int) {
+	if s.shown[path] {
+		r := &renderer{w: &s.builder, reparseable: true}
+		r.render(value)
+
+		return
+	}
+
+	switch v := value.(type) {
+	case Object:
+		s.builder.WriteString("{\n")
+		s.writeFields(path, v, depth+1)
+		s.builder.WriteString(strings.Repeat("  ", depth) + "}")
+	case Array:
+		s.builder.WriteString("[")
+
+		if len(v) > 0 {
+			s.writeValue(path+dotToken+"0", v[0], depth)
+		}
+
+		s.builder.WriteString("]")
+	default:
+		s.builder.WriteString(placeholder(value))
+	}
+}
+
+func placeholder(value Value) string {
+	switch value.(type) {
+	case Int:
+		return "<int>"
+	case Float32, Float64:
+		return "<float>"
+	case Boolean:
+		return "<boolean>"
+	case Duration:
+		return "<duration>"
+	case Null:
+		return string(null)
+	default:
+		return `"<string>"`
+	}
+}
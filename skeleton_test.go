@@ -0,0 +1,59 @@
+package hocon
+
+import "testing"
+
+func TestSkeleton(t *testing.T) {
+	config, err := ParseString(`
+		db { host = "localhost", port = 5432, password = secret }
+		timeout = 5s
+		ratio = 0.5
+		debug = true
+		optional = null
+		tags = [a, b]
+		"a.b" = [{ c = 1 }]
+		empty = []`)
+	assertNoError(t, err)
+
+	t.Run("render the type placeholders in place of the values", func(t *testing.T) {
+		expected := `"a.b" = [{
+  c = <int>
+}]
+db {
+  host = "<string>"
+  password = "<string>"
+  port = <int>
+}
+debug = <boolean>
+empty = []
+optional = null
+ratio = <float>
+tags = ["<string>"]
+timeout = <duration>
+`
+		assertEquals(t, config.Skeleton(), expected)
+	})
+
+	t.Run("render the values at the shown paths as they are", func(t *testing.T) {
+		got := config.Skeleton("db.port", "tags", "debug")
+		expected := `"a.b" = [{
+  c = <int>
+}]
+db {
+  host = "<string>"
+  password = "<string>"
+  port = 5432
+}
+debug = true
+empty = []
+optional = null
+ratio = <float>
+tags = ["a","b"]
+timeout = <duration>
+`
+		assertEquals(t, got, expected)
+	})
+
+	t.Run("render the skeleton of an array root", func(t *testing.T) {
+		assertEquals(t, (&Config{root: Array{Int(1), Int(2)}}).Skeleton(), "[<int>]\n")
+	})
+}
@@ -0,0 +1,57 @@
+package hocon
+
+import "time"
+
+// ParseStats describes the parsing of a configuration, e.g. to track the growth of the configurations in the dashboards
+type ParseStats struct {
+	Duration      time.Duration // time spent parsing and resolving the configuration
+	Objects       int           // number of the objects in the configuration tree, including the root
+	Arrays        int           // number of the arrays in the configuration tree
+	Scalars       int           // number of the other values in the configuration tree, including the nulls
+	Substitutions int           // number of the substitution occurrences resolved to a value
+	Includes      int           // number of the included files that are parsed
+}
+
+// ParseStringWithStats function parses the given hocon string like ParseString and reports the statistics of the parsing.
+// The values are counted in the resolved tree, the substitutions of a configuration parsed with the WithLazyResolution
+// option are not resolved yet so they are counted as scalars instead
+func ParseStringWithStats(input string, opts ...Option) (*Config, ParseStats, error) {
+	var stats ParseStats
+
+	config, err := parseString(input, &stats, opts)
+	if err != nil {
+		return nil, ParseStats{}, err
+	}
+
+	return config, stats, nil
+}
+
+// collect records the statistics of the parsed configuration with the number of the included files
+// and the time spent parsing it
+func (s *ParseStats) collect(config *Config, includes int, duration time.Duration) {
+	s.Duration, s.Includes = duration, includes
+	s.count(config.root)
+
+	if config.report != nil {
+		s.Substitutions = len(config.report.Resolved) + len(config.report.FromEnv) + len(config.report.FromResolver)
+	}
+}
+
+func (s *ParseStats) count(value Value) {
+	switch v := value.(type) {
+	case Object:
+		s.Objects++
+
+		for _, element := range v {
+			s.count(element)
+		}
+	case Array:
+		s.Arrays++
+
+		for _, element := range v {
+			s.count(element)
+		}
+	default:
+		s.Scalars++
+	}
+}
@@ -0,0 +1,28 @@
+package hocon
+
+import "testing"
+
+func TestParseStringWithStats(t *testing.T) {
+	t.Run("count the values, the resolved substitutions and the included files", func(t *testing.T) {
+		config, stats, err := ParseStringWithStats(`include "testdata/x.conf"
+			b: [1, {c: ${x}}, null]
+			d: ${?missing}
+			e: ${b}`)
+		assertNoError(t, err)
+		assertEquals(t, config.GetInt("b.1.c"), 7)
+
+		if stats.Duration <= 0 {
+			t.Fatalf("expected a positive duration, got: %v", stats.Duration)
+		}
+
+		stats.Duration = 0
+		assertEquals(t, stats, ParseStats{Objects: 3, Arrays: 2, Scalars: 9, Substitutions: 2, Includes: 3})
+	})
+
+	t.Run("return the parse error", func(t *testing.T) {
+		config, stats, err := ParseStringWithStats("a: [1")
+		assertError(t, err, invalidArrayError("parenthesis do not match", 1, 5))
+		assertNil(t, config)
+		assertEquals(t, stats, ParseStats{})
+	})
+}
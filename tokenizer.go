@@ -0,0 +1,216 @@
+package hocon
+
+import (
+	"io"
+	"text/scanner"
+	"unicode"
+)
+
+// TokenKind is the classification of a Token
+type TokenKind int
+
+// TokenKind constants
+const (
+	KeyKind               TokenKind = iota // object keys and the path segments of the substitutions
+	StringKind                             // quoted, multi-line and unquoted strings
+	NumberKind                             // numbers, including the ones with a unit like 10s
+	BooleanKind                            // true, false and the other boolean spellings
+	NullKind                               // null
+	KeywordKind                            // include
+	PunctuationKind                        // separators, braces, brackets and periods
+	CommentKind                            // # and // comments up to the end of the line
+	SubstitutionStartKind                  // ${ or ${?
+)
+
+func (k TokenKind) String() string {
+	switch k {
+	case KeyKind:
+		return "key"
+	case StringKind:
+		return "string"
+	case NumberKind:
+		return "number"
+	case BooleanKind:
+		return "boolean"
+	case NullKind:
+		return "null"
+	case KeywordKind:
+		return "keyword"
+	case PunctuationKind:
+		return "punctuation"
+	case CommentKind:
+		return "comment"
+	case SubstitutionStartKind:
+		return "substitution-start"
+	default:
+		return "unknown"
+	}
+}
+
+// Token is a lexical element of a HOCON source, Line and Column are the 1-based start position
+// and Length is the length of the Text in bytes
+type Token struct {
+	Kind   TokenKind
+	Text   string
+	Line   int
+	Column int
+	Length int
+}
+
+// Tokenize function splits the given HOCON source into the classified tokens in the order they appear,
+// whitespaces are skipped. Returns a ParseError if the source contains an invalid token, e.g. an unclosed string.
+// The unquoted strings are classified as booleans with the spellings of the WithBooleanSpellings option if given,
+// the other options are ignored
+func Tokenize(r io.Reader, opts ...Option) ([]Token, error) {
+	options := newOptions(opts)
+	if options.err != nil {
+		return nil, options.err
+	}
+
+	t := &tokenizer{booleans: options.booleans}
+	t.scanner = newLexer(r, func(message string, position scanner.Position) {
+		if t.err == nil {
+			t.err = parseError("invalid token!", message, position.Line, position.Column)
+		}
+	})
+	t.scanner.Mode &^= scanner.SkipComments
+
+	return t.tokenize()
+}
+
+type tokenizer struct {
+	scanner        *lexer
+	tokens         []Token
+	inSubstitution bool
+	booleans       booleanSpellings
+	err            error
+}
+
+func (t *tokenizer) tokenize() ([]Token, error) {
+	tok, _ := t.scanner.scanSkippingSpaces()
+
+	for tok != scanner.EOF {
+		position := t.scanner.Position
+		text := t.scanner.TokenText()
+
+		switch {
+		case tok == scanner.Comment || text == commentToken:
+			if text == commentToken {
+				text += t.scanner.restOfLine()
+			}
+
+			t.add(CommentKind, text, position)
+		case isMultiLineString(text, t.scanner.Peek()):
+			content, closed := t.scanner.restOfMultiLineString()
+			if !closed && t.err == nil {
+				t.err = unclosedMultiLineStringError()
+			}
+
+			text = `"""` + content
+			if closed {
+				text += `"""`
+			}
+
+			t.add(StringKind, text, position)
+		case isSubstitution(text, t.scanner.Peek()):
+			t.scanner.Next()
+			text += objectStartToken
+
+			if t.scanner.Peek() == '?' {
+				t.scanner.Next()
+				text += "?"
+			}
+
+			t.inSubstitution = true
+			t.add(SubstitutionStartKind, text, position)
+		case t.inSubstitution && text == objectEndToken:
+			t.inSubstitution = false
+			t.add(PunctuationKind, text, position)
+		case t.inSubstitution && text != dotToken:
+			t.add(KeyKind, text, position)
+		case tok == scanner.Int || tok == scanner.Float || text == "-" && unicode.IsDigit(t.scanner.Peek()):
+			tok = t.number(text, position)
+			continue
+		case tok == scanner.Ident:
+			t.add(t.identKind(text), text, position)
+		case tok == scanner.String || tok == scanner.RawString || tok == scanner.Char:
+			t.add(StringKind, text, position)
+		case isSeparator(text, t.scanner.Peek()) || text == objectStartToken:
+			if text == "+" {
+				t.scanner.Next()
+				text = "+="
+			}
+
+			t.markKeys()
+			t.add(PunctuationKind, text, position)
+		default:
+			t.add(PunctuationKind, text, position)
+		}
+
+		tok, _ = t.scanner.scanSkippingSpaces()
+	}
+
+	if t.err != nil {
+		return nil, t.err
+	}
+
+	return t.tokens, nil
+}
+
+func (t *tokenizer) add(kind TokenKind, text string, position scanner.Position) {
+	t.tokens = append(t.tokens, Token{Kind: kind, Text: text, Line: position.Line, Column: position.Column, Length: len(text)})
+}
+
+// markKeys marks the path expression (e.g. a.b."c") right before a key-value separator as keys
+func (t *tokenizer) markKeys() {
+	for i := len(t.tokens) - 1; i >= 0; i -= 2 {
+		token := &t.tokens[i]
+		if token.Kind == PunctuationKind || token.Kind == CommentKind || token.Kind == SubstitutionStartKind {
+			return
+		}
+
+		token.Kind = KeyKind
+
+		if i == 0 || t.tokens[i-1].Kind != PunctuationKind || t.tokens[i-1].Text != dotToken {
+			return
+		}
+	}
+}
+
+// number adds the number with the unit that the parser joins with it (e.g. 10s, 10 seconds, 512MB) or with the rest of
+// the key that starts with digits (e.g. 3x), the digits of a negative number are scanned after its sign.
+// Returns the token after the number that is scanned to find the unit
+func (t *tokenizer) number(text string, position scanner.Position) rune {
+	if text == "-" {
+		t.scanner.Scan()
+		text += t.scanner.TokenText()
+	}
+
+	digitLeadingKey := unicode.IsLetter(t.scanner.Peek())
+
+	tok, whitespaces := t.scanner.scanSkippingSpaces()
+	if digitLeadingKey || t.scanner.numberUnit(tok, position.Line, whitespaces) != noUnit {
+		text += whitespaces + t.scanner.TokenText()
+		tok, _ = t.scanner.scanSkippingSpaces()
+	}
+
+	t.add(NumberKind, text, position)
+
+	return tok
+}
+
+func (t *tokenizer) identKind(text string) TokenKind {
+	if text == string(null) {
+		return NullKind
+	}
+
+	if text == includeToken {
+		return KeywordKind
+	}
+
+	if _, ok := t.booleans.lookup(text); ok {
+		return BooleanKind
+	}
+
+	return StringKind
+}
@@ -0,0 +1,101 @@
+package hocon
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	t.Run("classify the tokens with their positions", func(t *testing.T) {
+		got, err := Tokenize(strings.NewReader("# top\na.b = \"x\" // c\nd: [-1.5, 10s, true, null]\nf { g += ${?h} }\ninclude \"a.conf\""))
+		assertNoError(t, err)
+
+		expected := []Token{
+			{Kind: CommentKind, Text: "# top", Line: 1, Column: 1, Length: 5},
+			{Kind: KeyKind, Text: "a", Line: 2, Column: 1, Length: 1},
+			{Kind: PunctuationKind, Text: ".", Line: 2, Column: 2, Length: 1},
+			{Kind: KeyKind, Text: "b", Line: 2, Column: 3, Length: 1},
+			{Kind: PunctuationKind, Text: "=", Line: 2, Column: 5, Length: 1},
+			{Kind: StringKind, Text: `"x"`, Line: 2, Column: 7, Length: 3},
+			{Kind: CommentKind, Text: "// c", Line: 2, Column: 11, Length: 4},
+			{Kind: KeyKind, Text: "d", Line: 3, Column: 1, Length: 1},
+			{Kind: PunctuationKind, Text: ":", Line: 3, Column: 2, Length: 1},
+			{Kind: PunctuationKind, Text: "[", Line: 3, Column: 4, Length: 1},
+			{Kind: NumberKind, Text: "-1.5", Line: 3, Column: 5, Length: 4},
+			{Kind: PunctuationKind, Text: ",", Line: 3, Column: 9, Length: 1},
+			{Kind: NumberKind, Text: "10s", Line: 3, Column: 11, Length: 3},
+			{Kind: PunctuationKind, Text: ",", Line: 3, Column: 14, Length: 1},
+			{Kind: BooleanKind, Text: "true", Line: 3, Column: 16, Length: 4},
+			{Kind: PunctuationKind, Text: ",", Line: 3, Column: 20, Length: 1},
+			{Kind: NullKind, Text: "null", Line: 3, Column: 22, Length: 4},
+			{Kind: PunctuationKind, Text: "]", Line: 3, Column: 26, Length: 1},
+			{Kind: KeyKind, Text: "f", Line: 4, Column: 1, Length: 1},
+			{Kind: PunctuationKind, Text: "{", Line: 4, Column: 3, Length: 1},
+			{Kind: KeyKind, Text: "g", Line: 4, Column: 5, Length: 1},
+			{Kind: PunctuationKind, Text: "+=", Line: 4, Column: 7, Length: 2},
+			{Kind: SubstitutionStartKind, Text: "${?", Line: 4, Column: 10, Length: 3},
+			{Kind: KeyKind, Text: "h", Line: 4, Column: 13, Length: 1},
+			{Kind: PunctuationKind, Text: "}", Line: 4, Column: 14, Length: 1},
+			{Kind: PunctuationKind, Text: "}", Line: 4, Column: 16, Length: 1},
+			{Kind: KeywordKind, Text: "include", Line: 5, Column: 1, Length: 7},
+			{Kind: StringKind, Text: `"a.conf"`, Line: 5, Column: 9, Length: 8},
+		}
+		assertDeepEqual(t, got, expected)
+	})
+
+	t.Run("return the multi-line string as a single token", func(t *testing.T) {
+		got, err := Tokenize(strings.NewReader("a: \"\"\"x\ny\"\"\""))
+		assertNoError(t, err)
+		assertDeepEqual(t, got[2], Token{Kind: StringKind, Text: "\"\"\"x\ny\"\"\"", Line: 1, Column: 4, Length: 9})
+	})
+
+	t.Run("classify the spellings of the WithBooleanSpellings option as booleans", func(t *testing.T) {
+		got, err := Tokenize(strings.NewReader("a: enabled"), WithBooleanSpellings([]string{"enabled"}, nil))
+		assertNoError(t, err)
+		assertDeepEqual(t, got[2], Token{Kind: BooleanKind, Text: "enabled", Line: 1, Column: 4, Length: 7})
+
+		got, err = Tokenize(strings.NewReader("a: enabled"))
+		assertNoError(t, err)
+		assertEquals(t, got[2].Kind, StringKind)
+	})
+
+	t.Run("return the leading zero numbers with the digits 8 and 9 as numbers", func(t *testing.T) {
+		got, err := Tokenize(strings.NewReader("zip = 08540"))
+		assertNoError(t, err)
+		assertDeepEqual(t, got[2], Token{Kind: NumberKind, Text: "08540", Line: 1, Column: 7, Length: 5})
+	})
+
+	t.Run("join the numbers with their units like the parser", func(t *testing.T) {
+		got, err := Tokenize(strings.NewReader("a: 10 seconds\nb: 512 megabytes\nc: -2 b\n3x: 1"))
+		assertNoError(t, err)
+
+		expected := []Token{
+			{Kind: KeyKind, Text: "a", Line: 1, Column: 1, Length: 1},
+			{Kind: PunctuationKind, Text: ":", Line: 1, Column: 2, Length: 1},
+			{Kind: NumberKind, Text: "10 seconds", Line: 1, Column: 4, Length: 10},
+			{Kind: KeyKind, Text: "b", Line: 2, Column: 1, Length: 1},
+			{Kind: PunctuationKind, Text: ":", Line: 2, Column: 2, Length: 1},
+			{Kind: NumberKind, Text: "512 megabytes", Line: 2, Column: 4, Length: 13},
+			{Kind: KeyKind, Text: "c", Line: 3, Column: 1, Length: 1},
+			{Kind: PunctuationKind, Text: ":", Line: 3, Column: 2, Length: 1},
+			{Kind: NumberKind, Text: "-2", Line: 3, Column: 4, Length: 2},
+			{Kind: StringKind, Text: "b", Line: 3, Column: 7, Length: 1},
+			{Kind: KeyKind, Text: "3x", Line: 4, Column: 1, Length: 2},
+			{Kind: PunctuationKind, Text: ":", Line: 4, Column: 3, Length: 1},
+			{Kind: NumberKind, Text: "1", Line: 4, Column: 5, Length: 1},
+		}
+		assertDeepEqual(t, got, expected)
+	})
+
+	t.Run("return a ParseError for the unclosed multi-line string", func(t *testing.T) {
+		got, err := Tokenize(strings.NewReader(`a: """abc""`))
+		assertError(t, err, unclosedMultiLineStringError())
+		assertNil(t, got)
+	})
+
+	t.Run("return a ParseError for the unclosed string", func(t *testing.T) {
+		got, err := Tokenize(strings.NewReader(`a: "abc`))
+		assertError(t, err, parseError("invalid token!", "literal not terminated", 1, 4))
+		assertNil(t, got)
+	})
+}
@@ -0,0 +1,282 @@
+package hocon
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	valueType    = reflect.TypeOf((*Value)(nil)).Elem()
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// Unmarshal method decodes the configuration into the struct (or map, slice) pointed by the given target.
+// The struct fields are matched with the keys named in their `hocon:"name"` tags, or with their field names
+// case-insensitively, the fields tagged with "-" and the unexported fields are skipped, the embedded structs are
+// decoded from the same object. The fields whose keys are missing or null keep their current values, so the
+// pointer fields (e.g. Timeout *int or Server *ServerConfig) are left nil when their keys are absent and point at
+// the decoded values when present, which separates "not configured" from "configured to the zero value"
+func (c *Config) Unmarshal(target interface{}) error {
+	reflectValue := reflect.ValueOf(target)
+	if reflectValue.Kind() != reflect.Ptr || reflectValue.IsNil() {
+		return fmt.Errorf("could not unmarshal the config, the target must be a non-nil pointer, got: %T", target)
+	}
+
+	return decoder{booleans: c.booleans}.decode("", c.GetRoot(), reflectValue.Elem())
+}
+
+// GetMap function finds the object at the given path in the config and converts its values to T with the conversion
+// rules of the Unmarshal method, e.g. GetMap[time.Duration](config, "timeouts"). The null values are set to the zero
+// value of T, returns an error wrapping ErrValueNotFound if the value is not found and an error naming the path
+// of the first value that cannot be converted
+func GetMap[T any](c *Config, path string) (map[string]T, error) {
+	value := c.Get(path)
+	if value == nil {
+		return nil, valueNotFoundError(path)
+	}
+
+	m := map[string]T{}
+	if err := (decoder{booleans: c.booleans}).decode(path, value, reflect.ValueOf(&m).Elem()); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// decoder decodes the values into the Go values, reading the boolean strings with the spellings of the config
+type decoder struct {
+	booleans booleanSpellings
+}
+
+func (d decoder) decode(path string, value Value, target reflect.Value) error {
+	if value == nil || value.Type() == NullType {
+		return nil
+	}
+
+	if target.Type() == valueType {
+		target.Set(reflect.ValueOf(value))
+		return nil
+	}
+
+	if target.Kind() == reflect.Ptr {
+		element := reflect.New(target.Type().Elem())
+		if err := d.decode(path, value, element.Elem()); err != nil {
+			return err
+		}
+
+		target.Set(element)
+
+		return nil
+	}
+
+	if target.Type() == durationType {
+		duration, ok := AsDuration(value)
+		if !ok {
+			return unmarshalError(path, value, target.Type())
+		}
+
+		target.SetInt(int64(duration))
+
+		return nil
+	}
+
+	switch target.Kind() {
+	case reflect.String:
+		if value.Type() == ObjectType || value.Type() == ArrayType {
+			return unmarshalError(path, value, target.Type())
+		}
+
+		target.SetString(unquotedString(value))
+	case reflect.Bool:
+		boolean, ok := d.booleans.asBoolean(value)
+		if !ok {
+			return unmarshalError(path, value, target.Type())
+		}
+
+		target.SetBool(boolean)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		integer, ok := AsInt(value)
+		if !ok || target.OverflowInt(int64(integer)) {
+			return unmarshalError(path, value, target.Type())
+		}
+
+		target.SetInt(int64(integer))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		integer, ok := AsInt(value)
+		if !ok || integer < 0 || target.OverflowUint(uint64(integer)) {
+			return unmarshalError(path, value, target.Type())
+		}
+
+		target.SetUint(uint64(integer))
+	case reflect.Float32, reflect.Float64:
+		float, ok := AsFloat64(value)
+		if !ok || target.OverflowFloat(float) {
+			return unmarshalError(path, value, target.Type())
+		}
+
+		target.SetFloat(float)
+	case reflect.Slice:
+		array, ok := AsArray(value)
+		if !ok {
+			return unmarshalError(path, value, target.Type())
+		}
+
+		slice := reflect.MakeSlice(target.Type(), len(array), len(array))
+		for i, element := range array {
+			if err := d.decode(childPath(path, fmt.Sprint(i)), element, slice.Index(i)); err != nil {
+				return err
+			}
+		}
+
+		target.Set(slice)
+	case reflect.Map:
+		object, ok := AsObject(value)
+		if !ok || target.Type().Key().Kind() != reflect.String {
+			return unmarshalError(path, value, target.Type())
+		}
+
+		if target.IsNil() {
+			target.Set(reflect.MakeMapWithSize(target.Type(), len(object)))
+		}
+
+		for _, entry := range object.Entries() {
+			element := reflect.New(target.Type().Elem()).Elem()
+			if err := d.decode(childPath(path, entry.Key), entry.Value, element); err != nil {
+				return err
+			}
+
+			target.SetMapIndex(reflect.ValueOf(entry.Key).Convert(target.Type().Key()), element)
+		}
+	case reflect.Struct:
+		object, ok := AsObject(value)
+		if !ok {
+			return unmarshalError(path, value, target.Type())
+		}
+
+		return d.decodeStruct(path, object, target)
+	default:
+		return unmarshalError(path, value, target.Type())
+	}
+
+	return nil
+}
+
+func (d decoder) decodeStruct(path string, object Object, target reflect.Value) error {
+	for i := 0; i < target.NumField(); i++ {
+		field := target.Type().Field(i)
+		tag, hasTag := field.Tag.Lookup("hocon")
+
+		if tag == "-" {
+			continue
+		}
+
+		if field.Anonymous && !hasTag && field.Type.Kind() == reflect.Struct {
+			if err := d.decodeStruct(path, object, target.Field(i)); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		key, value := lookupField(object, field, tag)
+		if err := d.decodeWithModifiers(childPath(path, key), value, target.Field(i), strings.Split(tag, ",")[1:]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// decodeWithModifiers converts the value as selected by the modifiers of the tag before decoding it into the field,
+// the "string" modifier reads a scalar as a string (e.g. port: 8080 into a string field) and the "duration" modifier
+// reads the value as a duration into a time.Duration or an integer field (as nanoseconds)
+func (d decoder) decodeWithModifiers(path string, value Value, target reflect.Value, modifiers []string) error {
+	for _, modifier := range modifiers {
+		if modifier != "string" && modifier != "duration" {
+			return fmt.Errorf("could not unmarshal the value at path: %s, unknown tag modifier: %q", path, modifier)
+		}
+	}
+
+	for _, modifier := range modifiers {
+		if value == nil || value.Type() == NullType {
+			break
+		}
+
+		switch modifier {
+		case "string":
+			if value.Type() == ObjectType || value.Type() == ArrayType {
+				return unmarshalError(path, value, reflect.TypeOf(""))
+			}
+
+			value = String(unquotedString(value))
+		case "duration":
+			duration, ok := AsDuration(value)
+			if !ok {
+				return unmarshalError(path, value, durationType)
+			}
+
+			fieldType := target.Type()
+			for fieldType.Kind() == reflect.Ptr {
+				fieldType = fieldType.Elem()
+			}
+
+			if fieldType == durationType {
+				value = Duration(duration)
+			} else {
+				value = Int(duration)
+			}
+		}
+	}
+
+	return d.decode(path, value, target)
+}
+
+// lookupField returns the key and the value of the given struct field, the key named in the tag must match exactly
+// while the field name is matched case-insensitively (preferring the exact match)
+func lookupField(object Object, field reflect.StructField, tag string) (string, Value) {
+	if name := strings.Split(tag, ",")[0]; name != "" {
+		return name, object[name]
+	}
+
+	if value, ok := object[field.Name]; ok {
+		return field.Name, value
+	}
+
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if strings.EqualFold(key, field.Name) {
+			return key, object[key]
+		}
+	}
+
+	return field.Name, nil
+}
+
+func childPath(path, key string) string {
+	if path == "" {
+		return quoteKeyIfNeeded(key)
+	}
+
+	return path + dotToken + quoteKeyIfNeeded(key)
+}
+
+func unmarshalError(path string, value Value, targetType reflect.Type) error {
+	if path == "" {
+		return fmt.Errorf("could not unmarshal the value: %s into %s", value, targetType)
+	}
+
+	return fmt.Errorf("could not unmarshal the value: %s into %s at path: %s", value, targetType, path)
+}
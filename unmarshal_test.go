@@ -0,0 +1,178 @@
+package hocon
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type unmarshalServer struct {
+	Host string
+	Port int `hocon:"port"`
+}
+
+type unmarshalBase struct {
+	Name string `hocon:"name"`
+}
+
+type unmarshalConfig struct {
+	unmarshalBase
+	Server   unmarshalServer
+	Backup   *unmarshalServer
+	Timeout  *int           `hocon:"timeout"`
+	Retries  *int           `hocon:"retries"`
+	Interval time.Duration  `hocon:"interval"`
+	Ratio    float32        `hocon:"ratio"`
+	Enabled  bool           `hocon:"enabled"`
+	Tags     []string       `hocon:"tags"`
+	Limits   map[string]int `hocon:"limits"`
+	Raw      Value          `hocon:"raw"`
+	Ignored  string         `hocon:"-"`
+	hidden   string
+}
+
+func TestUnmarshal(t *testing.T) {
+	t.Run("decode the config into the struct fields", func(t *testing.T) {
+		config, err := ParseString(`{
+			name: app
+			server { host: localhost, port: 8080 }
+			timeout: 0
+			interval: 5 seconds
+			ratio: 0.5
+			enabled: yes
+			tags: [a, b]
+			limits { x: 1, y: 2 }
+			raw: [1, 2]
+			ignored: value
+			hidden: value
+		}`)
+		assertNoError(t, err)
+
+		var got unmarshalConfig
+		assertNoError(t, config.Unmarshal(&got))
+
+		assertEquals(t, got.Name, "app")
+		assertDeepEqual(t, got.Server, unmarshalServer{Host: "localhost", Port: 8080})
+		assertEquals(t, got.Interval, 5*time.Second)
+		assertEquals(t, got.Ratio, float32(0.5))
+		assertEquals(t, got.Enabled, true)
+		assertDeepEqual(t, got.Tags, []string{"a", "b"})
+		assertDeepEqual(t, got.Limits, map[string]int{"x": 1, "y": 2})
+		assertDeepEqual(t, got.Raw, Array{Int(1), Int(2)})
+		assertEquals(t, got.Ignored, "")
+		assertEquals(t, got.hidden, "")
+	})
+
+	t.Run("leave the pointer fields nil if their keys are absent", func(t *testing.T) {
+		config, err := ParseString(`timeout: 0, retries: null`)
+		assertNoError(t, err)
+
+		var got unmarshalConfig
+		assertNoError(t, config.Unmarshal(&got))
+
+		if got.Timeout == nil || *got.Timeout != 0 {
+			t.Fatalf("expected the timeout to point at 0, got: %v", got.Timeout)
+		}
+
+		if got.Retries != nil || got.Backup != nil {
+			t.Fatalf("expected the absent pointer fields to be nil, got: %v, %v", got.Retries, got.Backup)
+		}
+	})
+
+	t.Run("allocate the nested struct pointers if their objects are present", func(t *testing.T) {
+		config, err := ParseString(`backup { host: "10.0.0.1" }`)
+		assertNoError(t, err)
+
+		var got unmarshalConfig
+		assertNoError(t, config.Unmarshal(&got))
+		assertDeepEqual(t, got.Backup, &unmarshalServer{Host: "10.0.0.1"})
+	})
+
+	t.Run("return an error if a value cannot be decoded into the field", func(t *testing.T) {
+		config, err := ParseString(`server { port: [1] }`)
+		assertNoError(t, err)
+
+		var got unmarshalConfig
+		assertError(t, config.Unmarshal(&got), errors.New("could not unmarshal the value: [1] into int at path: server.port"))
+
+		config, err = ParseString(`tags: [a, {b: 1}]`)
+		assertNoError(t, err)
+		assertError(t, config.Unmarshal(&got), errors.New("could not unmarshal the value: {b:1} into string at path: tags.1"))
+	})
+
+	t.Run("override the conversion of the values with the tag modifiers", func(t *testing.T) {
+		var got struct {
+			Port     string         `hocon:"port,string"`
+			ID       int            `hocon:"id,string"`
+			Timeout  int64          `hocon:"timeout,duration"`
+			Interval *time.Duration `hocon:"interval,duration"`
+			Missing  *int64         `hocon:"missing,duration"`
+		}
+
+		config, err := ParseString(`port: 8080, id: "42", timeout: 10 seconds, interval: "1m"`)
+		assertNoError(t, err)
+		assertNoError(t, config.Unmarshal(&got))
+
+		assertEquals(t, got.Port, "8080")
+		assertEquals(t, got.ID, 42)
+		assertEquals(t, got.Timeout, int64(10*time.Second))
+		assertDeepEqual(t, got.Interval, func() *time.Duration { d := time.Minute; return &d }())
+		assertNil(t, got.Missing)
+	})
+
+	t.Run("return an error if the value does not match the tag modifier", func(t *testing.T) {
+		var got struct {
+			Timeout int64 `hocon:"timeout,duration"`
+		}
+
+		config, err := ParseString(`timeout: forever`)
+		assertNoError(t, err)
+		assertError(t, config.Unmarshal(&got), errors.New("could not unmarshal the value: forever into time.Duration at path: timeout"))
+	})
+
+	t.Run("return an error for an unknown tag modifier", func(t *testing.T) {
+		var got struct {
+			Port int `hocon:"port,number"`
+		}
+
+		assertError(t, (&Config{root: Object{}}).Unmarshal(&got),
+			errors.New(`could not unmarshal the value at path: port, unknown tag modifier: "number"`))
+	})
+
+	t.Run("return an error if the target is not a pointer", func(t *testing.T) {
+		err := (&Config{root: Object{}}).Unmarshal(unmarshalConfig{})
+		assertError(t, err, errors.New("could not unmarshal the config, the target must be a non-nil pointer, got: hocon.unmarshalConfig"))
+	})
+}
+
+func TestGetMap(t *testing.T) {
+	config, err := ParseString(`weights { a: 1, b: "2", c: null }, timeouts { read: 5 seconds, write: 100ms }, mixed { a: 1, b: [2] }, list: [1]`)
+	assertNoError(t, err)
+
+	t.Run("convert the values of the object to the given type", func(t *testing.T) {
+		weights, err := GetMap[int](config, "weights")
+		assertNoError(t, err)
+		assertDeepEqual(t, weights, map[string]int{"a": 1, "b": 2, "c": 0})
+
+		timeouts, err := GetMap[time.Duration](config, "timeouts")
+		assertNoError(t, err)
+		assertDeepEqual(t, timeouts, map[string]time.Duration{"read": 5 * time.Second, "write": 100 * time.Millisecond})
+	})
+
+	t.Run("return an error if a value cannot be converted", func(t *testing.T) {
+		_, err := GetMap[int](config, "mixed")
+		assertError(t, err, errors.New("could not unmarshal the value: [2] into int at path: mixed.b"))
+	})
+
+	t.Run("return an error if the value is not an object", func(t *testing.T) {
+		_, err := GetMap[int](config, "list")
+		assertError(t, err, errors.New("could not unmarshal the value: [1] into map[string]int at path: list"))
+	})
+
+	t.Run("return an error if the value is not found", func(t *testing.T) {
+		_, err := GetMap[string](config, "missing")
+		if !errors.Is(err, ErrValueNotFound) {
+			t.Fatalf("expected a value not found error, got: %v", err)
+		}
+	})
+}